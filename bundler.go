@@ -1,43 +1,83 @@
 package turing
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// minLinger is the smallest linger used by a bundler under load.
+const minLinger = 50 * time.Microsecond
+
+// itemOverhead is the estimated encoding overhead per bundled instruction.
+const itemOverhead = 16
+
 type bundlerOptions struct {
+	name        string
 	queueSize   int
+	failFast    bool
 	batchSize   int
 	concurrency int
-	handler     func([]Instruction) error
+	pipeline    int
+	linger      time.Duration
+	encode      bool
+	maxSize     int
+	handler     func([]Instruction, []error) error
+	async       func(worker int, list []Instruction, codes [][]byte, errs []error, done func(error))
 }
 
 type bundlerItem struct {
-	ins Instruction
-	ch  chan error
-	fn  func(error)
+	ins  Instruction
+	code []byte
+	ref  Ref
+	size int
+	time time.Time
+	ch   chan error
+	fn   func(error)
 }
 
 type bundler struct {
-	opts   bundlerOptions
-	queue  chan bundlerItem
-	mutex  sync.RWMutex
-	group  sync.WaitGroup
-	closed bool
+	opts     bundlerOptions
+	queue    chan bundlerItem
+	depth    prometheus.Gauge
+	wait     prometheus.Observer
+	stopping chan struct{}
+	aborted  chan struct{}
+	stopped  sync.Once
+	abort    sync.Once
+	mutex    sync.RWMutex
+	group    sync.WaitGroup
+	closed   bool
 }
 
 func newBundler(opts bundlerOptions) *bundler {
 	// prepare bundler
 	c := &bundler{
-		opts:  opts,
-		queue: make(chan bundlerItem, opts.queueSize),
+		opts:     opts,
+		queue:    make(chan bundlerItem, opts.queueSize),
+		depth:    queueDepth.WithLabelValues(opts.name),
+		wait:     queueWait.WithLabelValues(opts.name),
+		stopping: make(chan struct{}),
+		aborted:  make(chan struct{}),
+	}
+
+	// ensure pipeline
+	if c.opts.pipeline < 1 {
+		c.opts.pipeline = 1
+	}
+
+	// ensure linger
+	if c.opts.linger == 0 {
+		c.opts.linger = 5 * time.Millisecond
 	}
 
 	// run processors
 	c.group.Add(opts.concurrency)
 	for i := 0; i < opts.concurrency; i++ {
-		go c.processor()
+		go c.processor(i)
 	}
 
 	return c
@@ -50,24 +90,37 @@ var bundlerChanPool = sync.Pool{
 }
 
 func (b *bundler) process(ins Instruction, fn func(error)) error {
-	// acquire mutex
-	b.mutex.RLock()
-	defer b.mutex.RUnlock()
+	// prepare item
+	item := bundlerItem{
+		ins: ins,
+	}
 
-	// check if done
-	if b.closed {
-		return fmt.Errorf("turing: bundler closed")
+	// encode instruction if requested
+	if b.opts.encode {
+		code, ref, err := ins.Encode()
+		if err != nil {
+			return err
+		}
+
+		// set code and size
+		item.code = code
+		item.ref = ref
+		item.size = len(ins.Describe().Name) + len(code) + itemOverhead
+
+		// check size
+		if b.opts.maxSize > 0 && item.size > b.opts.maxSize {
+			if ref != nil {
+				ref.Release()
+			}
+			return fmt.Errorf("turing: bundler: instruction too large: %d bytes", item.size)
+		}
 	}
 
 	// handle async
 	if fn != nil {
 		// queue instruction
-		b.queue <- bundlerItem{
-			ins: ins,
-			fn:  fn,
-		}
-
-		return nil
+		item.fn = fn
+		return b.enqueue(item)
 	}
 
 	// get result channel
@@ -75,89 +128,309 @@ func (b *bundler) process(ins Instruction, fn func(error)) error {
 	defer bundlerChanPool.Put(ch)
 
 	// queue instruction
-	b.queue <- bundlerItem{
-		ins: ins,
-		ch:  ch,
+	item.ch = ch
+	err := b.enqueue(item)
+	if err != nil {
+		return err
 	}
 
 	return <-ch
 }
 
-func (b *bundler) processor() {
+func (b *bundler) enqueue(item bundlerItem) error {
+	// acquire mutex
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	// check if closed
+	if b.closed {
+		if item.ref != nil {
+			item.ref.Release()
+		}
+		return ErrStopped
+	}
+
+	// set time
+	item.time = time.Now()
+
+	// queue instruction or fail fast if full
+	if b.opts.failFast {
+		select {
+		case b.queue <- item:
+		default:
+			if item.ref != nil {
+				item.ref.Release()
+			}
+			overloadMetrics.WithLabelValues(item.ins.Describe().Name).Inc()
+			return ErrOverloaded
+		}
+	} else {
+		select {
+		case b.queue <- item:
+		case <-b.stopping:
+			if item.ref != nil {
+				item.ref.Release()
+			}
+			return ErrStopped
+		}
+	}
+
+	// update depth
+	b.depth.Set(float64(len(b.queue)))
+
+	return nil
+}
+
+func (b *bundler) processor(worker int) {
 	// ensure done
 	defer b.group.Done()
 
-	// prepare list
-	list := make([]Instruction, 0, b.opts.batchSize)
-	chs := make([]chan error, 0, b.opts.batchSize)
-	fns := make([]func(error), 0, b.opts.batchSize)
+	// prepare bundles
+	free := make(chan *bundle, b.opts.pipeline)
+	for i := 0; i < b.opts.pipeline; i++ {
+		free <- newBundle(b.opts.batchSize, b.opts.maxSize)
+	}
 
-	for {
-		// wait up to 5ms until a full batch is available
-		for i := 0; i < 5 && len(b.queue) < b.opts.batchSize; i++ {
-			time.Sleep(time.Millisecond)
+	// await in-flight bundles
+	defer func() {
+		for i := 0; i < b.opts.pipeline; i++ {
+			<-free
 		}
+	}()
 
-		// await next item
-		item, ok := <-b.queue
-		if !ok {
-			return
+	// prepare linger and carried over item
+	var linger time.Duration
+	var pending *bundlerItem
+
+	for {
+		// acquire bundle
+		bnd := <-free
+
+		// add carried over item or await next item
+		if pending != nil {
+			bnd.add(*pending)
+			pending = nil
+		} else {
+			item, ok := <-b.queue
+			if !ok {
+				free <- bnd
+				return
+			}
+			bnd.add(item)
 		}
 
-		// add to list
-		list = append(list, item.ins)
-		if item.ch != nil {
-			chs = append(chs, item.ch)
+		// add buffered items while bundle has room
+		pending = b.fill(bnd, 0)
+
+		// linger for more items while bundle has room
+		if pending == nil && linger > 0 && !bnd.full() {
+			pending = b.fill(bnd, linger)
 		}
-		if item.fn != nil {
-			fns = append(fns, item.fn)
+
+		// observe wait and depth
+		now := time.Now()
+		for _, item := range bnd.items {
+			b.wait.Observe(float64(now.Sub(item.time)/time.Microsecond) / 1000.0)
 		}
+		b.depth.Set(float64(len(b.queue)))
 
-		// add buffered instructions while list has room
-		for len(b.queue) > 0 && len(list) < cap(list) {
-			item, ok := <-b.queue
-			if ok {
-				list = append(list, item.ins)
-				if item.ch != nil {
-					chs = append(chs, item.ch)
-				}
-				if item.fn != nil {
-					fns = append(fns, item.fn)
-				}
+		// adapt linger to load
+		if pending != nil || bnd.full() {
+			linger *= 2
+			if linger < minLinger {
+				linger = minLinger
+			} else if linger > b.opts.linger {
+				linger = b.opts.linger
+			}
+		} else if len(bnd.list) == 1 {
+			linger /= 2
+			if linger < minLinger {
+				linger = 0
 			}
 		}
 
+		// fail bundle if aborted
+		select {
+		case <-b.aborted:
+			bnd.forward(ErrStopped)
+			free <- bnd
+			continue
+		default:
+		}
+
+		// call async handler if available
+		if b.opts.async != nil {
+			b.opts.async(worker, bnd.list, bnd.codes, bnd.errs[:len(bnd.list)], func(err error) {
+				bnd.forward(err)
+				free <- bnd
+			})
+			continue
+		}
+
 		// call handler
-		err := b.opts.handler(list)
+		err := b.opts.handler(bnd.list, bnd.errs[:len(bnd.list)])
 
-		// forward on channels
-		for _, ch := range chs {
-			ch <- err
+		// forward errors
+		bnd.forward(err)
+		free <- bnd
+	}
+}
+
+func (b *bundler) fill(bnd *bundle, linger time.Duration) *bundlerItem {
+	// prepare timer if lingering
+	var deadline <-chan time.Time
+	if linger > 0 {
+		timer := time.NewTimer(linger)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for !bnd.full() {
+		// get next item
+		var item bundlerItem
+		var ok bool
+		if deadline != nil {
+			select {
+			case item, ok = <-b.queue:
+			case <-deadline:
+				return nil
+			}
+		} else {
+			select {
+			case item, ok = <-b.queue:
+			default:
+				return nil
+			}
 		}
 
-		// forward with callbacks
-		for _, fn := range fns {
-			fn(err)
+		// stop if closed
+		if !ok {
+			return nil
+		}
+
+		// carry over item if it does not fit
+		if !bnd.fits(item) {
+			return &item
 		}
 
-		// reset lists
-		list = list[:0]
-		chs = chs[:0]
-		fns = fns[:0]
+		// add item
+		bnd.add(item)
 	}
+
+	return nil
 }
 
-func (b *bundler) close() {
-	// acquire mutex
-	b.mutex.Lock()
-	defer b.mutex.Unlock()
+type bundle struct {
+	items   []bundlerItem
+	list    []Instruction
+	codes   [][]byte
+	errs    []error
+	size    int
+	maxSize int
+}
+
+func newBundle(batchSize, maxSize int) *bundle {
+	return &bundle{
+		items:   make([]bundlerItem, 0, batchSize),
+		list:    make([]Instruction, 0, batchSize),
+		codes:   make([][]byte, 0, batchSize),
+		errs:    make([]error, batchSize),
+		maxSize: maxSize,
+	}
+}
+
+func (b *bundle) add(item bundlerItem) {
+	b.items = append(b.items, item)
+	b.list = append(b.list, item.ins)
+	b.codes = append(b.codes, item.code)
+	b.size += item.size
+}
 
-	// close queue
-	close(b.queue)
+func (b *bundle) fits(item bundlerItem) bool {
+	return b.maxSize <= 0 || b.size+item.size <= b.maxSize
+}
+
+func (b *bundle) full() bool {
+	return len(b.list) >= cap(b.list) || (b.maxSize > 0 && b.size >= b.maxSize)
+}
+
+func (b *bundle) forward(err error) {
+	// forward errors
+	for i, item := range b.items {
+		// get error
+		e := err
+		if e == nil {
+			e = b.errs[i]
+		}
+
+		// release code
+		if item.ref != nil {
+			item.ref.Release()
+		}
 
-	// await returns
+		// forward on channel or with callback
+		if item.ch != nil {
+			item.ch <- e
+		} else if item.fn != nil {
+			item.fn(e)
+		}
+
+		// reset error
+		b.errs[i] = nil
+	}
+
+	// reset lists
+	b.items = b.items[:0]
+	b.list = b.list[:0]
+	b.codes = b.codes[:0]
+	b.size = 0
+}
+
+// stop will stop accepting new instructions and await the processing of all
+// queued instructions. If the context is cancelled before, the remaining
+// queued instructions fail with ErrStopped and the context error is returned.
+func (b *bundler) stop(ctx context.Context) error {
+	// stop accepting instructions
+	b.stopped.Do(func() {
+		// unblock waiting callers
+		close(b.stopping)
+
+		// acquire mutex
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		// close queue
+		close(b.queue)
+
+		// set flag
+		b.closed = true
+	})
+
+	// await processors
+	done := make(chan struct{})
+	go func() {
+		b.group.Wait()
+		close(done)
+	}()
+
+	// await completion or abort
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		b.abort.Do(func() {
+			close(b.aborted)
+		})
+		return ctx.Err()
+	}
+}
+
+// await will wait until all processors returned.
+func (b *bundler) await() {
 	b.group.Wait()
+}
 
-	// set flag
-	b.closed = true
+func (b *bundler) close() {
+	// stop and await processors
+	_ = b.stop(context.Background())
 }
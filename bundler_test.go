@@ -0,0 +1,249 @@
+package turing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundlerAsync(t *testing.T) {
+	var inflight, peak int64
+	var batches int64
+
+	b := newBundler(bundlerOptions{
+		queueSize:   100,
+		batchSize:   10,
+		concurrency: 2,
+		pipeline:    3,
+		async: func(worker int, list []Instruction, _ [][]byte, errs []error, done func(error)) {
+			// track in-flight bundles
+			n := atomic.AddInt64(&inflight, 1)
+			for {
+				p := atomic.LoadInt64(&peak)
+				if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+					break
+				}
+			}
+			atomic.AddInt64(&batches, 1)
+
+			// complete asynchronously
+			go func() {
+				time.Sleep(5 * time.Millisecond)
+				for i, ins := range list {
+					if ins.(*swapper).Mode == "fail" {
+						errs[i] = errors.New("failed")
+					}
+				}
+				atomic.AddInt64(&inflight, -1)
+				done(nil)
+			}()
+		},
+	})
+
+	var wg sync.WaitGroup
+	var failed int64
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ins := &swapper{}
+			if i%10 == 0 {
+				ins.Mode = "fail"
+			}
+			err := b.process(ins, nil)
+			if err != nil {
+				atomic.AddInt64(&failed, 1)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	b.close()
+
+	assert.Equal(t, int64(20), failed)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&inflight))
+	assert.True(t, peak > 1, peak)
+	assert.True(t, peak <= 6, peak)
+	assert.True(t, batches >= 20, batches)
+}
+
+func TestBundlerSync(t *testing.T) {
+	b := newBundler(bundlerOptions{
+		queueSize:   10,
+		batchSize:   10,
+		concurrency: 1,
+		handler: func(list []Instruction, errs []error) error {
+			return errors.New("failed")
+		},
+	})
+
+	err := b.process(&swapper{}, nil)
+	assert.Error(t, err)
+
+	done := make(chan error, 1)
+	err = b.process(&swapper{}, func(err error) {
+		done <- err
+	})
+	assert.NoError(t, err)
+	assert.Error(t, <-done)
+
+	b.close()
+}
+
+func TestBundlerLinger(t *testing.T) {
+	var batches int64
+	b := newBundler(bundlerOptions{
+		queueSize:   10,
+		batchSize:   10,
+		concurrency: 1,
+		handler: func(list []Instruction, errs []error) error {
+			atomic.AddInt64(&batches, 1)
+			return nil
+		},
+	})
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		err := b.process(&swapper{}, nil)
+		assert.NoError(t, err)
+	}
+	assert.True(t, time.Since(start) < 50*time.Millisecond, time.Since(start))
+	assert.Equal(t, int64(20), batches)
+
+	b.close()
+}
+
+func TestBundlerSize(t *testing.T) {
+	var mutex sync.Mutex
+	var sizes []int
+
+	b := newBundler(bundlerOptions{
+		queueSize:   100,
+		batchSize:   100,
+		concurrency: 1,
+		encode:      true,
+		maxSize:     500,
+		async: func(worker int, list []Instruction, codes [][]byte, errs []error, done func(error)) {
+			var size int
+			for i, code := range codes {
+				size += len(list[i].Describe().Name) + len(code) + itemOverhead
+			}
+			mutex.Lock()
+			sizes = append(sizes, size)
+			mutex.Unlock()
+			done(nil)
+		},
+	})
+
+	err := b.process(&swapper{Value: make([]byte, 500)}, nil)
+	assert.Error(t, err)
+	assert.Equal(t, "turing: bundler: instruction too large: 528 bytes", err.Error())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := b.process(&swapper{Value: make([]byte, 100)}, nil)
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+	b.close()
+
+	var total int
+	for _, size := range sizes {
+		assert.True(t, size <= 500, size)
+		total += size
+	}
+	assert.Equal(t, 50*127, total)
+}
+
+func TestBundlerFailFast(t *testing.T) {
+	started := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+
+	b := newBundler(bundlerOptions{
+		name:        "test",
+		queueSize:   1,
+		failFast:    true,
+		batchSize:   1,
+		concurrency: 1,
+		handler: func(list []Instruction, errs []error) error {
+			started <- struct{}{}
+			<-unblock
+			return nil
+		},
+	})
+
+	done := make(chan error, 2)
+	fn := func(err error) {
+		done <- err
+	}
+
+	err := b.process(&swapper{}, fn)
+	assert.NoError(t, err)
+	<-started
+
+	err = b.process(&swapper{}, fn)
+	assert.NoError(t, err)
+
+	err = b.process(&swapper{}, fn)
+	assert.Equal(t, ErrOverloaded, err)
+
+	close(unblock)
+	assert.NoError(t, <-done)
+	<-started
+	assert.NoError(t, <-done)
+
+	b.close()
+}
+
+func TestBundlerStop(t *testing.T) {
+	started := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+
+	b := newBundler(bundlerOptions{
+		name:        "test",
+		queueSize:   10,
+		batchSize:   1,
+		concurrency: 1,
+		handler: func(list []Instruction, errs []error) error {
+			started <- struct{}{}
+			<-unblock
+			return nil
+		},
+	})
+
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			done <- b.process(&swapper{}, nil)
+		}()
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := b.stop(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second, time.Since(start))
+
+	err = b.process(&swapper{}, nil)
+	assert.Equal(t, ErrStopped, err)
+
+	close(unblock)
+	b.await()
+
+	errs := []error{<-done, <-done}
+	assert.Contains(t, errs, nil)
+	assert.Contains(t, errs, ErrStopped)
+}
@@ -0,0 +1,483 @@
+package turing
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/cockroachdb/pebble"
+	"github.com/lni/dragonboat/v3/logger"
+)
+
+var checksumPrefix = []byte("$checksum:")
+
+// checksumLimit is the default number of keys hashed per checksum step.
+const checksumLimit = 1000
+
+// checksumRetention is the duration after which abandoned checksum states
+// are garbage collected.
+const checksumRetention = time.Hour
+
+func checksumKey(index uint64, prefix []byte) ([]byte, Ref) {
+	// borrow buffer
+	buf, ref := fpack.Borrow(len(checksumPrefix) + 8 + len(prefix))
+
+	// write key
+	n := copy(buf, checksumPrefix)
+	binary.BigEndian.PutUint64(buf[n:], index)
+	copy(buf[n+8:], prefix)
+
+	return buf, ref
+}
+
+// checksumState is the stored state of a replicated checksum.
+type checksumState struct {
+	Time int64
+	Done bool
+	Hash []byte
+	Last []byte
+}
+
+func (s *checksumState) encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Int64(s.Time)
+		enc.Bool(s.Done)
+		enc.VarBytes(s.Hash)
+		enc.VarBytes(s.Last)
+
+		return nil
+	})
+}
+
+func (s *checksumState) decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("turing: decode checksum state: invalid version")
+		}
+
+		// decode body
+		s.Time = dec.Int64()
+		s.Done = dec.Bool()
+		s.Hash = dec.VarBytes(true)
+		s.Last = dec.VarBytes(true)
+
+		return nil
+	})
+}
+
+func (s *checksumState) hash() (hash.Hash64, error) {
+	// create hash
+	hash := fnv.New64a()
+
+	// restore hash
+	if len(s.Hash) > 0 {
+		err := hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(s.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return hash, nil
+}
+
+func loadChecksumState(txn *transaction, key []byte) (*checksumState, error) {
+	// get value
+	value, closer, err := txn.reader.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// ensure close
+	defer closer.Close()
+
+	// decode state
+	var state checksumState
+	err = state.decode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// checksum is an internal instruction that computes a hash of all user keys
+// with the provided prefix. If not replicated, all keys are hashed at once.
+// Otherwise, the instruction hashes at most Limit keys per step and stores
+// its state under the index of the first step. The instruction must be
+// executed with the returned index until it reports completion. Replicas
+// apply the steps at the same indexes and thus compute the same hash, while
+// writes between steps are not blocked by a long-running scan. As every step
+// hashes the keys as of its own index, the result is not a point-in-time hash
+// of the keys at any single index. Every replicated step also deletes at most
+// one abandoned state of an earlier checksum.
+type checksum struct {
+	Prefix []byte
+	Limit  int
+	Index  uint64
+	Hash   uint64
+	Done   bool
+}
+
+var checksumDesc = &Description{
+	Name: "turing/Checksum",
+}
+
+func (c *checksum) Describe() *Description {
+	return checksumDesc
+}
+
+func (c *checksum) Effect() int {
+	return 2
+}
+
+func (c *checksum) Execute(mem Memory, _ Cache) error {
+	// get transaction
+	txn := mem.(*transaction)
+
+	// prepare state
+	state := &checksumState{Time: txn.now}
+
+	// get limit
+	limit := c.Limit
+	if limit <= 0 {
+		limit = checksumLimit
+	}
+
+	// hash all keys at once if not replicated
+	if txn.index == 0 {
+		limit = 0
+	}
+
+	// collect garbage
+	if txn.index != 0 {
+		err := c.collect(txn)
+		if err != nil {
+			return err
+		}
+	}
+
+	// start or continue checksum
+	if c.Index == 0 {
+		// set index
+		c.Index = txn.index
+	} else {
+		// prepare key
+		key, ref := checksumKey(c.Index, c.Prefix)
+		defer ref.Release()
+
+		// load state
+		var err error
+		state, err = loadChecksumState(txn, key)
+		if err != nil {
+			return err
+		}
+
+		// handle collected checksums
+		if state == nil {
+			c.Index = 0
+			c.Hash = 0
+			c.Done = true
+			return nil
+		}
+	}
+
+	// restore hash
+	hash, err := state.hash()
+	if err != nil {
+		return err
+	}
+
+	// handle finished checksums
+	if state.Done {
+		c.Hash = hash.Sum64()
+		c.Done = true
+		return nil
+	}
+
+	// prepare buffer
+	size := make([]byte, 8)
+
+	// prepare iterator
+	iter := mem.Iterate(c.Prefix)
+	ok := iter.First()
+	if len(state.Last) > 0 {
+		ok = iter.SeekGE(state.Last)
+		if ok && string(iter.TempKey()) == string(state.Last) {
+			ok = iter.Next()
+		}
+	}
+
+	// hash keys and values
+	var count int
+	for ; ok; ok = iter.Next() {
+		// check limit
+		if limit > 0 && count >= limit {
+			break
+		}
+
+		// hash key and value
+		err := iter.Use(func(key, value []byte) error {
+			binary.BigEndian.PutUint64(size, uint64(len(key)))
+			_, _ = hash.Write(size)
+			_, _ = hash.Write(key)
+			binary.BigEndian.PutUint64(size, uint64(len(value)))
+			_, _ = hash.Write(size)
+			_, _ = hash.Write(value)
+			return nil
+		})
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		// remember key
+		state.Last = append(state.Last[:0], iter.TempKey()...)
+		count++
+	}
+
+	// close iterator
+	err = iter.Close()
+	if err != nil {
+		return err
+	}
+
+	// set result
+	c.Hash = hash.Sum64()
+	c.Done = !ok
+
+	// skip storage if not replicated
+	if txn.index == 0 {
+		return nil
+	}
+
+	// save hash
+	state.Done = c.Done
+	state.Hash, err = hash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	// encode state
+	value, valueRef, err := state.encode()
+	if err != nil {
+		return err
+	}
+	defer valueRef.Release()
+
+	// check effect
+	err = txn.checkEffect()
+	if err != nil {
+		return err
+	}
+
+	// store state
+	key, ref := checksumKey(c.Index, c.Prefix)
+	defer ref.Release()
+	err = txn.writer.Set(key, value, nil)
+	if err != nil {
+		return err
+	}
+
+	// increment effect
+	txn.effect++
+	txn.writes++
+
+	return nil
+}
+
+// collect will delete the first abandoned state. As every checksum stores one
+// state and runs at least one step, abandoned states are still deleted faster
+// than they are created.
+func (c *checksum) collect(txn *transaction) error {
+	// prepare iterator
+	iter := txn.reader.NewIter(prefixIterator(checksumPrefix))
+
+	// delete first abandoned state
+	for iter.First(); iter.Valid(); iter.Next() {
+		// decode state
+		var state checksumState
+		err := state.decode(iter.Value())
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		// check age
+		if txn.now-state.Time < int64(checksumRetention) {
+			continue
+		}
+
+		// check effect
+		err = txn.checkEffect()
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		// delete state
+		err = txn.writer.Delete(iter.Key(), nil)
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		// increment effect
+		txn.effect++
+		txn.writes++
+
+		break
+	}
+
+	return iter.Close()
+}
+
+func (c *checksum) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarBytes(c.Prefix)
+		enc.VarInt(int64(c.Limit))
+		enc.Uint64(c.Index)
+		enc.Uint64(c.Hash)
+		enc.Bool(c.Done)
+
+		return nil
+	})
+}
+
+func (c *checksum) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("turing: decode checksum: invalid version")
+		}
+
+		// decode body
+		c.Prefix = dec.VarBytes(true)
+		c.Limit = int(dec.VarInt())
+		c.Index = dec.Uint64()
+		c.Hash = dec.Uint64()
+		c.Done = dec.Bool()
+
+		return nil
+	})
+}
+
+// checksumVerify is an internal instruction that compares the hash stored by
+// a previous checksum with the expected hash and reports mismatches.
+type checksumVerify struct {
+	Prefix []byte
+	Index  uint64
+	Hash   uint64
+}
+
+var checksumVerifyDesc = &Description{
+	Name:     "turing/ChecksumVerify",
+	NoResult: true,
+}
+
+func (v *checksumVerify) Describe() *Description {
+	return checksumVerifyDesc
+}
+
+func (v *checksumVerify) Effect() int {
+	return 1
+}
+
+func (v *checksumVerify) Execute(mem Memory, _ Cache) error {
+	// get transaction
+	txn := mem.(*transaction)
+
+	// prepare key
+	key, ref := checksumKey(v.Index, v.Prefix)
+	defer ref.Release()
+
+	// load state
+	state, err := loadChecksumState(txn, key)
+	if err != nil {
+		return err
+	} else if state == nil {
+		checksumMetrics.WithLabelValues("missing").Inc()
+		logger.GetLogger("turing").Warningf("checksum missing: index %d, prefix %q", v.Index, v.Prefix)
+		return nil
+	}
+
+	// restore hash
+	sum, err := state.hash()
+	if err != nil {
+		return err
+	}
+	hash := sum.Sum64()
+
+	// compare hash
+	if !state.Done {
+		checksumMetrics.WithLabelValues("incomplete").Inc()
+		logger.GetLogger("turing").Warningf("checksum incomplete: index %d, prefix %q", v.Index, v.Prefix)
+	} else if hash != v.Hash {
+		checksumMetrics.WithLabelValues("mismatch").Inc()
+		logger.GetLogger("turing").Errorf("checksum mismatch: index %d, prefix %q: expected %016x, got %016x", v.Index, v.Prefix, v.Hash, hash)
+	} else {
+		checksumMetrics.WithLabelValues("match").Inc()
+	}
+
+	// check effect
+	err = txn.checkEffect()
+	if err != nil {
+		return err
+	}
+
+	// delete stored hash
+	err = txn.writer.Delete(key, nil)
+	if err != nil {
+		return err
+	}
+
+	// increment effect
+	txn.effect++
+	txn.writes++
+
+	return nil
+}
+
+func (v *checksumVerify) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarBytes(v.Prefix)
+		enc.Uint64(v.Index)
+		enc.Uint64(v.Hash)
+
+		return nil
+	})
+}
+
+func (v *checksumVerify) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("turing: decode checksum verify: invalid version")
+		}
+
+		// decode body
+		v.Prefix = dec.VarBytes(true)
+		v.Index = dec.Uint64()
+		v.Hash = dec.Uint64()
+
+		return nil
+	})
+}
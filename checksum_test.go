@@ -0,0 +1,209 @@
+package turing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksum(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&expiringSet{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db1, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	db2, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	for _, db := range []*database{db1, db2} {
+		err = db.update([]Instruction{
+			&expiringSet{Key: []byte("foo"), Value: []byte("bar"), TTL: time.Hour},
+			&expiringSet{Key: []byte("baz"), Value: []byte("qux"), TTL: time.Hour},
+		}, nil, 1, now, 0)
+		assert.NoError(t, err)
+	}
+
+	sum1 := &checksum{Prefix: []byte("")}
+	err = db1.update([]Instruction{sum1}, nil, 2, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), sum1.Index)
+	assert.NotZero(t, sum1.Hash)
+
+	sum2 := &checksum{Prefix: []byte("")}
+	err = db2.update([]Instruction{sum2}, nil, 2, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, *sum1, *sum2)
+
+	sum3 := &checksum{Prefix: []byte("f")}
+	err = db2.update([]Instruction{sum3}, nil, 3, now, 0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sum1.Hash, sum3.Hash)
+
+	match := testutil.ToFloat64(checksumMetrics.WithLabelValues("match"))
+	mismatch := testutil.ToFloat64(checksumMetrics.WithLabelValues("mismatch"))
+
+	err = db2.update([]Instruction{&checksumVerify{Index: 2, Hash: sum1.Hash}}, nil, 4, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, match+1, testutil.ToFloat64(checksumMetrics.WithLabelValues("match")))
+	assert.Equal(t, 1, countKeys(db2, checksumPrefix))
+
+	// diverge
+	err = db1.pebble.Set(append(Clone(userPrefix), "quz"...), []byte{1, 1, 1}, pebble.Sync)
+	assert.NoError(t, err)
+
+	sum4 := &checksum{}
+	err = db1.update([]Instruction{sum4}, nil, 5, now, 0)
+	assert.NoError(t, err)
+
+	sum5 := &checksum{}
+	err = db2.update([]Instruction{sum5}, nil, 5, now, 0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sum4.Hash, sum5.Hash)
+
+	err = db2.update([]Instruction{&checksumVerify{Index: 5, Hash: sum4.Hash}}, nil, 6, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, mismatch+1, testutil.ToFloat64(checksumMetrics.WithLabelValues("mismatch")))
+
+	assert.NoError(t, db1.close())
+	assert.NoError(t, db2.close())
+}
+
+func TestMachineChecksum(t *testing.T) {
+	machine := Test(&expiringSet{})
+	defer machine.Stop()
+
+	index, hash1, err := machine.Checksum(nil)
+	assert.NoError(t, err)
+	assert.Zero(t, index)
+
+	err = machine.Execute(&expiringSet{Key: []byte("foo"), Value: []byte("bar"), TTL: time.Hour})
+	assert.NoError(t, err)
+
+	_, hash2, err := machine.Checksum(nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash1, hash2)
+
+	_, hash3, err := machine.Checksum(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, hash2, hash3)
+}
+
+func TestChecksumSteps(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&expiringSet{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	err = db.update([]Instruction{
+		&expiringSet{Key: []byte("a"), Value: []byte("1"), TTL: time.Hour},
+		&expiringSet{Key: []byte("b"), Value: []byte("2"), TTL: time.Hour},
+		&expiringSet{Key: []byte("c"), Value: []byte("3"), TTL: time.Hour},
+	}, nil, 1, now, 0)
+	assert.NoError(t, err)
+
+	full := &checksum{}
+	err = db.update([]Instruction{full}, nil, 0, now, 0)
+	assert.NoError(t, err)
+	assert.True(t, full.Done)
+
+	sum := &checksum{Limit: 2}
+	err = db.update([]Instruction{sum}, nil, 2, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), sum.Index)
+	assert.False(t, sum.Done)
+
+	incomplete := testutil.ToFloat64(checksumMetrics.WithLabelValues("incomplete"))
+	err = db.update([]Instruction{&checksumVerify{Index: 2, Hash: full.Hash}}, nil, 3, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, incomplete+1, testutil.ToFloat64(checksumMetrics.WithLabelValues("incomplete")))
+
+	sum = &checksum{Limit: 2}
+	err = db.update([]Instruction{sum}, nil, 4, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), sum.Index)
+	assert.False(t, sum.Done)
+
+	err = db.update([]Instruction{sum}, nil, 5, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), sum.Index)
+	assert.True(t, sum.Done)
+	assert.Equal(t, full.Hash, sum.Hash)
+
+	match := testutil.ToFloat64(checksumMetrics.WithLabelValues("match"))
+	err = db.update([]Instruction{&checksumVerify{Index: 4, Hash: full.Hash}}, nil, 6, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, match+1, testutil.ToFloat64(checksumMetrics.WithLabelValues("match")))
+	assert.Equal(t, 0, countKeys(db, checksumPrefix))
+
+	assert.NoError(t, db.close())
+}
+
+func TestChecksumCollect(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&expiringSet{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	err = db.update([]Instruction{
+		&expiringSet{Key: []byte("a"), Value: []byte("1"), TTL: 2 * time.Hour},
+		&expiringSet{Key: []byte("b"), Value: []byte("2"), TTL: 2 * time.Hour},
+	}, nil, 1, now, 0)
+	assert.NoError(t, err)
+
+	sum1 := &checksum{Limit: 1}
+	err = db.update([]Instruction{sum1}, nil, 2, now, 0)
+	assert.NoError(t, err)
+	assert.False(t, sum1.Done)
+
+	sum2 := &checksum{}
+	err = db.update([]Instruction{sum2}, nil, 3, now, 0)
+	assert.NoError(t, err)
+	assert.True(t, sum2.Done)
+	assert.Equal(t, 2, countKeys(db, checksumPrefix))
+
+	later := now + int64(checksumRetention)
+
+	sum3 := &checksum{}
+	err = db.update([]Instruction{sum3}, nil, 4, later, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countKeys(db, checksumPrefix))
+
+	err = db.update([]Instruction{sum1}, nil, 5, later, 0)
+	assert.NoError(t, err)
+	assert.True(t, sum1.Done)
+	assert.Zero(t, sum1.Index)
+	assert.Zero(t, sum1.Hash)
+	assert.Equal(t, 1, countKeys(db, checksumPrefix))
+
+	assert.NoError(t, db.close())
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/cockroachdb/pebble"
+
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/tape"
+)
+
+// unresolvedMarker marks values produced by the inspection merger. Encoded
+// cells always start with a small version number.
+const unresolvedMarker = 0xff
+
+// The inspection merger does not resolve operands. Instead, it yields all
+// merged cells from oldest to newest so they can be printed.
+var merger = &pebble.Merger{
+	Name: "turing", // must match the database merger
+	Merge: func(key, value []byte) (pebble.ValueMerger, error) {
+		return &unresolved{values: [][]byte{turing.Clone(value)}}, nil
+	},
+}
+
+type unresolved struct {
+	values [][]byte
+}
+
+func (u *unresolved) MergeNewer(value []byte) error {
+	u.values = append(u.values, turing.Clone(value))
+	return nil
+}
+
+func (u *unresolved) MergeOlder(value []byte) error {
+	u.values = append([][]byte{turing.Clone(value)}, u.values...)
+	return nil
+}
+
+func (u *unresolved) Finish(bool) ([]byte, io.Closer, error) {
+	// encode values
+	buf, _, err := fpack.Encode(false, func(enc *fpack.Encoder) error {
+		enc.Uint8(unresolvedMarker)
+		enc.VarUint(uint64(len(u.values)))
+		for _, value := range u.values {
+			enc.VarBytes(value)
+		}
+		return nil
+	})
+
+	return buf, nil, err
+}
+
+func decodeUnresolved(buf []byte) ([][]byte, error) {
+	var values [][]byte
+	err := fpack.Decode(buf, func(dec *fpack.Decoder) error {
+		// skip marker
+		dec.Uint8()
+
+		// decode values
+		values = make([][]byte, dec.VarUint())
+		for i := range values {
+			values[i] = dec.VarBytes(false)
+		}
+
+		return nil
+	})
+
+	return values, err
+}
+
+func open(dir string) (*pebble.DB, error) {
+	// check directory
+	_, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return pebble.Open(dir, &pebble.Options{
+		ReadOnly: true,
+		Merger:   merger,
+	})
+}
+
+type inspector struct {
+	db  *pebble.DB
+	out io.Writer
+	max int
+	hex bool
+}
+
+func (i *inspector) state() error {
+	// get state
+	value, closer, err := i.db.Get(turing.StateKey())
+	if err == pebble.ErrNotFound {
+		return fmt.Errorf("missing state")
+	} else if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	// decode state
+	var state tape.State
+	err = state.Decode(value)
+	if err != nil {
+		return err
+	}
+
+	// print state
+	w := tabwriter.NewWriter(i.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Index:\t%d\n", state.Index)
+	fmt.Fprintf(w, "Batch:\t%d\n", state.Batch)
+	fmt.Fprintf(w, "Last:\t%d\n", state.Last)
+
+	// print sync
+	value, closer2, err := i.db.Get(turing.SyncKey())
+	if err == nil {
+		fmt.Fprintf(w, "Sync:\t%s\n", value)
+		_ = closer2.Close()
+	} else if err != pebble.ErrNotFound {
+		return err
+	}
+
+	return w.Flush()
+}
+
+func (i *inspector) keys(prefix []byte, limit int) error {
+	// prepare bounds
+	lower, upper := turing.PrefixRange(turing.UserKey(prefix))
+
+	// create iterator
+	iter := i.db.NewIter(&pebble.IterOptions{
+		LowerBound: lower,
+		UpperBound: upper,
+	})
+
+	// list keys
+	w := tabwriter.NewWriter(i.out, 0, 0, 2, ' ', 0)
+	count := 0
+	for iter.First(); iter.Valid() && (limit <= 0 || count < limit); iter.Next() {
+		key, _ := turing.TrimUserKey(iter.Key())
+		fmt.Fprintf(w, "%s\t%s\n", i.format(key, 0), i.summary(iter.Value()))
+		count++
+	}
+
+	// close iterator
+	err := iter.Close()
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+func (i *inspector) get(key []byte) error {
+	// get value
+	value, closer, err := i.db.Get(turing.UserKey(key))
+	if err == pebble.ErrNotFound {
+		return fmt.Errorf("missing key")
+	} else if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	// handle unresolved values
+	if len(value) > 0 && value[0] == unresolvedMarker {
+		values, err := decodeUnresolved(value)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(i.out, "Unresolved: %d cells (oldest first)\n", len(values))
+		for j, value := range values {
+			fmt.Fprintf(i.out, "Cell %d:\n", j)
+			err = i.cell(value, "  ")
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return i.cell(value, "")
+}
+
+func (i *inspector) cell(value []byte, indent string) error {
+	// decode cell
+	var cell tape.Cell
+	err := cell.Decode(value, false)
+	if err != nil {
+		return err
+	}
+
+	// print expiry
+	if cell.Expiry != 0 {
+		fmt.Fprintf(i.out, "%sExpiry: %s\n", indent, time.Unix(0, cell.Expiry).UTC().Format(time.RFC3339Nano))
+	}
+
+	// print raw cell
+	if cell.Type == tape.RawCell {
+		fmt.Fprintf(i.out, "%sType: Raw\n", indent)
+		fmt.Fprintf(i.out, "%sSize: %d\n", indent, len(cell.Value))
+		fmt.Fprintf(i.out, "%sValue: %s\n", indent, i.format(cell.Value, i.max))
+		return nil
+	}
+
+	// decode stack
+	var stack tape.Stack
+	err = stack.Decode(cell.Value, false)
+	if err != nil {
+		return err
+	}
+
+	// print stack cell
+	fmt.Fprintf(i.out, "%sType: Stack\n", indent)
+	fmt.Fprintf(i.out, "%sOperands: %d\n", indent, len(stack.Operands))
+	for _, op := range stack.Operands {
+		fmt.Fprintf(i.out, "%s  %s: %s\n", indent, op.Name, i.format(op.Value, i.max))
+	}
+
+	return nil
+}
+
+func (i *inspector) summary(value []byte) string {
+	// handle unresolved values
+	if len(value) > 0 && value[0] == unresolvedMarker {
+		values, err := decodeUnresolved(value)
+		if err != nil {
+			return "invalid"
+		}
+		return fmt.Sprintf("unresolved (%d cells)", len(values))
+	}
+
+	// decode cell
+	var cell tape.Cell
+	err := cell.Decode(value, false)
+	if err != nil {
+		return "invalid"
+	}
+
+	// format cell
+	var str string
+	if cell.Type == tape.RawCell {
+		str = fmt.Sprintf("raw (%d bytes)", len(cell.Value))
+	} else {
+		var stack tape.Stack
+		err = stack.Decode(cell.Value, false)
+		if err != nil {
+			return "invalid"
+		}
+		str = fmt.Sprintf("stack (%d operands)", len(stack.Operands))
+	}
+	if cell.Expiry != 0 {
+		str += " expires " + time.Unix(0, cell.Expiry).UTC().Format(time.RFC3339)
+	}
+
+	return str
+}
+
+type keyspace struct {
+	name   string
+	count  int
+	keys   int
+	values int
+}
+
+func (i *inspector) stats() error {
+	// prepare keyspaces
+	spaces := map[string]*keyspace{}
+	get := func(name string) *keyspace {
+		if spaces[name] == nil {
+			spaces[name] = &keyspace{name: name}
+		}
+		return spaces[name]
+	}
+
+	// create iterator
+	iter := i.db.NewIter(nil)
+
+	// collect stats
+	for iter.First(); iter.Valid(); iter.Next() {
+		// get key
+		key := iter.Key()
+
+		// classify key
+		space := get(turing.ClassifyKey(key))
+
+		// update stats
+		space.count++
+		space.keys += len(key)
+		space.values += len(iter.Value())
+	}
+
+	// close iterator
+	err := iter.Close()
+	if err != nil {
+		return err
+	}
+
+	// sort keyspaces
+	list := make([]*keyspace, 0, len(spaces))
+	for _, space := range spaces {
+		list = append(list, space)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].name < list[b].name
+	})
+
+	// print stats
+	w := tabwriter.NewWriter(i.out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Keyspace\tKeys\tKey Bytes\tValue Bytes\t\n")
+	for _, space := range list {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t\n", space.name, space.count, space.keys, space.values)
+	}
+
+	return w.Flush()
+}
+
+func (i *inspector) parse(str string) ([]byte, error) {
+	// decode hex
+	if i.hex {
+		return hex.DecodeString(str)
+	}
+
+	return []byte(str), nil
+}
+
+func (i *inspector) format(buf []byte, max int) string {
+	// truncate
+	suffix := ""
+	if max > 0 && len(buf) > max {
+		buf = buf[:max]
+		suffix = "..."
+	}
+
+	// format
+	if i.hex {
+		return hex.EncodeToString(buf) + suffix
+	}
+
+	return strconv.Quote(string(buf)) + suffix
+}
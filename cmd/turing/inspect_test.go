@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/stdset"
+)
+
+func TestMain(m *testing.M) {
+	turing.SetLogger(nil)
+	os.Exit(m.Run())
+}
+
+func TestInspector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "turing")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	machine, err := turing.Start(turing.Config{
+		Directory:    dir,
+		Standalone:   true,
+		Instructions: []turing.Instruction{&stdset.Set{}, &stdset.Inc{}},
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&stdset.Set{Key: []byte("foo"), Value: []byte("bar")})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&stdset.Set{Key: []byte("baz"), Value: []byte("qux"), TTL: time.Hour})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&stdset.Inc{Key: []byte("cnt"), Value: 7})
+	assert.NoError(t, err)
+
+	machine.Stop()
+
+	db, err := open(turing.Config{Directory: dir}.DatabaseDir())
+	assert.NoError(t, err)
+	defer db.Close()
+
+	var out bytes.Buffer
+	ins := &inspector{db: db, out: &out, max: 64}
+
+	err = ins.state()
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Index:")
+
+	out.Reset()
+	err = ins.keys(nil, 0)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `"baz"`)
+	assert.Contains(t, out.String(), `"cnt"`)
+	assert.Contains(t, out.String(), `"foo"  raw (3 bytes)`)
+
+	out.Reset()
+	err = ins.keys([]byte("f"), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "\"foo\"  raw (3 bytes)\n", out.String())
+
+	out.Reset()
+	err = ins.get([]byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Type: Raw\nSize: 3\nValue: \"bar\"\n", out.String())
+
+	out.Reset()
+	err = ins.get([]byte("baz"))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Expiry: ")
+
+	out.Reset()
+	err = ins.get([]byte("cnt"))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "turing/Add: \"7\"")
+
+	err = ins.get([]byte("nope"))
+	assert.Error(t, err)
+
+	out.Reset()
+	err = ins.stats()
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "# (user)")
+	assert.Contains(t, out.String(), "$ttl: (expiry)")
+	assert.Contains(t, out.String(), "$state")
+}
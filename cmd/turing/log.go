@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/resp"
+	"github.com/256dpi/turing/stdset"
+	"github.com/256dpi/turing/stdset/queue"
+)
+
+// known returns the instructions that are decoded when printing the log.
+func known() []turing.Instruction {
+	return append(resp.Instructions(),
+		&stdset.Inc{},
+		&stdset.CAS{},
+		&stdset.DeleteRange{},
+		&queue.Enqueue{},
+		&queue.Dequeue{},
+		&queue.Ack{},
+		&queue.Nack{},
+		&queue.Requeue{},
+	)
+}
+
+func (i *inspector) log(directory string, payloads bool) error {
+	// prepare config
+	config := turing.Config{
+		Directory:    directory,
+		Instructions: known(),
+	}
+
+	// print entries
+	w := tabwriter.NewWriter(i.out, 0, 0, 2, ' ', 0)
+	err := turing.ReadLog(config, func(entry turing.LogEntry) error {
+		// print entry
+		fmt.Fprintf(w, "%d\t%d\t%s\t%d bytes\t", entry.Index, entry.Term, entry.Type, entry.Size)
+
+		// print command
+		if entry.Command != nil {
+			names := make([]string, 0, len(entry.Command.Operations))
+			for _, op := range entry.Command.Operations {
+				names = append(names, fmt.Sprintf("%s (%d bytes)", op.Name, len(op.Code)))
+			}
+			stamp := "-"
+			if entry.Command.Time != 0 {
+				stamp = time.Unix(0, entry.Command.Time).UTC().Format(time.RFC3339Nano)
+			}
+			fmt.Fprintf(w, "%s\t%s", stamp, strings.Join(names, ", "))
+		}
+
+		// print error
+		if entry.Error != nil {
+			fmt.Fprintf(w, "\terror: %s", entry.Error)
+		}
+		fmt.Fprintln(w)
+
+		// print payloads
+		if payloads && entry.Command != nil {
+			for j, op := range entry.Command.Operations {
+				if j < len(entry.Instructions) && entry.Instructions[j] != nil {
+					fmt.Fprintf(w, "\t\t\t\t\t  %s %+v\n", op.Name, entry.Instructions[j])
+				} else {
+					fmt.Fprintf(w, "\t\t\t\t\t  %s %s\n", op.Name, i.format(op.Code, i.max))
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+func (i *inspector) backup(path string) error {
+	// open file
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// verify backup
+	info, err := turing.VerifyBackup(file)
+	if err != nil {
+		return err
+	}
+
+	// print info
+	w := tabwriter.NewWriter(i.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Index:\t%d\n", info.State.Index)
+	fmt.Fprintf(w, "Batch:\t%d\n", info.State.Batch)
+	fmt.Fprintf(w, "Last:\t%d\n", info.State.Last)
+	fmt.Fprintf(w, "Keys:\t%d\n", info.Keys)
+	fmt.Fprintf(w, "User Keys:\t%d\n", info.UserKeys)
+	fmt.Fprintf(w, "Bytes:\t%d\n", info.Bytes)
+
+	return w.Flush()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/stdset"
+	"github.com/256dpi/turing/tape"
+)
+
+func TestInspectorLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "turing")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	machine, err := turing.Start(turing.Config{
+		ID:            1,
+		Members:       []turing.Member{{ID: 1, Host: "127.0.0.1", Port: 42002}},
+		Directory:     dir,
+		Instructions:  []turing.Instruction{&stdset.Set{}},
+		RoundTripTime: time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	for machine.Status().Role != turing.RoleLeader {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	err = machine.Execute(&stdset.Set{Key: []byte("foo"), Value: []byte("bar")})
+	assert.NoError(t, err)
+
+	machine.Stop()
+
+	var out bytes.Buffer
+	ins := &inspector{out: &out, max: 64}
+
+	err = ins.log(dir, true)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "turing/Set (")
+	assert.Contains(t, out.String(), `Key:[102 111 111]`)
+}
+
+func TestInspectorBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "turing")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	state := tape.State{Index: 42, Batch: 1, Last: 1}
+	value, _, err := state.Encode(false)
+	assert.NoError(t, err)
+
+	cell := tape.Cell{Type: tape.RawCell, Value: []byte("bar")}
+	raw, _, err := cell.Encode(false)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	for _, pair := range [][2][]byte{
+		{[]byte("#foo"), raw},
+		{[]byte("$state"), value},
+	} {
+		num := make([]byte, 8)
+		binary.BigEndian.PutUint64(num, uint64(len(pair[0])))
+		buf.Write(num)
+		buf.Write(pair[0])
+		binary.BigEndian.PutUint64(num, uint64(len(pair[1])))
+		buf.Write(num)
+		buf.Write(pair[1])
+	}
+
+	path := filepath.Join(dir, "backup")
+	err = ioutil.WriteFile(path, buf.Bytes(), 0644)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	ins := &inspector{out: &out}
+
+	err = ins.backup(path)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Index:      42")
+	assert.Contains(t, out.String(), "User Keys:  1")
+
+	err = ioutil.WriteFile(path, buf.Bytes()[:buf.Len()-2], 0644)
+	assert.NoError(t, err)
+
+	err = ins.backup(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+// Command turing inspects the database and raft log of a stopped node.
+//
+// Usage:
+//
+//	turing [flags] state         print the database state
+//	turing [flags] keys          list user keys
+//	turing [flags] get <key>     print the cell stored at a user key
+//	turing [flags] stats         report key counts and sizes per keyspace
+//	turing [flags] log           list the entries of the raft log
+//	turing [flags] backup <file> verify a snapshot stream and print its state
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/256dpi/turing"
+)
+
+var directory = flag.String("directory", "data", "the node directory (Config.Directory)")
+var prefix = flag.String("prefix", "", "the key prefix used by keys")
+var limit = flag.Int("limit", 0, "the maximum number of keys listed by keys")
+var maxBytes = flag.Int("max", 64, "the maximum number of value bytes printed (0 = all)")
+var hexMode = flag.Bool("hex", false, "parse and print keys and values as hex")
+var payloads = flag.Bool("payloads", false, "print decoded instruction payloads in log")
+
+func main() {
+	// parse flags
+	flag.Usage = usage
+	flag.Parse()
+
+	// check command
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	// prepare inspector
+	ins := &inspector{
+		out: os.Stdout,
+		max: *maxBytes,
+		hex: *hexMode,
+	}
+
+	// run raft commands
+	switch flag.Arg(0) {
+	case "log":
+		exit(ins.log(*directory, *payloads))
+		return
+	case "backup":
+		if flag.NArg() != 2 {
+			exit(fmt.Errorf("backup requires a file"))
+			return
+		}
+		exit(ins.backup(flag.Arg(1)))
+		return
+	}
+
+	// open database
+	db, err := open(turing.Config{Directory: *directory}.DatabaseDir())
+	if err != nil {
+		exit(err)
+		return
+	}
+
+	// set database
+	ins.db = db
+
+	// run command
+	switch flag.Arg(0) {
+	case "state":
+		err = ins.state()
+	case "keys":
+		var p []byte
+		p, err = ins.parse(*prefix)
+		if err == nil {
+			err = ins.keys(p, *limit)
+		}
+	case "get":
+		if flag.NArg() != 2 {
+			err = fmt.Errorf("get requires a key")
+			break
+		}
+		var key []byte
+		key, err = ins.parse(flag.Arg(1))
+		if err == nil {
+			err = ins.get(key)
+		}
+	case "stats":
+		err = ins.stats()
+	default:
+		err = fmt.Errorf("unknown command: %s", flag.Arg(0))
+	}
+
+	// close database
+	_ = db.Close()
+
+	// handle error
+	exit(err)
+}
+
+func exit(err error) {
+	// handle error
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "turing:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: turing [flags] state|keys|get <key>|stats|log|backup <file>")
+	flag.PrintDefaults()
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/lni/dragonboat/v3/logger"
+
 	"github.com/256dpi/turing/tape"
 )
 
@@ -78,8 +80,18 @@ func (c *computer) combine(cells []tape.Cell) (tape.Cell, Ref, error) {
 		}
 
 		// combine operands
-		result, ref, err := operator.Combine(ops)
-		if err != nil {
+		result, ref, err := combineOperands(operator, ops)
+		if _, ok := err.(*PanicError); ok {
+			// keep operands to be applied later
+			for _, op := range ops {
+				stack.Operands = append(stack.Operands, tape.Operand{
+					Name:  operator.Name,
+					Value: op,
+				})
+			}
+
+			return nil
+		} else if err != nil {
 			return err
 		}
 
@@ -163,20 +175,26 @@ func (c *computer) apply(cells []tape.Cell) (tape.Cell, Ref, error) {
 		}
 
 		// merge base with operands
-		var newRef Ref
-		var err error
-		base, newRef, err = op.Apply(base, ops)
-		if err != nil {
+		result, newRef, err := applyOperands(op, base, ops)
+		if _, ok := err.(*PanicError); ok {
+			// apply operands one by one and drop the panicking ones
+			var applied bool
+			result, newRef, applied, err = applyEach(op, base, ops)
+			if err != nil {
+				return err
+			} else if !applied {
+				return nil
+			}
+		} else if err != nil {
 			return err
 		}
 
-		// release old ref
-		if ref != nil {
-			ref.Release()
-		}
+		// set new base
+		base = result
 
-		// set new ref
+		// replace ref, a result without a ref may still reference the old base
 		if newRef != nil {
+			ref.Release()
 			ref = newRef
 		}
 
@@ -186,10 +204,11 @@ func (c *computer) apply(cells []tape.Cell) (tape.Cell, Ref, error) {
 		return tape.Cell{}, nil, err
 	}
 
-	// prepare result
+	// prepare result (retaining the base expiry)
 	result := tape.Cell{
-		Type:  tape.RawCell,
-		Value: base,
+		Type:   tape.RawCell,
+		Value:  base,
+		Expiry: cells[0].Expiry,
 	}
 
 	return result, ref, nil
@@ -271,6 +290,65 @@ func (c *computer) pipeline(names []string, values [][]byte, fn func(op *Operato
 	return nil
 }
 
+func combineOperands(operator *Operator, ops [][]byte) (result []byte, ref Ref, err error) {
+	// convert panics
+	defer func() {
+		if val := recover(); val != nil {
+			result, ref, err = nil, nil, recovered(operator.Name, val)
+		}
+	}()
+
+	return operator.Combine(ops)
+}
+
+func applyOperands(operator *Operator, base []byte, ops [][]byte) (result []byte, ref Ref, err error) {
+	// convert panics
+	defer func() {
+		if val := recover(); val != nil {
+			result, ref, err = nil, nil, recovered(operator.Name, val)
+		}
+	}()
+
+	return operator.Apply(base, ops)
+}
+
+// applyEach will apply the operands one by one and drop every operand that
+// causes the operator to panic. As operators are deterministic, the same
+// operands are dropped on all replicas and the base value is retained if all
+// operands have been dropped.
+func applyEach(operator *Operator, base []byte, ops [][]byte) ([]byte, Ref, bool, error) {
+	// apply operands
+	var ref Ref
+	var applied bool
+	for _, op := range ops {
+		// apply operand
+		result, newRef, err := applyOperands(operator, base, [][]byte{op})
+		if _, ok := err.(*PanicError); ok {
+			logger.GetLogger("turing").Errorf("dropped operand of %s: %s", operator.Name, err.Error())
+			continue
+		} else if err != nil {
+			if ref != nil {
+				ref.Release()
+			}
+			return nil, nil, false, err
+		}
+
+		// set base
+		base = result
+		applied = true
+
+		// replace ref, a result without a ref may still reference the old base
+		if newRef != nil {
+			if ref != nil {
+				ref.Release()
+			}
+			ref = newRef
+		}
+	}
+
+	return base, ref, applied, nil
+}
+
 func (c *computer) recycle() {
 	// unset registry
 	c.registry = nil
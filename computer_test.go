@@ -194,6 +194,93 @@ func TestComputerApply(t *testing.T) {
 	}))
 }
 
+type countingRef struct {
+	released int
+}
+
+func (r *countingRef) Release() {
+	r.released++
+}
+
+func TestComputerApplyRefs(t *testing.T) {
+	cells := []tape.Cell{
+		{
+			Type:  tape.RawCell,
+			Value: []byte("a"),
+		},
+		{
+			Type: tape.StackCell,
+			Value: mustEncodeStack(tape.Stack{
+				Operands: []tape.Operand{
+					{
+						Name:  "op1",
+						Value: []byte("b"),
+					},
+					{
+						Name:  "op2",
+						Value: []byte("c"),
+					},
+					{
+						Name:  "op1",
+						Value: []byte("d"),
+					},
+				},
+			}),
+		},
+	}
+
+	var refs []*countingRef
+
+	registry := &registry{
+		ops: map[string]*Operator{
+			"op1": {
+				Name: "op1",
+				Apply: func(value []byte, ops [][]byte) ([]byte, Ref, error) {
+					// clone value
+					value = Clone(value)
+
+					// concat operands
+					for _, op := range ops {
+						value = append(value, op...)
+					}
+
+					// track ref
+					ref := &countingRef{}
+					refs = append(refs, ref)
+
+					return value, ref, nil
+				},
+			},
+			"op2": {
+				Name: "op2",
+				Apply: func(value []byte, ops [][]byte) ([]byte, Ref, error) {
+					// the value must still be valid
+					for _, ref := range refs {
+						assert.Equal(t, 0, ref.released)
+					}
+
+					return value, nil, nil
+				},
+			},
+		},
+	}
+
+	computer := newComputer(registry)
+	result, ref, err := computer.apply(cells)
+	assert.NoError(t, err)
+	assert.Equal(t, tape.Cell{
+		Type:  tape.RawCell,
+		Value: []byte("abd"),
+	}, result)
+	assert.Len(t, refs, 2)
+	assert.Equal(t, 1, refs[0].released)
+	assert.Equal(t, 0, refs[1].released)
+	assert.Equal(t, refs[1], ref)
+
+	ref.Release()
+	assert.Equal(t, 1, refs[1].released)
+}
+
 func TestComputerResolve(t *testing.T) {
 	cell := tape.Cell{
 		Type: tape.StackCell,
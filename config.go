@@ -10,6 +10,7 @@ import (
 	"time"
 
 	pfs "github.com/cockroachdb/pebble/vfs"
+	"github.com/lni/dragonboat/v3/config"
 	dfs "github.com/lni/goutils/vfs"
 )
 
@@ -35,13 +36,20 @@ type Config struct {
 	/* Performance Tuning */
 
 	// The maximum effect that can be reported by an instruction. Instructions
-	// with a bigger effect must report an unbounded effect. Increasing the
+	// with a bigger effect must report an unbounded effect. The value also
+	// limits the number of writes per transaction, where setting a key with a
+	// TTL or merging into an expired value counts as two writes. Increasing the
 	// value will allow more throughput as more instructions are executed using
 	// the same transaction.
 	//
 	// Default: 10_000.
 	MaxEffect int
 
+	// StrictEffect may be set to enforce the declared effect of bounded
+	// instructions. Instructions that try to modify more keys than declared
+	// fail with an EffectError and their changes are discarded.
+	StrictEffect bool
+
 	// The average round trip time.
 	//
 	// Default: 10ms.
@@ -57,6 +65,20 @@ type Config struct {
 	// Default: NumCPUs.
 	ConcurrentProposers int
 
+	// The maximum number of in-flight proposals per proposer. Proposers
+	// continue to batch and propose instructions while earlier proposals
+	// are being replicated.
+	//
+	// Default: 4.
+	InflightProposals int
+
+	// The number of instructions executed concurrently during an update.
+	// Only scoped instructions with non-overlapping scopes are executed
+	// concurrently. A value of one disables the concurrent execution.
+	//
+	// Default: 1.
+	ConcurrentUpdaters int
+
 	// The maximum instruction batch sizes.
 	//
 	// Default: 200, 200, 200.
@@ -64,6 +86,12 @@ type Config struct {
 	LookupBatchSize   int
 	ProposalBatchSize int
 
+	// The maximum encoded size of a proposed instruction batch. Larger values
+	// are capped at the maximum raft entry size of 64MB.
+	//
+	// Default: 4MB.
+	ProposalBatchBytes int
+
 	// The time after a proposal times out.
 	//
 	// Default: 10s.
@@ -73,6 +101,39 @@ type Config struct {
 	//
 	// Default: 10s.
 	LinearReadTimeout time.Duration
+
+	// The interval at which the leader sweeps expired keys. A negative value
+	// disables the sweep.
+	//
+	// Default: 1s.
+	SweepInterval time.Duration
+
+	/* Admission Control */
+
+	// The maximum number of instructions waiting in each queue.
+	//
+	// Default: (concurrency + 1) * batch size.
+	QueueSize int
+
+	// FailFast may be set to reject instructions with ErrOverloaded if a queue
+	// or an instruction limit is exhausted instead of waiting for capacity.
+	FailFast bool
+
+	// The maximum number of concurrently executed instructions per name.
+	// Instructions that exceed the limit wait for capacity or are rejected
+	// with ErrOverloaded in fail fast mode.
+	InstructionLimits map[string]int
+
+	/* Expert Configuration */
+
+	// The filesystems used for the raft and database files. If set, they are
+	// used instead of the filesystems selected by the directory.
+	RaftFileSystem     dfs.FS
+	DatabaseFileSystem pfs.FS
+
+	// The factory used to create the raft transport. If unset, the default
+	// TCP transport is used.
+	Transport config.TransportFactory
 }
 
 // Local will return the local member.
@@ -130,6 +191,16 @@ func (c *Config) Validate() error {
 		c.ConcurrentProposers = runtime.NumCPU()
 	}
 
+	// check in-flight proposals
+	if c.InflightProposals == 0 {
+		c.InflightProposals = 4
+	}
+
+	// check concurrent updaters
+	if c.ConcurrentUpdaters == 0 {
+		c.ConcurrentUpdaters = 1
+	}
+
 	// check batch sizes
 	if c.UpdateBatchSize == 0 {
 		c.UpdateBatchSize = 200
@@ -140,6 +211,11 @@ func (c *Config) Validate() error {
 	if c.ProposalBatchSize == 0 {
 		c.ProposalBatchSize = 200
 	}
+	if c.ProposalBatchBytes == 0 {
+		c.ProposalBatchBytes = 4 << 20
+	} else if c.ProposalBatchBytes > maxEntrySize {
+		c.ProposalBatchBytes = maxEntrySize
+	}
 
 	// check timeouts
 	if c.ProposalTimeout == 0 {
@@ -149,9 +225,30 @@ func (c *Config) Validate() error {
 		c.LinearReadTimeout = 10 * time.Second
 	}
 
+	// check instruction limits
+	for name, limit := range c.InstructionLimits {
+		if limit <= 0 {
+			return fmt.Errorf("turing: config validate: invalid instruction limit: %s", name)
+		}
+	}
+
+	// check sweep interval
+	if c.SweepInterval == 0 {
+		c.SweepInterval = time.Second
+	}
+
 	return nil
 }
 
+func (c Config) queueSize(concurrency, batchSize int) int {
+	// use configured if available
+	if c.QueueSize > 0 {
+		return c.QueueSize
+	}
+
+	return (concurrency + 1) * batchSize
+}
+
 // RaftDir returns the directory used for the raft files.
 func (c Config) RaftDir() string {
 	return filepath.Join(c.Directory, "raft")
@@ -159,6 +256,11 @@ func (c Config) RaftDir() string {
 
 // RaftFS returns the filesystem used for the raft files.
 func (c Config) RaftFS() dfs.FS {
+	// use configured if available
+	if c.RaftFileSystem != nil {
+		return c.RaftFileSystem
+	}
+
 	// use in-memory if empty
 	if c.Directory == "" {
 		return dfs.NewMem()
@@ -174,6 +276,11 @@ func (c Config) DatabaseDir() string {
 
 // DatabaseFS returns the filesystem used for the database files.
 func (c Config) DatabaseFS() pfs.FS {
+	// use configured if available
+	if c.DatabaseFileSystem != nil {
+		return c.DatabaseFileSystem
+	}
+
 	// use in-memory if empty
 	if c.Directory == "" {
 		return pfs.NewMem()
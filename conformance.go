@@ -0,0 +1,406 @@
+package turing
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// TestInstruction will check whether the instruction conforms to the
+// instruction contract. The instruction is executed twice on fresh in-memory
+// databases that are prepared using the setup instructions. An error is
+// returned if the instruction does not survive an encode/decode round-trip,
+// modifies more keys than declared by its effect, leaves iterators or closers
+// open or yields different results or database contents across both runs.
+// The setup should let the instruction take its full write path as declaring
+// more effect than the keys actually modified is reported as well.
+func TestInstruction(ins Instruction, setup ...Instruction) error {
+	// collect distinct instructions
+	var list []Instruction
+	names := map[string]bool{}
+	for _, item := range append([]Instruction{ins}, setup...) {
+		name := item.Describe().Name
+		if !names[name] {
+			names[name] = true
+			list = append(list, item)
+		}
+	}
+
+	// prepare config
+	config := Config{
+		Instructions: list,
+		Standalone:   true,
+	}
+
+	// validate config
+	err := config.Validate()
+	if err != nil {
+		return err
+	}
+
+	// build registry
+	registry, err := buildRegistry(config)
+	if err != nil {
+		return err
+	}
+
+	// check input round-trip
+	input, err := roundTrip(registry, ins)
+	if err != nil {
+		return err
+	}
+
+	// get time and seed
+	now := time.Now().UnixNano()
+	seed := rand.Int63()
+
+	// execute twice
+	var results [2][]byte
+	var hashes [2]uint64
+	for i := range results {
+		// decode instruction
+		copied, err := decodeInstruction(registry, ins.Describe().Name, input)
+		if err != nil {
+			return err
+		}
+
+		// run instruction
+		hashes[i], err = testRun(config, registry, copied, setup, now, seed)
+		if err != nil {
+			return err
+		}
+
+		// check output round-trip
+		results[i], err = roundTrip(registry, copied)
+		if err != nil {
+			return err
+		}
+	}
+
+	// compare results
+	if !bytes.Equal(results[0], results[1]) {
+		return fmt.Errorf("turing: test instruction: %s: nondeterministic result", ins.Describe().Name)
+	}
+
+	// compare database contents
+	if hashes[0] != hashes[1] {
+		return fmt.Errorf("turing: test instruction: %s: nondeterministic database contents", ins.Describe().Name)
+	}
+
+	return nil
+}
+
+func testRun(config Config, registry *registry, ins Instruction, setup []Instruction, now, seed int64) (uint64, error) {
+	// open database
+	db, _, err := openDatabase(config, registry, newManager())
+	if err != nil {
+		return 0, err
+	}
+
+	// ensure close
+	defer db.close()
+
+	// copy and apply setup instructions
+	if len(setup) > 0 {
+		list := make([]Instruction, 0, len(setup))
+		for _, item := range setup {
+			input, err := roundTrip(registry, item)
+			if err != nil {
+				return 0, err
+			}
+			copied, err := decodeInstruction(registry, item.Describe().Name, input)
+			if err != nil {
+				return 0, err
+			}
+			list = append(list, copied)
+		}
+		err = db.update(list, nil, 0, now, seed)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// prepare transaction
+	txn := newTransaction()
+	txn.config = db.config
+	txn.registry = db.registry
+	txn.now = now
+	txn.seed = seed
+
+	// ensure recycle
+	defer recycleTransaction(txn)
+
+	// execute read only instructions on a snapshot
+	effect := ins.Effect()
+	if effect == 0 {
+		snapshot := db.pebble.NewSnapshot()
+		defer snapshot.Close()
+		txn.reader = snapshot
+		_, err = txn.execute(ins, newCache())
+		if err != nil {
+			return 0, fmt.Errorf("turing: test instruction: %s: %w", ins.Describe().Name, err)
+		}
+	}
+
+	// execute write instructions using batches
+	for effect != 0 {
+		// prepare batch
+		batch := db.pebble.NewIndexedBatch()
+		txn.reader = batch
+		txn.writer = batch
+		txn.effect = 0
+		txn.writes = 0
+
+		// execute instruction
+		effectMaxed, err := txn.execute(ins, newCache())
+		if err != nil {
+			_ = batch.Close()
+			return 0, fmt.Errorf("turing: test instruction: %s: %w", ins.Describe().Name, err)
+		}
+
+		// check effect
+		if effect > 0 && txn.effect > effect {
+			_ = batch.Close()
+			return 0, fmt.Errorf("turing: test instruction: %s: effect %d exceeds declared effect %d", ins.Describe().Name, txn.effect, effect)
+		}
+
+		// count writes
+		writes := countWrites(batch)
+
+		// check writes against declared effect
+		if effect > 0 && writes > effect {
+			_ = batch.Close()
+			return 0, fmt.Errorf("turing: test instruction: %s: %d writes exceed declared effect %d", ins.Describe().Name, writes, effect)
+		} else if effect > 0 && writes < effect && txn.effect < effect {
+			_ = batch.Close()
+			return 0, fmt.Errorf("turing: test instruction: %s: declared effect %d exceeds %d writes", ins.Describe().Name, effect, writes)
+		}
+
+		// commit batch
+		err = batch.Commit(pebble.NoSync)
+		if err != nil {
+			return 0, err
+		}
+
+		// check if done
+		if !effectMaxed {
+			break
+		}
+
+		// carry over continuation
+		txn.proceed()
+	}
+
+	// compute checksum
+	sum := &checksum{}
+	err = db.update([]Instruction{sum}, nil, 0, now, seed)
+	if err != nil {
+		return 0, err
+	}
+
+	return sum.Hash, nil
+}
+
+// countWrites will count the distinct user keys modified by the batch. Like
+// the effect, a range deletion counts as a single write.
+func countWrites(batch *pebble.Batch) int {
+	// collect keys
+	keys := map[string]bool{}
+	ranges := 0
+	reader := batch.Reader()
+	for {
+		// get next record
+		kind, key, _, ok := reader.Next()
+		if !ok {
+			break
+		}
+
+		// skip internal keys
+		if !bytes.HasPrefix(key, userPrefix) {
+			continue
+		}
+
+		// count range or add key
+		if kind == pebble.InternalKeyKindRangeDelete {
+			ranges++
+		} else {
+			keys[string(key)] = true
+		}
+	}
+
+	return len(keys) + ranges
+}
+
+func roundTrip(registry *registry, ins Instruction) ([]byte, error) {
+	// encode instruction
+	encoded, ref, err := ins.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	// copy and release
+	encoded = Clone(encoded)
+	if ref != nil {
+		ref.Release()
+	}
+
+	// decode instruction
+	decoded, err := decodeInstruction(registry, ins.Describe().Name, encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	// encode again
+	reencoded, ref, err := decoded.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	// release
+	if ref != nil {
+		defer ref.Release()
+	}
+
+	// compare
+	if !bytes.Equal(encoded, reencoded) {
+		return nil, fmt.Errorf("turing: test instruction: %s: encode/decode round-trip mismatch", ins.Describe().Name)
+	}
+
+	return encoded, nil
+}
+
+func decodeInstruction(registry *registry, name string, encoded []byte) (Instruction, error) {
+	// build instruction
+	ins, err := registry.build(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// decode instruction
+	err = ins.Decode(Clone(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("turing: test instruction: %s: %w", name, err)
+	}
+
+	return ins, nil
+}
+
+// TestOperator will check whether the operator yields the same value when
+// operands are combined before being applied as when they are applied one
+// by one. The generator is called with a seeded source to produce random
+// operands.
+func TestOperator(op *Operator, gen func(r *rand.Rand) []byte) error {
+	// prepare random
+	r := rand.New(rand.NewSource(1))
+
+	// run rounds
+	for round := 0; round < 100; round++ {
+		// generate operands
+		ops := make([][]byte, 1+r.Intn(8))
+		for i := range ops {
+			ops[i] = gen(r)
+		}
+
+		// apply sequentially
+		expected := op.Zero
+		for _, operand := range ops {
+			value, err := testApply(op, expected, [][]byte{operand})
+			if err != nil {
+				return err
+			}
+			expected = value
+		}
+
+		// apply at once
+		actual, err := testApply(op, op.Zero, ops)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(expected, actual) {
+			return fmt.Errorf("turing: test operator: %s: apply mismatch for operands %x", op.Name, ops)
+		}
+
+		// skip if combine is not available
+		if op.Combine == nil {
+			continue
+		}
+
+		// combine all operands
+		combined, err := testCombine(op, ops)
+		if err != nil {
+			return err
+		}
+
+		// apply combined operand
+		actual, err = testApply(op, op.Zero, [][]byte{combined})
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(expected, actual) {
+			return fmt.Errorf("turing: test operator: %s: combine mismatch for operands %x", op.Name, ops)
+		}
+
+		// combine split operands and then the combined results
+		split := r.Intn(len(ops) + 1)
+		var partial [][]byte
+		for _, part := range [][][]byte{ops[:split], ops[split:]} {
+			if len(part) > 0 {
+				combined, err := testCombine(op, part)
+				if err != nil {
+					return err
+				}
+				partial = append(partial, combined)
+			}
+		}
+		combined, err = testCombine(op, partial)
+		if err != nil {
+			return err
+		}
+
+		// apply combined operand
+		actual, err = testApply(op, op.Zero, [][]byte{combined})
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(expected, actual) {
+			return fmt.Errorf("turing: test operator: %s: partial combine mismatch for operands %x", op.Name, ops)
+		}
+	}
+
+	return nil
+}
+
+func testApply(op *Operator, value []byte, ops [][]byte) ([]byte, error) {
+	// apply operands
+	result, ref, err := op.Apply(value, ops)
+	if err != nil {
+		return nil, fmt.Errorf("turing: test operator: %s: %w", op.Name, err)
+	}
+
+	// copy and release
+	result = Clone(result)
+	if ref != nil {
+		ref.Release()
+	}
+
+	return result, nil
+}
+
+func testCombine(op *Operator, ops [][]byte) ([]byte, error) {
+	// combine operands
+	result, ref, err := op.Combine(ops)
+	if err != nil {
+		return nil, fmt.Errorf("turing: test operator: %s: %w", op.Name, err)
+	}
+
+	// copy and release
+	result = Clone(result)
+	if ref != nil {
+		ref.Release()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,191 @@
+package turing
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/stretchr/testify/assert"
+)
+
+type faulty struct {
+	Mode  string
+	Value int64
+}
+
+var faultyDesc = &Description{
+	Name: "faulty",
+}
+
+func (f *faulty) Describe() *Description {
+	return faultyDesc
+}
+
+func (f *faulty) Effect() int {
+	if f.Mode == "read" {
+		return 0
+	}
+
+	return 1
+}
+
+func (f *faulty) Execute(mem Memory, _ Cache) error {
+	switch f.Mode {
+	case "effect":
+		_ = mem.Set([]byte("a"), []byte("1"))
+		return mem.Set([]byte("b"), []byte("2"))
+	case "iterator":
+		mem.Iterate(nil)
+	case "closer":
+		_, _, _, err := mem.Get([]byte("a"))
+		return err
+	case "random":
+		f.Value = rand.Int63()
+		return mem.Set([]byte("a"), []byte("1"))
+	case "seeded":
+		f.Value = mem.Rand().Int63()
+		return mem.Set([]byte("a"), []byte(time.Unix(0, f.Value).String()))
+	case "read":
+		return mem.Set([]byte("a"), []byte("1"))
+	case "raw":
+		txn := mem.(*transaction)
+		_ = txn.writer.Set(append(Clone(userPrefix), "a"...), nil, nil)
+		return txn.writer.Set(append(Clone(userPrefix), "b"...), nil, nil)
+	}
+
+	return nil
+}
+
+func (f *faulty) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		enc.VarString(f.Mode)
+		enc.VarInt(f.Value)
+		return nil
+	})
+}
+
+func (f *faulty) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		f.Mode = dec.VarString(true)
+		f.Value = dec.VarInt()
+		return nil
+	})
+}
+
+func TestTestInstruction(t *testing.T) {
+	err := TestInstruction(&expiringSet{Key: []byte("foo"), Value: []byte("bar"), TTL: time.Hour})
+	assert.NoError(t, err)
+
+	err = TestInstruction(&panicker{Mode: "get", Key: []byte("foo")}, &panicker{
+		Mode: "merge", Key: []byte("foo"), Value: []byte("a"),
+	})
+	assert.NoError(t, err)
+
+	err = TestInstruction(&faulty{Mode: "seeded"})
+	assert.NoError(t, err)
+
+	for mode, msg := range map[string]string{
+		"effect":   "effect 2 exceeds declared effect 1",
+		"iterator": "unclosed iterators",
+		"closer":   "unclosed closers",
+		"random":   "nondeterministic result",
+		"read":     "read only",
+		"idle":     "declared effect 1 exceeds 0 writes",
+	} {
+		err = TestInstruction(&faulty{Mode: mode, Value: 7}, &expiringSet{
+			Key: []byte("a"), Value: []byte("0"), TTL: time.Hour,
+		})
+		assert.Error(t, err, mode)
+		if err != nil {
+			assert.True(t, strings.Contains(err.Error(), msg), err.Error())
+		}
+	}
+
+	err = TestInstruction(&faulty{Mode: "raw"})
+	assert.Error(t, err)
+	if err != nil {
+		assert.True(t, strings.Contains(err.Error(), "2 writes exceed declared effect 1"), err.Error())
+	}
+
+	err = TestInstruction(&panicker{Mode: "panic", Key: []byte("foo")})
+	assert.Equal(t, "turing: test instruction: panicker: turing: panic in panicker: boom", err.Error())
+}
+
+func TestTestInstructionRoundTrip(t *testing.T) {
+	err := TestInstruction(&randomizer{})
+	assert.NoError(t, err)
+
+	err = TestInstruction(&lossy{Value: 7})
+	assert.Equal(t, "turing: test instruction: lossy: encode/decode round-trip mismatch", err.Error())
+}
+
+type lossy struct {
+	Value int64
+}
+
+var lossyDesc = &Description{
+	Name: "lossy",
+}
+
+func (l *lossy) Describe() *Description {
+	return lossyDesc
+}
+
+func (l *lossy) Effect() int {
+	return 0
+}
+
+func (l *lossy) Execute(Memory, Cache) error {
+	return nil
+}
+
+func (l *lossy) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		enc.VarInt(l.Value)
+		return nil
+	})
+}
+
+func (l *lossy) Decode([]byte) error {
+	return nil
+}
+
+var concat = &Operator{
+	Name: "concat",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, Ref, error) {
+		value = Clone(value)
+		for _, op := range ops {
+			value = append(value, op...)
+		}
+		return value, nil, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, Ref, error) {
+		var value []byte
+		for _, op := range ops {
+			value = append(value, op...)
+		}
+		return value, nil, nil
+	},
+}
+
+func TestTestOperator(t *testing.T) {
+	gen := func(r *rand.Rand) []byte {
+		return []byte{byte(r.Intn(256))}
+	}
+
+	err := TestOperator(concat, gen)
+	assert.NoError(t, err)
+
+	broken := *concat
+	broken.Name = "broken"
+	broken.Combine = func(ops [][]byte) ([]byte, Ref, error) {
+		return ops[len(ops)-1], nil, nil
+	}
+
+	err = TestOperator(&broken, gen)
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "turing: test operator: broken: combine mismatch"), err.Error())
+}
@@ -0,0 +1,105 @@
+package turing
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/cockroachdb/pebble"
+)
+
+var continuationKey = []byte("$continuation")
+
+// continuation is the persisted continuation of an unbounded instruction
+// that has been partially applied. It is stored with the batch that
+// committed the partial changes and removed once the instruction finished.
+type continuation struct {
+	Index    uint64
+	Position uint16
+	State    []byte
+}
+
+func (c *continuation) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Uint64(c.Index)
+		enc.Uint16(c.Position)
+		enc.Tail(c.State)
+
+		return nil
+	})
+}
+
+func (c *continuation) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("turing: decode continuation: invalid version")
+		}
+
+		// decode body
+		c.Index = dec.Uint64()
+		c.Position = dec.Uint16()
+		c.State = dec.Tail(true)
+		if len(c.State) == 0 {
+			c.State = nil
+		}
+
+		return nil
+	})
+}
+
+func loadContinuation(reader pebble.Reader, index uint64, position uint16) ([]byte, error) {
+	// get value
+	value, closer, err := reader.Get(continuationKey)
+	if err == pebble.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// ensure close
+	defer closer.Close()
+
+	// decode continuation
+	var cont continuation
+	err = cont.Decode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	// check instruction
+	if cont.Index != index || cont.Position != position {
+		return nil, nil
+	}
+
+	return cont.State, nil
+}
+
+func storeContinuation(writer pebble.Writer, index uint64, position uint16, state []byte) error {
+	// prepare continuation
+	cont := continuation{
+		Index:    index,
+		Position: position,
+		State:    state,
+	}
+
+	// encode continuation
+	value, ref, err := cont.Encode()
+	if err != nil {
+		return err
+	}
+
+	// ensure release
+	defer ref.Release()
+
+	// set continuation
+	err = writer.Set(continuationKey, value, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package turing
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing/tape"
+)
+
+type migrator struct {
+	Fail    int
+	Count   int
+	Resumed []byte
+	runs    int
+}
+
+var migratorDesc = &Description{
+	Name: "migrator",
+}
+
+func (m *migrator) Describe() *Description {
+	return migratorDesc
+}
+
+func (m *migrator) Effect() int {
+	return UnboundedEffect
+}
+
+func (m *migrator) Execute(mem Memory, _ Cache) error {
+	// fail if requested
+	m.runs++
+	if m.Fail > 0 && m.runs >= m.Fail {
+		return errors.New("crash")
+	}
+
+	// get continuation
+	resume := mem.Continuation()
+	if m.runs == 1 {
+		m.Resumed = resume
+	}
+
+	// collect keys
+	var keys [][]byte
+	iter := mem.Iterate([]byte("k"))
+	for ok := iter.SeekGE(resume); ok; ok = iter.Next() {
+		keys = append(keys, Clone(iter.TempKey()))
+	}
+	err := iter.Close()
+	if err != nil {
+		return err
+	}
+
+	// rewrite keys
+	for _, key := range keys {
+		err = mem.Set(key, []byte("new"))
+		if err == ErrMaxEffect {
+			_ = mem.Continue(key)
+			return err
+		} else if err != nil {
+			return err
+		}
+		m.Count++
+	}
+
+	return nil
+}
+
+func (m *migrator) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		enc.VarInt(int64(m.Fail))
+		enc.VarInt(int64(m.Count))
+		enc.VarBytes(m.Resumed)
+		return nil
+	})
+}
+
+func (m *migrator) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		m.Fail = int(dec.VarInt())
+		m.Count = int(dec.VarInt())
+		m.Resumed = dec.VarBytes(true)
+		return nil
+	})
+}
+
+func openMigratorDatabase(t *testing.T) *database {
+	config := Config{
+		Instructions: []Instruction{&migrator{}, &expiringSet{}},
+		Standalone:   true,
+		MaxEffect:    5,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	var list []Instruction
+	for i := 0; i < 12; i++ {
+		list = append(list, &expiringSet{
+			Key:   []byte(fmt.Sprintf("k%02d", i)),
+			Value: []byte("old"),
+			TTL:   time.Hour,
+		})
+	}
+	err = db.update(list, nil, 1, time.Now().UnixNano(), 0)
+	assert.NoError(t, err)
+
+	return db
+}
+
+func countValues(db *database, value string) int {
+	iter := db.pebble.NewIter(prefixIterator(userPrefix))
+	defer iter.Close()
+
+	var count int
+	for iter.First(); iter.Valid(); iter.Next() {
+		var cell tape.Cell
+		if cell.Decode(iter.Value(), false) == nil && string(cell.Value) == value {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestContinuation(t *testing.T) {
+	db := openMigratorDatabase(t)
+
+	ins := &migrator{}
+	err := db.update([]Instruction{ins}, nil, 2, time.Now().UnixNano(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, ins.Count)
+	assert.Nil(t, ins.Resumed)
+	assert.Equal(t, 12, countValues(db, "new"))
+	assert.Equal(t, 0, countKeys(db, continuationKey))
+
+	assert.NoError(t, db.close())
+}
+
+func TestContinuationCrash(t *testing.T) {
+	db := openMigratorDatabase(t)
+
+	ins := &migrator{Fail: 2}
+	err := db.update([]Instruction{ins}, nil, 2, time.Now().UnixNano(), 0)
+	assert.Error(t, err)
+	assert.Equal(t, 5, ins.Count)
+	assert.Equal(t, 5, countValues(db, "new"))
+	assert.Equal(t, 1, countKeys(db, continuationKey))
+
+	ins = &migrator{}
+	err = db.update([]Instruction{ins}, nil, 2, time.Now().UnixNano(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, ins.Count)
+	assert.Equal(t, []byte("k05"), ins.Resumed)
+	assert.Equal(t, 12, countValues(db, "new"))
+	assert.Equal(t, 0, countKeys(db, continuationKey))
+
+	assert.NoError(t, db.close())
+}
+
+func TestContinuationStandalone(t *testing.T) {
+	db := openMigratorDatabase(t)
+
+	ins := &migrator{}
+	err := db.update([]Instruction{ins}, nil, 0, time.Now().UnixNano(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, ins.Count)
+	assert.Equal(t, 0, countKeys(db, continuationKey))
+
+	assert.NoError(t, db.close())
+}
+
+func TestContinuationReadOnly(t *testing.T) {
+	db := openMigratorDatabase(t)
+
+	txn := newTransaction()
+	txn.reader = db.pebble
+	err := txn.Continue([]byte("foo"))
+	assert.Equal(t, ErrReadOnly, err)
+	assert.Nil(t, txn.Continuation())
+	recycleTransaction(txn)
+
+	assert.NoError(t, db.close())
+}
+
+func TestContinuationEncoding(t *testing.T) {
+	cont1 := &continuation{Index: 7, Position: 3, State: []byte("foo")}
+	bytes, _, err := cont1.Encode()
+	assert.NoError(t, err)
+
+	var cont2 continuation
+	err = cont2.Decode(bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, *cont1, cont2)
+
+	db, _, err := openDatabase(Config{}, nil, newManager())
+	assert.NoError(t, err)
+
+	err = storeContinuation(db.pebble, 7, 3, []byte("foo"))
+	assert.NoError(t, err)
+
+	state, err := loadContinuation(db.pebble, 7, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("foo"), state)
+
+	state, err = loadContinuation(db.pebble, 7, 4)
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+
+	assert.NoError(t, db.close())
+}
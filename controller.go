@@ -1,5 +1,11 @@
 package turing
 
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
 type controller struct {
 	database *database
 	updates  *bundler
@@ -18,19 +24,23 @@ func createController(config Config, registry *registry, manager *manager) (*con
 	return &controller{
 		database: database,
 		updates: newBundler(bundlerOptions{
-			queueSize:   2 * config.UpdateBatchSize,
+			name:        "updates",
+			queueSize:   config.queueSize(1, config.UpdateBatchSize),
+			failFast:    config.FailFast,
 			batchSize:   config.UpdateBatchSize,
 			concurrency: 1, // database anyway only allows one writer
-			handler: func(list []Instruction) error {
-				return database.update(list, 0)
+			handler: func(list []Instruction, errs []error) error {
+				return database.update(list, errs, 0, time.Now().UnixNano(), rand.Int63())
 			},
 		}),
 		lookups: newBundler(bundlerOptions{
-			queueSize:   (config.ConcurrentReaders + 1) * config.LookupBatchSize,
+			name:        "lookups",
+			queueSize:   config.queueSize(config.ConcurrentReaders, config.LookupBatchSize),
+			failFast:    config.FailFast,
 			batchSize:   config.LookupBatchSize,
 			concurrency: config.ConcurrentReaders,
-			handler: func(list []Instruction) error {
-				return database.lookup(list)
+			handler: func(list []Instruction, errs []error) error {
+				return database.lookup(list, errs)
 			},
 		}),
 	}, nil
@@ -44,10 +54,14 @@ func (c *controller) lookup(ins Instruction, fn func(error)) error {
 	return c.lookups.process(ins, fn)
 }
 
-func (c *controller) close() error {
-	// close bundlers
-	c.updates.close()
-	c.lookups.close()
+func (c *controller) close(ctx context.Context) error {
+	// drain bundlers
+	err1 := c.updates.stop(ctx)
+	err2 := c.lookups.stop(ctx)
+
+	// await bundlers
+	c.updates.await()
+	c.lookups.await()
 
 	// close database
 	err := c.database.close()
@@ -55,5 +69,10 @@ func (c *controller) close() error {
 		return err
 	}
 
-	return nil
+	// check drain errors
+	if err1 != nil {
+		return err1
+	}
+
+	return err2
 }
@@ -2,6 +2,8 @@ package turing
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
 	"net"
 	"strconv"
 	"time"
@@ -16,6 +18,12 @@ import (
 
 const clusterID uint64 = 1
 
+// maxEntrySize is the maximum size of a raft entry accepted by dragonboat.
+const maxEntrySize = 64 << 20
+
+// commandOverhead is the estimated encoding overhead of a command.
+const commandOverhead = 64
+
 type coordinator struct {
 	config      Config
 	node        *dragonboat.NodeHost
@@ -23,7 +31,7 @@ type coordinator struct {
 	linearReads *bundler
 	writes      *bundler
 	session     *client.Session
-	operations  []wire.Operation
+	operations  [][]wire.Operation
 }
 
 func createCoordinator(cfg Config, registry *registry, manager *manager) (*coordinator, error) {
@@ -57,7 +65,8 @@ func createCoordinator(cfg Config, registry *registry, manager *manager) (*coord
 		RTTMillisecond: rttMS,
 		RaftAddress:    cfg.Local().Address(),
 		Expert: config.ExpertConfig{
-			FS: cfg.RaftFS(),
+			FS:               cfg.RaftFS(),
+			TransportFactory: cfg.Transport,
 		},
 	}
 
@@ -83,12 +92,19 @@ func createCoordinator(cfg Config, registry *registry, manager *manager) (*coord
 		config:     cfg,
 		node:       node,
 		session:    node.GetNoOPSession(clusterID),
-		operations: make([]wire.Operation, cfg.ProposalBatchSize),
+		operations: make([][]wire.Operation, cfg.ConcurrentProposers),
+	}
+
+	// prepare operation buffers
+	for i := range coordinator.operations {
+		coordinator.operations[i] = make([]wire.Operation, 0, cfg.ProposalBatchSize)
 	}
 
 	// create stale read bundler
 	coordinator.staleReads = newBundler(bundlerOptions{
-		queueSize:   (cfg.ConcurrentReaders + 1) * cfg.LookupBatchSize,
+		name:        "staleReads",
+		queueSize:   cfg.queueSize(cfg.ConcurrentReaders, cfg.LookupBatchSize),
+		failFast:    cfg.FailFast,
 		batchSize:   cfg.LookupBatchSize,
 		concurrency: cfg.ConcurrentReaders,
 		handler:     coordinator.performStaleLookup,
@@ -96,7 +112,9 @@ func createCoordinator(cfg Config, registry *registry, manager *manager) (*coord
 
 	// create liner read bundler
 	coordinator.linearReads = newBundler(bundlerOptions{
-		queueSize:   (cfg.ConcurrentReaders + 1) * cfg.LookupBatchSize,
+		name:        "linearReads",
+		queueSize:   cfg.queueSize(cfg.ConcurrentReaders, cfg.LookupBatchSize),
+		failFast:    cfg.FailFast,
 		batchSize:   cfg.LookupBatchSize,
 		concurrency: cfg.ConcurrentReaders,
 		handler:     coordinator.performLinearLookup,
@@ -104,10 +122,15 @@ func createCoordinator(cfg Config, registry *registry, manager *manager) (*coord
 
 	// create write bundler
 	coordinator.writes = newBundler(bundlerOptions{
-		queueSize:   (cfg.ConcurrentProposers + 1) * cfg.ProposalBatchSize,
+		name:        "writes",
+		queueSize:   cfg.queueSize(cfg.ConcurrentProposers*cfg.InflightProposals, cfg.ProposalBatchSize),
+		failFast:    cfg.FailFast,
 		batchSize:   cfg.ProposalBatchSize,
 		concurrency: cfg.ConcurrentProposers,
-		handler:     coordinator.performUpdates,
+		pipeline:    cfg.InflightProposals,
+		encode:      true,
+		maxSize:     cfg.ProposalBatchBytes - commandOverhead,
+		async:       coordinator.performUpdates,
 	})
 
 	return coordinator, nil
@@ -131,59 +154,105 @@ func (c *coordinator) update(ins Instruction, fn func(error)) error {
 
 var coordinatorPerformUpdates = systemMetrics.WithLabelValues("coordinator.performUpdates")
 
-func (c *coordinator) performUpdates(list []Instruction) error {
+func (c *coordinator) performUpdates(worker int, list []Instruction, codes [][]byte, errs []error, done func(error)) {
 	// observe
 	timer := observe(coordinatorPerformUpdates)
-	defer timer.finish()
 
 	// TODO: Clarify the handling of proposal failures, retries and idempotency.
 
-	// prepare command
-	cmd := wire.Command{
-		Operations: c.operations[:0],
+	// encode command
+	encodedCommand, ref, err := c.encodeCommand(worker, list, codes)
+	if err != nil {
+		timer.finish()
+		done(err)
+		return
 	}
 
-	// add operations
-	for _, ins := range list {
-		// encode instructions
-		encodedInstruction, ref, err := ins.Encode()
-		if err != nil {
-			return err
-		}
+	// propose
+	rs, err := c.node.Propose(c.session, encodedCommand, c.config.ProposalTimeout)
+	if err != nil {
+		ref.Release()
+		timer.finish()
+		done(err)
+		return
+	}
 
-		// ensure release
-		if ref != nil {
-			defer ref.Release()
-		}
+	// await result
+	go func() {
+		// await application
+		result := <-rs.AppliedC()
+
+		// release command
+		ref.Release()
+
+		// handle result
+		err := c.handleResult(rs, result, list, errs)
+		timer.finish()
+		done(err)
+	}()
+}
+
+func (c *coordinator) encodeCommand(worker int, list []Instruction, codes [][]byte) ([]byte, Ref, error) {
+	// prepare command
+	cmd := wire.Command{
+		Time:       time.Now().UnixNano(),
+		Seed:       rand.Int63(),
+		Operations: c.operations[worker][:0],
+	}
 
-		// add operation
+	// add operations using the instructions encoded by the bundler
+	for i, ins := range list {
 		cmd.Operations = append(cmd.Operations, wire.Operation{
 			Name: ins.Describe().Name,
-			Code: encodedInstruction,
+			Code: codes[i],
 		})
 	}
 
 	// encode command
 	encodedCommand, ref, err := cmd.Encode(true)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	// release
-	defer ref.Release()
-
-	// prepare context
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.ProposalTimeout)
-	defer cancel()
+	return encodedCommand, ref, nil
+}
 
-	// propose
-	result, err := c.node.SyncPropose(ctx, c.session, encodedCommand)
-	if err != nil {
-		return err
+func (c *coordinator) handleResult(rs *dragonboat.RequestState, result dragonboat.RequestResult, list []Instruction, errs []error) error {
+	// release request state
+	defer rs.Release()
+
+	// check result
+	if result.Rejected() {
+		return dragonboat.ErrRejected
+	} else if result.Timeout() {
+		return dragonboat.ErrTimeout
+	} else if result.Terminated() {
+		return dragonboat.ErrClusterClosed
+	} else if result.Dropped() {
+		return dragonboat.ErrClusterNotReady
+	} else if !result.Completed() {
+		return fmt.Errorf("turing: coordinator: proposal not completed")
 	}
 
+	// get data
+	data := result.GetResult().Data
+
 	// walk command and decode results
-	err = wire.WalkCommand(result.Data, func(i int, op wire.Operation) (bool, error) {
+	err := wire.WalkCommand(data, func(i int, op wire.Operation) (bool, error) {
+		// decode panic error
+		if op.Name == panicOperation {
+			perr := &PanicError{}
+			errs[i] = perr
+			return true, perr.Decode(op.Code)
+		}
+
+		// decode effect error
+		if op.Name == effectOperation {
+			eerr := &EffectError{}
+			errs[i] = eerr
+			return true, eerr.Decode(op.Code)
+		}
+
 		// decode result if available
 		if len(op.Code) > 0 {
 			return true, list[i].Decode(op.Code)
@@ -215,13 +284,13 @@ func (c *coordinator) lookup(ins Instruction, fn func(error), options Options) e
 
 var coordinatorPerformStaleLookup = systemMetrics.WithLabelValues("coordinator.performStaleLookup")
 
-func (c *coordinator) performStaleLookup(list []Instruction) error {
+func (c *coordinator) performStaleLookup(list []Instruction, errs []error) error {
 	// observe
 	timer := observe(coordinatorPerformStaleLookup)
 	defer timer.finish()
 
 	// perform stale read
-	_, err := c.node.StaleRead(clusterID, list)
+	_, err := c.node.StaleRead(clusterID, lookupRequest{list: list, errs: errs})
 	if err != nil {
 		return err
 	}
@@ -231,7 +300,7 @@ func (c *coordinator) performStaleLookup(list []Instruction) error {
 
 var coordinatorPerformLinearLookup = systemMetrics.WithLabelValues("coordinator.performLinearLookup")
 
-func (c *coordinator) performLinearLookup(list []Instruction) error {
+func (c *coordinator) performLinearLookup(list []Instruction, errs []error) error {
 	// observe
 	timer := observe(coordinatorPerformLinearLookup)
 	defer timer.finish()
@@ -241,7 +310,7 @@ func (c *coordinator) performLinearLookup(list []Instruction) error {
 	defer cancel()
 
 	// perform linear read
-	_, err := c.node.SyncRead(ctx, clusterID, list)
+	_, err := c.node.SyncRead(ctx, clusterID, lookupRequest{list: list, errs: errs})
 	if err != nil {
 		return err
 	}
@@ -319,7 +388,73 @@ func (c *coordinator) status() Status {
 	return status
 }
 
-func (c *coordinator) close() {
+func (c *coordinator) leader() bool {
+	// get leader
+	id, ok, _ := c.node.GetLeaderID(clusterID)
+
+	return ok && id == c.config.ID
+}
+
+func (c *coordinator) close(ctx context.Context, graceful bool) error {
+	// drain bundlers
+	var err error
+	for _, b := range []*bundler{c.writes, c.linearReads, c.staleReads} {
+		if e := b.stop(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	// transfer leadership if graceful
+	if graceful {
+		if e := c.transfer(ctx); e != nil && err == nil {
+			err = e
+		}
+	}
+
 	// stop node
 	c.node.Stop()
+
+	// await bundlers
+	c.writes.await()
+	c.linearReads.await()
+	c.staleReads.await()
+
+	return err
+}
+
+func (c *coordinator) transfer(ctx context.Context) error {
+	// skip if not leader
+	if !c.leader() {
+		return nil
+	}
+
+	// select member with lowest id as target
+	var target uint64
+	for _, member := range c.status().Members {
+		if member.ID != c.config.ID && (target == 0 || member.ID < target) {
+			target = member.ID
+		}
+	}
+
+	// skip if there is no other member
+	if target == 0 {
+		return nil
+	}
+
+	// request transfer
+	err := c.node.RequestLeaderTransfer(clusterID, target)
+	if err != nil {
+		return err
+	}
+
+	// await transfer
+	for c.leader() {
+		select {
+		case <-time.After(c.config.RoundTripTime):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
 }
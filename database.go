@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -146,7 +147,7 @@ func openDatabase(config Config, registry *registry, manager *manager) (*databas
 
 var databaseUpdate = systemMetrics.WithLabelValues("database.update")
 
-func (d *database) update(list []Instruction, index uint64) error {
+func (d *database) update(list []Instruction, errs []error, index uint64, now, seed int64) error {
 	// acquire write mutex
 	d.write.Lock()
 	defer d.write.Unlock()
@@ -177,23 +178,50 @@ func (d *database) update(list []Instruction, index uint64) error {
 	txn.registry = d.registry
 	txn.reader = batch
 	txn.writer = batch
+	txn.index = index
+	txn.now = now
 
 	// ensure recycle
 	defer recycleTransaction(txn)
 
 	// execute all instructions
-	for i, ins := range list {
+	for i := 0; i < len(list); i++ {
+		// get instruction
+		ins := list[i]
+
 		// skip instruction if already applied
 		if index != 0 && d.state.Batch == index && d.state.Last >= uint16(i) {
 			continue
 		}
 
+		// execute non-conflicting scoped instructions concurrently
+		if n := d.collect(list[i:]); n > 1 {
+			var applied int
+			var err error
+			batch, applied, err = d.parallel(txn, batch, list[i:i+n], errs, i, seed)
+			if err != nil {
+				return err
+			}
+
+			// reset cache and continue with the first instruction that has
+			// not been applied
+			if applied > 0 {
+				cache = newCache()
+				i += applied - 1
+				continue
+			}
+		}
+
 		// begin observation
 		timer := observe(ins.Describe().observer)
 
-		// check if new transaction is needed for bounded transaction
+		// derive instruction seed
+		txn.seed = seed + int64(i)
+
+		// check if new transaction is needed for bounded transaction, each
+		// modification may require up to two writes
 		effect := ins.Effect()
-		if effect > 0 && txn.effect+effect >= d.config.MaxEffect {
+		if effect > 0 && txn.writes+2*effect >= d.config.MaxEffect {
 			// commit current batch
 			err := batch.Commit(pebble.NoSync)
 			if err != nil {
@@ -207,17 +235,88 @@ func (d *database) update(list []Instruction, index uint64) error {
 			txn.reader = batch
 			txn.writer = batch
 			txn.effect = 0
+			txn.writes = 0
+		}
+
+		// load continuation of a partially applied unbounded instruction
+		txn.resume = nil
+		if effect < 0 && index != 0 {
+			resume, err := loadContinuation(batch, index, uint16(i))
+			if err != nil {
+				return err
+			}
+			txn.resume = resume
 		}
 
+		// track persisted continuation
+		persisted := txn.resume != nil
+
 		for {
+			// commit the changes of previous instructions if the changes of
+			// this instruction may be discarded
+			if errs != nil && !batch.Empty() {
+				// commit current batch
+				err := batch.Commit(pebble.NoSync)
+				if err != nil {
+					return err
+				}
+
+				// create new batch
+				batch = d.pebble.NewIndexedBatch()
+
+				// reset transaction
+				txn.reader = batch
+				txn.writer = batch
+				txn.effect = 0
+				txn.writes = 0
+			}
+
 			// execute transaction
 			effectMaxed, err := txn.execute(ins, cache)
-			if err != nil {
+			if rejected(err) && errs != nil {
+				// set error
+				errs[i] = err
+
+				// discard changes of instruction
+				err = batch.Close()
+				if err != nil {
+					return err
+				}
+
+				// create new batch
+				batch = d.pebble.NewIndexedBatch()
+
+				// reset transaction and cache
+				txn.reader = batch
+				txn.writer = batch
+				txn.effect = 0
+				txn.writes = 0
+				txn.closers = 0
+				txn.iterators = 0
+				cache = newCache()
+			} else if err != nil {
 				return err
 			}
 
 			// commit batch if effect is maxed and start over
 			if effectMaxed {
+				// carry over continuation
+				txn.proceed()
+
+				// persist continuation of unbounded instruction if recorded
+				if effect < 0 && index != 0 && txn.continued {
+					if txn.recorded != nil {
+						err = storeContinuation(batch, index, uint16(i), txn.recorded)
+						persisted = true
+					} else if persisted {
+						err = batch.Delete(continuationKey, nil)
+						persisted = false
+					}
+					if err != nil {
+						return err
+					}
+				}
+
 				// commit current batch
 				err := batch.Commit(pebble.NoSync)
 				if err != nil {
@@ -231,10 +330,19 @@ func (d *database) update(list []Instruction, index uint64) error {
 				txn.reader = batch
 				txn.writer = batch
 				txn.effect = 0
+				txn.writes = 0
 
 				continue
 			}
 
+			// remove persisted continuation
+			if persisted {
+				err = batch.Delete(continuationKey, nil)
+				if err != nil {
+					return err
+				}
+			}
+
 			// update state
 			d.state.Batch = index
 			d.state.Last = uint16(i)
@@ -295,8 +403,10 @@ func (d *database) update(list []Instruction, index uint64) error {
 	}
 
 	// yield to manager
-	for _, instruction := range list {
-		d.manager.process(instruction)
+	for i, instruction := range list {
+		if errs == nil || errs[i] == nil {
+			d.manager.process(instruction)
+		}
 	}
 
 	return nil
@@ -304,7 +414,7 @@ func (d *database) update(list []Instruction, index uint64) error {
 
 var databaseLookup = systemMetrics.WithLabelValues("database.lookup")
 
-func (d *database) lookup(list []Instruction) error {
+func (d *database) lookup(list []Instruction, errs []error) error {
 	// acquire reader token
 	<-d.readers
 	defer func() {
@@ -336,18 +446,27 @@ func (d *database) lookup(list []Instruction) error {
 	txn.config = d.config
 	txn.registry = d.registry
 	txn.reader = snapshot
+	txn.now = time.Now().UnixNano()
+	txn.seed = rand.Int63()
 
 	// ensure recycle
 	defer recycleTransaction(txn)
 
 	// execute instructions
-	for _, ins := range list {
+	for i, ins := range list {
 		// begin observation
 		timer := observe(ins.Describe().observer)
 
 		// execute transaction
 		_, err := txn.execute(ins, cache)
-		if err != nil {
+		if perr, ok := err.(*PanicError); ok && errs != nil {
+			// reset transaction
+			txn.closers = 0
+			txn.iterators = 0
+
+			// set error
+			errs[i] = perr
+		} else if err != nil {
 			return err
 		}
 
@@ -473,6 +592,13 @@ func (d *database) restore(source io.Reader) error {
 
 	// TODO: Delete all current data?
 
+	// read backup
+	return readBackup(source, func(key, value []byte) error {
+		return d.pebble.Set(key, value, pebble.NoSync)
+	})
+}
+
+func readBackup(source io.Reader, fn func(key, value []byte) error) error {
 	// prepare buffers
 	lenBuf := make([]byte, 8)
 	keyBuf := make([]byte, 1<<14) // ~16KB
@@ -522,8 +648,8 @@ func (d *database) restore(source io.Reader) error {
 			return err
 		}
 
-		// set key
-		err = d.pebble.Set(keyBuf[:keyLen], valBuf[:valLen], pebble.NoSync)
+		// yield pair
+		err = fn(keyBuf[:keyLen], valBuf[:valLen])
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,68 @@
+package turing
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+)
+
+// EffectError is returned in strict effect mode for instructions that tried
+// to modify more keys than declared by their effect. The changes of the
+// instruction are discarded and the error is reported consistently on all
+// replicas.
+type EffectError struct {
+	// The name of the instruction.
+	Name string
+
+	// The declared effect.
+	Declared int
+}
+
+// Error implements the error interface.
+func (e *EffectError) Error() string {
+	return fmt.Sprintf("turing: effect exceeded by %s: declared %d", e.Name, e.Declared)
+}
+
+// Encode will encode the error.
+func (e *EffectError) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarString(e.Name)
+		enc.VarInt(int64(e.Declared))
+
+		return nil
+	})
+}
+
+// Decode will decode the error.
+func (e *EffectError) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("turing: decode effect error: invalid version")
+		}
+
+		// decode body
+		e.Name = dec.VarString(true)
+		e.Declared = int(dec.VarInt())
+
+		return nil
+	})
+}
+
+// effectOperation is the operation name used to return effect errors.
+const effectOperation = "turing/EffectExceeded"
+
+// rejected returns whether the error only rejects the executed instruction
+// instead of failing the whole batch.
+func rejected(err error) bool {
+	switch err.(type) {
+	case *PanicError, *EffectError:
+		return true
+	default:
+		return false
+	}
+}
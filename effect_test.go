@@ -0,0 +1,133 @@
+package turing
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectStrict(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&faulty{}, &expiringSet{}},
+		Standalone:   true,
+		StrictEffect: true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	misreports := testutil.ToFloat64(effectMisreports.WithLabelValues("faulty"))
+
+	errs := make([]error, 3)
+	err = db.update([]Instruction{
+		&expiringSet{Key: []byte("foo"), Value: []byte("1"), TTL: time.Hour},
+		&faulty{Mode: "effect"},
+		&expiringSet{Key: []byte("bar"), Value: []byte("2"), TTL: time.Hour},
+	}, errs, 1, time.Now().UnixNano(), 0)
+	assert.NoError(t, err)
+	assert.Nil(t, errs[0])
+	assert.Equal(t, &EffectError{Name: "faulty", Declared: 1}, errs[1])
+	assert.Nil(t, errs[2])
+	assert.Equal(t, 2, countKeys(db, userPrefix))
+	assert.Equal(t, misreports+1, testutil.ToFloat64(effectMisreports.WithLabelValues("faulty")))
+
+	err = db.update([]Instruction{
+		&faulty{Mode: "effect"},
+	}, nil, 2, time.Now().UnixNano(), 0)
+	assert.Equal(t, &EffectError{Name: "faulty", Declared: 1}, err)
+
+	assert.NoError(t, db.close())
+}
+
+func TestEffectMisreport(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&faulty{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	declared := testutil.ToFloat64(effectMetrics.WithLabelValues("faulty", "declared"))
+	actual := testutil.ToFloat64(effectMetrics.WithLabelValues("faulty", "actual"))
+	misreports := testutil.ToFloat64(effectMisreports.WithLabelValues("faulty"))
+
+	err = db.update([]Instruction{
+		&faulty{Mode: "effect"},
+		&faulty{Mode: "seeded"},
+	}, nil, 1, time.Now().UnixNano(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countKeys(db, userPrefix))
+	assert.Equal(t, declared+2, testutil.ToFloat64(effectMetrics.WithLabelValues("faulty", "declared")))
+	assert.Equal(t, actual+3, testutil.ToFloat64(effectMetrics.WithLabelValues("faulty", "actual")))
+	assert.Equal(t, misreports+1, testutil.ToFloat64(effectMisreports.WithLabelValues("faulty")))
+
+	assert.NoError(t, db.close())
+}
+
+func TestEffectErrorEncoding(t *testing.T) {
+	err1 := &EffectError{Name: "foo", Declared: 3}
+	bytes, _, err := err1.Encode()
+	assert.NoError(t, err)
+
+	var err2 EffectError
+	err = err2.Decode(bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, *err1, err2)
+	assert.Equal(t, "turing: effect exceeded by foo: declared 3", err2.Error())
+}
+
+func TestEffectMachine(t *testing.T) {
+	machine, err := Start(Config{
+		Instructions: []Instruction{&faulty{}},
+		Standalone:   true,
+		StrictEffect: true,
+	})
+	assert.NoError(t, err)
+	defer machine.Stop()
+
+	err = machine.Execute(&faulty{Mode: "effect"})
+	assert.Equal(t, &EffectError{Name: "faulty", Declared: 1}, err)
+
+	err = machine.Execute(&faulty{Mode: "seeded"})
+	assert.NoError(t, err)
+}
+
+func TestEffectReplicated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "turing")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	machine, err := Start(Config{
+		ID:            1,
+		Members:       []Member{{ID: 1, Host: "127.0.0.1", Port: 42004}},
+		Directory:     dir,
+		Instructions:  []Instruction{&faulty{}},
+		RoundTripTime: time.Millisecond,
+		StrictEffect:  true,
+	})
+	assert.NoError(t, err)
+	defer machine.Stop()
+
+	for machine.Status().Role != RoleLeader {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	err = machine.Execute(&faulty{Mode: "effect"})
+	assert.Equal(t, &EffectError{Name: "faulty", Declared: 1}, err)
+
+	err = machine.Execute(&faulty{Mode: "seeded"})
+	assert.NoError(t, err)
+}
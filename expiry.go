@@ -0,0 +1,229 @@
+package turing
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/cockroachdb/pebble"
+
+	"github.com/256dpi/turing/tape"
+)
+
+var expiryPrefix = []byte("$ttl:")
+
+func expiryKey(expiry int64, key []byte) ([]byte, Ref) {
+	// borrow buffer
+	buf, ref := fpack.Borrow(len(expiryPrefix) + 8 + len(key))
+
+	// write key
+	n := copy(buf, expiryPrefix)
+	binary.BigEndian.PutUint64(buf[n:], uint64(expiry))
+	copy(buf[n+8:], key)
+
+	return buf, ref
+}
+
+func parseExpiryKey(ek []byte) (int64, []byte, error) {
+	// check length
+	if len(ek) < len(expiryPrefix)+8 {
+		return 0, nil, fmt.Errorf("turing: invalid expiry key")
+	}
+
+	// get expiry and key
+	ek = ek[len(expiryPrefix):]
+	expiry := int64(binary.BigEndian.Uint64(ek))
+	key := ek[8:]
+
+	return expiry, key, nil
+}
+
+func expiryIterator(reader pebble.Reader, now int64) *pebble.Iterator {
+	// prepare upper bound (all entries that expire at or before now)
+	upper := make([]byte, len(expiryPrefix)+8)
+	copy(upper, expiryPrefix)
+	binary.BigEndian.PutUint64(upper[len(expiryPrefix):], uint64(now+1))
+
+	return reader.NewIter(&pebble.IterOptions{
+		LowerBound: expiryPrefix,
+		UpperBound: upper,
+	})
+}
+
+// expiryProbe is an internal instruction that checks whether expired keys are
+// available for sweeping.
+type expiryProbe struct {
+	Found bool
+}
+
+var expiryProbeDesc = &Description{
+	Name: "turing/ExpiryProbe",
+}
+
+func (p *expiryProbe) Describe() *Description {
+	return expiryProbeDesc
+}
+
+func (p *expiryProbe) Effect() int {
+	return 0
+}
+
+func (p *expiryProbe) Execute(mem Memory, _ Cache) error {
+	// get transaction
+	txn := mem.(*transaction)
+
+	// check index
+	iter := expiryIterator(txn.reader, txn.now)
+	p.Found = iter.First()
+
+	// close iterator
+	err := iter.Close()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *expiryProbe) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Bool(p.Found)
+
+		return nil
+	})
+}
+
+func (p *expiryProbe) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("turing: decode expiry probe: invalid version")
+		}
+
+		// decode body
+		p.Found = dec.Bool()
+
+		return nil
+	})
+}
+
+// expirySweep is an internal instruction that deletes expired keys.
+type expirySweep struct{}
+
+var expirySweepDesc = &Description{
+	Name:     "turing/ExpirySweep",
+	NoResult: true,
+}
+
+func (s *expirySweep) Describe() *Description {
+	return expirySweepDesc
+}
+
+func (s *expirySweep) Effect() int {
+	return UnboundedEffect
+}
+
+func (s *expirySweep) Execute(mem Memory, _ Cache) error {
+	// get transaction
+	txn := mem.(*transaction)
+
+	// get limit (each entry deletes the index entry and the key)
+	limit := (txn.config.MaxEffect - txn.writes) / 2
+	if limit <= 0 {
+		return ErrMaxEffect
+	}
+
+	// collect expired index entries
+	var entries [][]byte
+	iter := expiryIterator(txn.reader, txn.now)
+	for iter.First(); iter.Valid() && len(entries) < limit; iter.Next() {
+		entries = append(entries, Clone(iter.Key()))
+	}
+
+	// close iterator
+	err := iter.Close()
+	if err != nil {
+		return err
+	}
+
+	// delete expired keys
+	for _, entry := range entries {
+		// parse entry
+		expiry, key, err := parseExpiryKey(entry)
+		if err != nil {
+			return err
+		}
+
+		// delete key if it has not been changed since
+		err = s.delete(txn, key, expiry)
+		if err != nil {
+			return err
+		}
+
+		// delete entry
+		err = txn.writer.Delete(entry, nil)
+		if err != nil {
+			return err
+		}
+
+		// increment effect
+		txn.effect++
+		txn.writes += 2
+	}
+
+	// check if more entries might be available
+	if len(entries) == limit {
+		return ErrMaxEffect
+	}
+
+	return nil
+}
+
+func (s *expirySweep) delete(txn *transaction, key []byte, expiry int64) error {
+	// prefix key
+	pk, pkr := prefixUserKey(key)
+	defer pkr.Release()
+
+	// get value
+	value, closer, err := txn.reader.Get(pk)
+	if err == pebble.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// ensure close
+	defer closer.Close()
+
+	// decode cell
+	var cell tape.Cell
+	err = cell.Decode(value, false)
+	if err != nil {
+		return err
+	}
+
+	// skip if expiry has changed
+	if cell.Expiry != expiry {
+		return nil
+	}
+
+	// delete key
+	err = txn.writer.Delete(pk, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *expirySweep) Encode() ([]byte, Ref, error) {
+	return nil, nil, nil
+}
+
+func (s *expirySweep) Decode([]byte) error {
+	return nil
+}
@@ -0,0 +1,327 @@
+package turing
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/stretchr/testify/assert"
+)
+
+type expiringSet struct {
+	Key   []byte
+	Value []byte
+	TTL   time.Duration
+}
+
+var expiringSetDesc = &Description{
+	Name: "expiringSet",
+}
+
+func (s *expiringSet) Describe() *Description {
+	return expiringSetDesc
+}
+
+func (s *expiringSet) Effect() int {
+	return 1
+}
+
+func (s *expiringSet) Execute(mem Memory, _ Cache) error {
+	return mem.SetWithTTL(s.Key, s.Value, s.TTL)
+}
+
+func (s *expiringSet) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		enc.VarBytes(s.Key)
+		enc.VarBytes(s.Value)
+		enc.VarInt(int64(s.TTL))
+		return nil
+	})
+}
+
+func (s *expiringSet) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		s.Key = dec.VarBytes(true)
+		s.Value = dec.VarBytes(true)
+		s.TTL = time.Duration(dec.VarInt())
+		return nil
+	})
+}
+
+var appendOperator = &Operator{
+	Name: "append",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, Ref, error) {
+		value = Clone(value)
+		for _, op := range ops {
+			value = append(value, op...)
+		}
+		return value, noopRef, nil
+	},
+}
+
+type expiringAppend struct {
+	Key    []byte
+	Value  []byte
+	Result []byte
+}
+
+var expiringAppendDesc = &Description{
+	Name:      "expiringAppend",
+	Operators: []*Operator{appendOperator},
+}
+
+func (a *expiringAppend) Describe() *Description {
+	return expiringAppendDesc
+}
+
+func (a *expiringAppend) Effect() int {
+	if a.Value == nil {
+		return 0
+	}
+
+	return 1
+}
+
+func (a *expiringAppend) Execute(mem Memory, _ Cache) error {
+	// get value if no operand is provided
+	if a.Value == nil {
+		return mem.Use(a.Key, func(value []byte) error {
+			a.Result = Clone(value)
+			return nil
+		})
+	}
+
+	return mem.Merge(a.Key, a.Value, appendOperator)
+}
+
+func (a *expiringAppend) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		enc.VarBytes(a.Key)
+		enc.VarBytes(a.Value)
+		enc.VarBytes(a.Result)
+		return nil
+	})
+}
+
+func (a *expiringAppend) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		a.Key = dec.VarBytes(true)
+		a.Value = dec.VarBytes(true)
+		a.Result = dec.VarBytes(true)
+		return nil
+	})
+}
+
+func TestExpirySweep(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&expiringSet{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	for i := 0; i < 10; i++ {
+		err = db.update([]Instruction{&expiringSet{
+			Key:   []byte(fmt.Sprintf("foo%d", i)),
+			Value: []byte("bar"),
+			TTL:   time.Duration(i+1) * time.Second,
+		}}, nil, 0, now, 0)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 10, countKeys(db, userPrefix))
+	assert.Equal(t, 10, countKeys(db, expiryPrefix))
+
+	err = db.update([]Instruction{&expirySweep{}}, nil, 0, now+int64(5*time.Second), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 5, countKeys(db, userPrefix))
+	assert.Equal(t, 5, countKeys(db, expiryPrefix))
+
+	// extend ttl
+	err = db.update([]Instruction{&expiringSet{
+		Key:   []byte("foo9"),
+		Value: []byte("bar"),
+		TTL:   time.Hour,
+	}}, nil, 0, now, 0)
+	assert.NoError(t, err)
+
+	err = db.update([]Instruction{&expirySweep{}}, nil, 0, now+int64(20*time.Second), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, countKeys(db, userPrefix))
+	assert.Equal(t, 1, countKeys(db, expiryPrefix))
+
+	assert.NoError(t, db.close())
+}
+
+func TestExpirySweepEffect(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&expiringSet{}},
+		Standalone:   true,
+		MaxEffect:    5,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	for i := 0; i < 5; i++ {
+		err = db.update([]Instruction{&expiringSet{
+			Key:   []byte(fmt.Sprintf("foo%d", i)),
+			Value: []byte("bar"),
+			TTL:   time.Second,
+		}}, nil, 0, now, 0)
+		assert.NoError(t, err)
+	}
+
+	batch := db.pebble.NewIndexedBatch()
+	txn := newTransaction()
+	txn.config = db.config
+	txn.registry = db.registry
+	txn.reader = batch
+	txn.writer = batch
+	txn.now = now + int64(time.Minute)
+
+	effectMaxed, err := txn.execute(&expirySweep{}, newCache())
+	assert.NoError(t, err)
+	assert.True(t, effectMaxed)
+	assert.Equal(t, 2, txn.effect)
+	assert.Equal(t, 4, txn.writes)
+	assert.Equal(t, uint32(4), batch.Count())
+	assert.NoError(t, batch.Close())
+	recycleTransaction(txn)
+
+	err = db.update([]Instruction{&expirySweep{}}, nil, 0, now+int64(time.Minute), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, countKeys(db, userPrefix))
+	assert.Equal(t, 0, countKeys(db, expiryPrefix))
+
+	assert.NoError(t, db.close())
+}
+
+func TestExpiryWrites(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&expiringSet{}, &expiringAppend{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	batch := db.pebble.NewIndexedBatch()
+	txn := newTransaction()
+	txn.config = db.config
+	txn.registry = db.registry
+	txn.reader = batch
+	txn.writer = batch
+	txn.now = now
+
+	_, err = txn.execute(&expiringSet{Key: []byte("foo"), Value: []byte("x"), TTL: time.Second}, newCache())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, txn.effect)
+	assert.Equal(t, 2, txn.writes)
+
+	txn.now = now + int64(2*time.Second)
+
+	_, err = txn.execute(&expiringAppend{Key: []byte("foo"), Value: []byte("a")}, newCache())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, txn.effect)
+	assert.Equal(t, 4, txn.writes)
+	assert.Equal(t, uint32(4), batch.Count())
+
+	txn.now = 0
+
+	_, err = txn.execute(&expiringSet{Key: []byte("bar"), Value: []byte("y"), TTL: time.Second}, newCache())
+	assert.Equal(t, ErrMissingTime, err)
+
+	assert.NoError(t, batch.Close())
+	recycleTransaction(txn)
+
+	assert.NoError(t, db.close())
+}
+
+func TestExpiryMerge(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&expiringSet{}, &expiringAppend{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	err = db.update([]Instruction{
+		&expiringSet{Key: []byte("foo"), Value: []byte("x"), TTL: time.Second},
+		&expiringSet{Key: []byte("bar"), Value: []byte("y"), TTL: time.Minute},
+	}, nil, 0, now, 0)
+	assert.NoError(t, err)
+
+	err = db.update([]Instruction{
+		&expiringAppend{Key: []byte("foo"), Value: []byte("a")},
+		&expiringAppend{Key: []byte("bar"), Value: []byte("b")},
+	}, nil, 0, now+int64(2*time.Second), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, countKeys(db, userPrefix))
+	assert.Equal(t, 2, countKeys(db, expiryPrefix))
+
+	err = db.update([]Instruction{&expirySweep{}}, nil, 0, now+int64(3*time.Second), 0)
+	assert.NoError(t, err)
+
+	get := &expiringAppend{Key: []byte("foo")}
+	err = db.lookup([]Instruction{get}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a"), get.Result)
+
+	get = &expiringAppend{Key: []byte("bar")}
+	err = db.lookup([]Instruction{get}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("yb"), get.Result)
+
+	err = db.update([]Instruction{&expirySweep{}}, nil, 0, now+int64(2*time.Minute), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, countKeys(db, userPrefix))
+	assert.Equal(t, 0, countKeys(db, expiryPrefix))
+
+	assert.NoError(t, db.close())
+}
+
+func countKeys(db *database, prefix []byte) int {
+	iter := db.pebble.NewIter(prefixIterator(prefix))
+	defer iter.Close()
+
+	var count int
+	for iter.First(); iter.Valid(); iter.Next() {
+		count++
+	}
+
+	return count
+}
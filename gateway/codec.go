@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/256dpi/turing"
+)
+
+// Codec encodes and decodes instructions to and from JSON.
+type Codec interface {
+	// Decode will decode the input fields of the instruction from the provided
+	// JSON document.
+	Decode(data []byte, ins turing.Instruction) error
+
+	// Encode will encode the result fields of the executed instruction as a
+	// JSON document.
+	Encode(ins turing.Instruction) ([]byte, error)
+}
+
+// Reflect is a codec that uses the standard JSON encoding of the instruction
+// struct. Byte slices are therefore encoded using base64.
+var Reflect Codec = reflectCodec{}
+
+type reflectCodec struct{}
+
+func (reflectCodec) Decode(data []byte, ins turing.Instruction) error {
+	// skip empty documents
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	// decode strictly
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(ins)
+}
+
+func (reflectCodec) Encode(ins turing.Instruction) ([]byte, error) {
+	return json.Marshal(ins)
+}
+
+// Funcs is a codec that uses the provided functions. Missing functions fall
+// back to the Reflect codec.
+type Funcs struct {
+	DecodeFunc func(data []byte, ins turing.Instruction) error
+	EncodeFunc func(ins turing.Instruction) ([]byte, error)
+}
+
+// Decode implements the Codec interface.
+func (f Funcs) Decode(data []byte, ins turing.Instruction) error {
+	if f.DecodeFunc == nil {
+		return Reflect.Decode(data, ins)
+	}
+
+	return f.DecodeFunc(data, ins)
+}
+
+// Encode implements the Codec interface.
+func (f Funcs) Encode(ins turing.Instruction) ([]byte, error) {
+	if f.EncodeFunc == nil {
+		return Reflect.Encode(ins)
+	}
+
+	return f.EncodeFunc(ins)
+}
@@ -0,0 +1,228 @@
+// Package gateway provides an HTTP handler that exposes the instructions of a
+// machine as JSON endpoints.
+//
+// The handler serves the following endpoints:
+//
+//	GET  /instructions         lists the names of all exposed instructions
+//	POST /instructions/{name}  executes the named instruction
+//	GET  /status               returns the machine status
+//
+// Read instructions may be executed as stale reads by adding "?stale=true"
+// to the request.
+package gateway
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/256dpi/turing"
+)
+
+// Config is used to configure a gateway.
+type Config struct {
+	// The machine used to execute instructions.
+	Machine *turing.Machine
+
+	// The codecs used for the named instructions. Instructions without a
+	// codec use the Reflect codec.
+	Codecs map[string]Codec
+
+	// The names of the exposed instructions. If empty, all configured
+	// instructions of the machine are exposed.
+	Instructions []string
+
+	// The maximum size of request bodies.
+	//
+	// Default: 4 MiB.
+	MaxBodySize int64
+}
+
+// Gateway is an HTTP handler that executes instructions.
+type Gateway struct {
+	config  Config
+	names   []string
+	exposed map[string]bool
+}
+
+// New creates and returns a new gateway.
+func New(config Config) *Gateway {
+	// set default body size
+	if config.MaxBodySize == 0 {
+		config.MaxBodySize = 4 << 20
+	}
+
+	// get names
+	names := config.Instructions
+	if len(names) == 0 {
+		names = config.Machine.Instructions()
+	}
+
+	// prepare exposed
+	exposed := map[string]bool{}
+	for _, name := range names {
+		exposed[name] = true
+	}
+
+	return &Gateway{
+		config:  config,
+		names:   names,
+		exposed: exposed,
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// get path
+	path := strings.Trim(r.URL.Path, "/")
+
+	// route request
+	switch {
+	case path == "instructions":
+		g.list(w, r)
+	case strings.HasPrefix(path, "instructions/"):
+		g.execute(w, r, strings.TrimPrefix(path, "instructions/"))
+	case path == "status":
+		g.status(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (g *Gateway) list(w http.ResponseWriter, r *http.Request) {
+	// check method
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, g.names)
+}
+
+func (g *Gateway) execute(w http.ResponseWriter, r *http.Request, name string) {
+	// check method
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// check name
+	if !g.exposed[name] {
+		writeError(w, http.StatusNotFound, "unknown instruction")
+		return
+	}
+
+	// parse options
+	var opts turing.Options
+	if stale := r.URL.Query().Get("stale"); stale != "" {
+		var err error
+		opts.StaleRead, err = strconv.ParseBool(stale)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid stale parameter")
+			return
+		}
+	}
+
+	// build instruction
+	ins, err := g.config.Machine.Build(name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	// get codec
+	codec := g.config.Codecs[name]
+	if codec == nil {
+		codec = Reflect
+	}
+
+	// read body
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, g.config.MaxBodySize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+
+	// decode instruction
+	err = codec.Decode(body, ins)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// execute instruction
+	err = g.config.Machine.Execute(ins, opts)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// handle no result
+	if ins.Describe().NoResult {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// encode result
+	res, err := codec.Encode(ins)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// write result
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(res)
+}
+
+type member struct {
+	ID   uint64 `json:"id"`
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type status struct {
+	ID      uint64   `json:"id"`
+	Role    string   `json:"role"`
+	Leader  *member  `json:"leader"`
+	Members []member `json:"members"`
+}
+
+func (g *Gateway) status(w http.ResponseWriter, r *http.Request) {
+	// check method
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// get status
+	s := g.config.Machine.Status()
+
+	// convert status
+	res := status{
+		ID:      s.ID,
+		Role:    s.Role.String(),
+		Members: make([]member, 0, len(s.Members)),
+	}
+	if s.Leader != nil {
+		res.Leader = &member{ID: s.Leader.ID, Host: s.Leader.Host, Port: s.Leader.Port}
+	}
+	for _, m := range s.Members {
+		res.Members = append(res.Members, member{ID: m.ID, Host: m.Host, Port: m.Port})
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/stdset"
+)
+
+func TestMain(m *testing.M) {
+	// disable logging
+	turing.SetLogger(nil)
+
+	// run tests
+	os.Exit(m.Run())
+}
+
+func request(handler http.Handler, method, path, body string) (int, string) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec.Code, strings.TrimSpace(rec.Body.String())
+}
+
+func TestGateway(t *testing.T) {
+	machine := turing.Test(&stdset.Set{}, &stdset.Get{}, &stdset.Inc{})
+	defer machine.Stop()
+
+	gateway := New(Config{
+		Machine: machine,
+	})
+
+	code, body := request(gateway, "GET", "/instructions", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `["turing/Set","turing/Get","turing/Inc"]`, body)
+
+	code, body = request(gateway, "POST", "/instructions/turing/Set", `{"Key":"Zm9v","Value":"YmFy"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"Key":"Zm9v","Value":"YmFy","TTL":0}`, body)
+
+	code, body = request(gateway, "POST", "/instructions/turing/Get?stale=true", `{"Key":"Zm9v"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"Key":"Zm9v","Value":"YmFy","Exists":true}`, body)
+
+	code, body = request(gateway, "POST", "/instructions/turing/Get?stale=foo", `{"Key":"Zm9v"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, `{"error":"invalid stale parameter"}`, body)
+
+	code, _ = request(gateway, "POST", "/instructions/turing/Get", `{"Foo":"Zm9v"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = request(gateway, "GET", "/instructions/turing/Get", ``)
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	code, body = request(gateway, "POST", "/instructions/turing/ExpirySweep", ``)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, `{"error":"unknown instruction"}`, body)
+
+	code, body = request(gateway, "GET", "/status", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"id":0,"role":"Unknown","leader":null,"members":[]}`, body)
+}
+
+func TestGatewayCodecs(t *testing.T) {
+	machine := turing.Test(&stdset.Set{}, &stdset.Get{})
+	defer machine.Stop()
+
+	type pair struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
+	gateway := New(Config{
+		Machine: machine,
+		Codecs: map[string]Codec{
+			"turing/Set": Funcs{
+				DecodeFunc: func(data []byte, ins turing.Instruction) error {
+					var p pair
+					err := json.Unmarshal(data, &p)
+					ins.(*stdset.Set).Key = []byte(p.Key)
+					ins.(*stdset.Set).Value = []byte(p.Value)
+					return err
+				},
+				EncodeFunc: func(ins turing.Instruction) ([]byte, error) {
+					return []byte(`{}`), nil
+				},
+			},
+			"turing/Get": Funcs{
+				DecodeFunc: func(data []byte, ins turing.Instruction) error {
+					var p pair
+					err := json.Unmarshal(data, &p)
+					ins.(*stdset.Get).Key = []byte(p.Key)
+					return err
+				},
+				EncodeFunc: func(ins turing.Instruction) ([]byte, error) {
+					get := ins.(*stdset.Get)
+					return json.Marshal(pair{Key: string(get.Key), Value: string(get.Value)})
+				},
+			},
+		},
+		Instructions: []string{"turing/Set", "turing/Get"},
+	})
+
+	code, body := request(gateway, "POST", "/instructions/turing/Set", `{"key":"foo","value":"bar"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{}`, body)
+
+	code, body = request(gateway, "POST", "/instructions/turing/Get", `{"key":"foo"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"key":"foo","value":"bar"}`, body)
+}
@@ -0,0 +1,416 @@
+package turing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/cockroachdb/pebble"
+	pfs "github.com/cockroachdb/pebble/vfs"
+	"github.com/lni/dragonboat/v3/raftpb"
+	dfs "github.com/lni/goutils/vfs"
+
+	"github.com/256dpi/turing/tape"
+	"github.com/256dpi/turing/wire"
+)
+
+// The dragonboat logdb does not provide a public API to read entries. The
+// following key layout has been copied from the internal logdb package of the
+// vendored dragonboat version and must be verified when upgrading.
+const logFormatVersion = "v3.3.2"
+
+var logEntryHeader = []byte{0x01, 0x01}
+var logBatchHeader = []byte{0x07, 0x07}
+
+const logKeySize = 28
+
+// logFS adapts the raft filesystem to be used by pebble.
+type logFS struct {
+	dfs.FS
+}
+
+func (f *logFS) Create(name string) (pfs.File, error) {
+	return f.FS.Create(name)
+}
+
+func (f *logFS) Open(name string, opts ...pfs.OpenOption) (pfs.File, error) {
+	// open file
+	file, err := f.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// apply options
+	for _, opt := range opts {
+		opt.Apply(file)
+	}
+
+	return file, nil
+}
+
+func (f *logFS) OpenDir(name string) (pfs.File, error) {
+	return f.FS.OpenDir(name)
+}
+
+func (f *logFS) ReuseForWrite(oldname, newname string) (pfs.File, error) {
+	return f.FS.ReuseForWrite(oldname, newname)
+}
+
+// LogEntry describes an entry read from the raft log.
+type LogEntry struct {
+	// The index and term of the entry.
+	Index uint64
+	Term  uint64
+
+	// The raft entry type.
+	Type string
+
+	// The size of the entry payload.
+	Size int
+
+	// The decoded command, if the entry carries one.
+	Command *wire.Command
+
+	// The decoded instructions, if they are registered. Unknown instructions
+	// are returned as nil.
+	Instructions []Instruction
+
+	// The error encountered while decoding the command or instructions.
+	Error error
+}
+
+// ReadLog will read the raft log of a stopped node and yield all available
+// entries in index order. Instruction payloads are decoded if they have been
+// configured in the provided config.
+func ReadLog(config Config, fn func(LogEntry) error) error {
+	// check directory
+	if config.Directory == "" {
+		return fmt.Errorf("turing: read log: missing directory")
+	}
+
+	// build registry
+	registry, err := buildRegistry(config)
+	if err != nil {
+		return err
+	}
+
+	// get filesystem
+	fs := config.RaftFS()
+
+	// find shards
+	shards, err := findLogShards(fs, config.RaftDir())
+	if err != nil {
+		return err
+	}
+
+	// collect entries
+	entries := map[uint64]raftpb.Entry{}
+	for _, shard := range shards {
+		err = readLogShard(fs, shard, entries)
+		if err != nil {
+			return err
+		}
+	}
+
+	// sort indexes
+	indexes := make([]uint64, 0, len(entries))
+	for index := range entries {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i] < indexes[j]
+	})
+
+	// yield entries
+	for _, index := range indexes {
+		err = fn(decodeLogEntry(registry, entries[index]))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findLogShards will return the logdb shard directories that are stored below
+// the host and node directories created by dragonboat.
+func findLogShards(fs dfs.FS, dir string) ([]string, error) {
+	// walk levels
+	dirs := []string{dir}
+	for _, pattern := range []string{"*", "*", "logdb-*"} {
+		var matches []string
+		for _, dir := range dirs {
+			// list directory
+			names, err := fs.List(dir)
+			if err != nil {
+				return nil, err
+			}
+
+			// collect matching directories
+			for _, name := range names {
+				if ok, _ := filepath.Match(pattern, name); !ok {
+					continue
+				}
+				path := fs.PathJoin(dir, name)
+				info, err := fs.Stat(path)
+				if err != nil {
+					return nil, err
+				} else if info.IsDir() {
+					matches = append(matches, path)
+				}
+			}
+		}
+		dirs = matches
+	}
+
+	// sort shards
+	sort.Strings(dirs)
+
+	return dirs, nil
+}
+
+func readLogShard(fs dfs.FS, dir string, entries map[uint64]raftpb.Entry) error {
+	// open shard
+	db, err := pebble.Open(dir, &pebble.Options{
+		FS:       &logFS{FS: fs},
+		ReadOnly: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// ensure close
+	defer db.Close()
+
+	// prepare collector
+	collect := func(entry raftpb.Entry) {
+		// keep entry from latest term
+		if existing, ok := entries[entry.Index]; !ok || entry.Term >= existing.Term {
+			entries[entry.Index] = entry
+		}
+	}
+
+	// create iterator
+	iter := db.NewIter(nil)
+	defer iter.Close()
+
+	// iterate all keys
+	for iter.First(); iter.Valid(); iter.Next() {
+		// get key
+		key := iter.Key()
+		if len(key) != logKeySize || binary.BigEndian.Uint64(key[4:]) != clusterID {
+			continue
+		}
+
+		// handle entries and batches
+		switch {
+		case bytes.HasPrefix(key, logEntryHeader):
+			var entry raftpb.Entry
+			err = entry.Unmarshal(iter.Value())
+			if err != nil {
+				return fmt.Errorf("turing: read log: %w", err)
+			}
+			collect(entry)
+		case bytes.HasPrefix(key, logBatchHeader):
+			var batch raftpb.EntryBatch
+			err = batch.Unmarshal(iter.Value())
+			if err != nil {
+				return fmt.Errorf("turing: read log: %w", err)
+			}
+			for _, entry := range batch.Entries {
+				collect(entry)
+			}
+		}
+	}
+
+	return iter.Error()
+}
+
+func decodeLogEntry(registry *registry, entry raftpb.Entry) LogEntry {
+	// prepare result
+	result := LogEntry{
+		Index: entry.Index,
+		Term:  entry.Term,
+		Type:  entry.Type.String(),
+		Size:  len(entry.Cmd),
+	}
+
+	// get payload
+	payload := entry.Cmd
+	switch entry.Type {
+	case raftpb.ApplicationEntry:
+	case raftpb.EncodedEntry:
+		// check header (only uncompressed payloads are produced)
+		if len(payload) > 0 && payload[0] != 0 {
+			result.Error = fmt.Errorf("turing: read log: unsupported entry encoding: %d", payload[0])
+			return result
+		} else if len(payload) > 0 {
+			payload = payload[1:]
+		}
+	default:
+		return result
+	}
+
+	// skip empty entries
+	if len(payload) == 0 {
+		return result
+	}
+
+	// decode command
+	var cmd wire.Command
+	err := cmd.Decode(payload, true)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	// set command
+	result.Command = &cmd
+
+	// decode instructions
+	for _, op := range cmd.Operations {
+		// build instruction
+		ins, err := registry.build(op.Name)
+		if err != nil {
+			result.Instructions = append(result.Instructions, nil)
+			continue
+		}
+
+		// decode instruction
+		err = ins.Decode(op.Code)
+		if err != nil && result.Error == nil {
+			result.Error = fmt.Errorf("turing: read log: decode %s: %w", op.Name, err)
+		}
+
+		// add instruction
+		result.Instructions = append(result.Instructions, ins)
+	}
+
+	return result
+}
+
+// BackupInfo describes a verified backup.
+type BackupInfo struct {
+	// The state of the database at the time of the backup.
+	State tape.State
+
+	// The total and user key count.
+	Keys     int
+	UserKeys int
+
+	// The total size of all keys and values.
+	Bytes int64
+}
+
+// VerifyBackup will read and verify a backup produced by a machine snapshot.
+// It checks the framing, the key order and that the state and all user values
+// can be decoded.
+func VerifyBackup(r io.Reader) (BackupInfo, error) {
+	// prepare info
+	var info BackupInfo
+	var last []byte
+
+	// read backup
+	err := readBackup(r, func(key, value []byte) error {
+		// check order
+		if last != nil && bytes.Compare(key, last) <= 0 {
+			return fmt.Errorf("turing: verify backup: key out of order: %q", key)
+		}
+		last = append(last[:0], key...)
+
+		// update counters
+		info.Keys++
+		info.Bytes += int64(len(key) + len(value))
+
+		// verify state
+		if bytes.Equal(key, stateKey) {
+			err := info.State.Decode(value)
+			if err != nil {
+				return fmt.Errorf("turing: verify backup: invalid state: %w", err)
+			}
+			return nil
+		}
+
+		// skip non user keys
+		if !bytes.HasPrefix(key, userPrefix) {
+			return nil
+		}
+
+		// verify cell
+		var cell tape.Cell
+		err := cell.Decode(value, false)
+		if err != nil {
+			return fmt.Errorf("turing: verify backup: invalid cell %q: %w", key, err)
+		}
+
+		// verify stack
+		if cell.Type == tape.StackCell {
+			var stack tape.Stack
+			err = stack.Decode(cell.Value, false)
+			if err != nil {
+				return fmt.Errorf("turing: verify backup: invalid stack %q: %w", key, err)
+			}
+		}
+
+		// increment
+		info.UserKeys++
+
+		return nil
+	})
+	if err == io.ErrUnexpectedEOF {
+		return info, fmt.Errorf("turing: verify backup: truncated stream")
+	} else if err != nil {
+		return info, err
+	}
+
+	return info, nil
+}
+
+// ClassifyKey will return the name of the keyspace the provided raw database
+// key belongs to.
+func ClassifyKey(key []byte) string {
+	switch {
+	case bytes.HasPrefix(key, userPrefix):
+		return "# (user)"
+	case bytes.Equal(key, stateKey):
+		return "$state"
+	case bytes.Equal(key, syncKey):
+		return "$sync"
+	case bytes.HasPrefix(key, expiryPrefix):
+		return "$ttl: (expiry)"
+	case bytes.HasPrefix(key, checksumPrefix):
+		return "$checksum: (pending)"
+	case bytes.Equal(key, continuationKey):
+		return "$continuation"
+	default:
+		return "other"
+	}
+}
+
+// UserKey will return the raw database key for the provided user key.
+func UserKey(key []byte) []byte {
+	return append(Clone(userPrefix), key...)
+}
+
+// TrimUserKey will return the user key of the provided raw database key and
+// whether the raw key is a user key at all.
+func TrimUserKey(key []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(key, userPrefix) {
+		return nil, false
+	}
+
+	return key[len(userPrefix):], true
+}
+
+// StateKey will return the raw database key of the stored state.
+func StateKey() []byte {
+	return Clone(stateKey)
+}
+
+// SyncKey will return the raw database key of the stored sync marker.
+func SyncKey() []byte {
+	return Clone(syncKey)
+}
@@ -0,0 +1,141 @@
+package turing
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	pfs "github.com/cockroachdb/pebble/vfs"
+	dfs "github.com/lni/goutils/vfs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "turing")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	testReadLog(t, Config{
+		ID:            1,
+		Members:       []Member{{ID: 1, Host: "127.0.0.1", Port: 42001}},
+		Directory:     dir,
+		Instructions:  []Instruction{&expiringSet{}},
+		RoundTripTime: time.Millisecond,
+	})
+}
+
+func TestReadLogFS(t *testing.T) {
+	testReadLog(t, Config{
+		ID:                 1,
+		Members:            []Member{{ID: 1, Host: "127.0.0.1", Port: 42005}},
+		Directory:          "/turing",
+		Instructions:       []Instruction{&expiringSet{}},
+		RoundTripTime:      time.Millisecond,
+		RaftFileSystem:     dfs.NewMem(),
+		DatabaseFileSystem: pfs.NewMem(),
+	})
+}
+
+func TestLogFormatVersion(t *testing.T) {
+	info, ok := debug.ReadBuildInfo()
+	assert.True(t, ok)
+
+	var version string
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/lni/dragonboat/v3" {
+			version = dep.Version
+		}
+	}
+	assert.Equal(t, logFormatVersion, version, "verify the log key layout")
+}
+
+func testReadLog(t *testing.T, config Config) {
+	machine, err := Start(config)
+	assert.NoError(t, err)
+
+	for machine.Status().Role != RoleLeader {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	err = machine.Execute(&expiringSet{Key: []byte("foo"), Value: []byte("bar"), TTL: time.Hour})
+	assert.NoError(t, err)
+
+	machine.Stop()
+
+	var found []*expiringSet
+	err = ReadLog(config, func(entry LogEntry) error {
+		assert.NotZero(t, entry.Index)
+		assert.NotZero(t, entry.Term)
+		assert.NoError(t, entry.Error)
+
+		if entry.Command != nil {
+			assert.Equal(t, "expiringSet", entry.Command.Operations[0].Name)
+			found = append(found, entry.Instructions[0].(*expiringSet))
+		}
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*expiringSet{
+		{Key: []byte("foo"), Value: []byte("bar"), TTL: time.Hour},
+	}, found)
+}
+
+func TestVerifyBackup(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&expiringSet{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	err = db.update([]Instruction{
+		&expiringSet{Key: []byte("foo"), Value: []byte("bar"), TTL: time.Minute},
+		&expiringSet{Key: []byte("baz"), Value: []byte("qux"), TTL: time.Hour},
+	}, nil, 7, time.Now().UnixNano(), 0)
+	assert.NoError(t, err)
+
+	snapshot, err := db.snapshot()
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = db.backup(snapshot, &buf, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.close())
+
+	info, err := VerifyBackup(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), info.State.Index)
+	assert.Equal(t, 2, info.UserKeys)
+	assert.Equal(t, 6, info.Keys)
+	assert.Equal(t, int64(buf.Len()-info.Keys*16), info.Bytes)
+
+	_, err = VerifyBackup(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	assert.Error(t, err)
+}
+
+func TestClassifyKey(t *testing.T) {
+	assert.Equal(t, "# (user)", ClassifyKey(UserKey([]byte("foo"))))
+	assert.Equal(t, "$state", ClassifyKey(StateKey()))
+	assert.Equal(t, "$sync", ClassifyKey(SyncKey()))
+	assert.Equal(t, "$ttl: (expiry)", ClassifyKey(append(Clone(expiryPrefix), 1)))
+	assert.Equal(t, "$checksum: (pending)", ClassifyKey(append(Clone(checksumPrefix), 1)))
+	assert.Equal(t, "$continuation", ClassifyKey(continuationKey))
+	assert.Equal(t, "other", ClassifyKey([]byte("foo")))
+
+	key, ok := TrimUserKey(UserKey([]byte("foo")))
+	assert.True(t, ok)
+	assert.Equal(t, []byte("foo"), key)
+
+	key, ok = TrimUserKey(StateKey())
+	assert.False(t, ok)
+	assert.Nil(t, key)
+}
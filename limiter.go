@@ -0,0 +1,53 @@
+package turing
+
+// limiter enforces the configured per instruction concurrency limits. Blocked
+// callers fail with ErrStopped once the done channel is closed.
+type limiter struct {
+	failFast bool
+	slots    map[string]chan struct{}
+	done     <-chan struct{}
+}
+
+func newLimiter(limits map[string]int, failFast bool, done <-chan struct{}) *limiter {
+	// prepare slots
+	slots := make(map[string]chan struct{}, len(limits))
+	for name, limit := range limits {
+		slots[name] = make(chan struct{}, limit)
+	}
+
+	return &limiter{
+		failFast: failFast,
+		slots:    slots,
+		done:     done,
+	}
+}
+
+func (l *limiter) acquire(name string) (func(), error) {
+	// get slots
+	slots, ok := l.slots[name]
+	if !ok {
+		return noopRelease, nil
+	}
+
+	// acquire slot or fail fast if exhausted
+	if l.failFast {
+		select {
+		case slots <- struct{}{}:
+		default:
+			overloadMetrics.WithLabelValues(name).Inc()
+			return nil, ErrOverloaded
+		}
+	} else {
+		select {
+		case slots <- struct{}{}:
+		case <-l.done:
+			return nil, ErrStopped
+		}
+	}
+
+	return func() {
+		<-slots
+	}, nil
+}
+
+func noopRelease() {}
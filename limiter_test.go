@@ -0,0 +1,169 @@
+package turing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type sleeper struct {
+	Duration time.Duration
+}
+
+var sleeperDesc = &Description{
+	Name: "sleeper",
+}
+
+func (s *sleeper) Describe() *Description {
+	return sleeperDesc
+}
+
+func (s *sleeper) Effect() int {
+	return 1
+}
+
+func (s *sleeper) Execute(Memory, Cache) error {
+	time.Sleep(s.Duration)
+	return nil
+}
+
+func (s *sleeper) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		enc.VarInt(int64(s.Duration))
+		return nil
+	})
+}
+
+func (s *sleeper) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		s.Duration = time.Duration(dec.VarInt())
+		return nil
+	})
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(map[string]int{"a": 1}, true, nil)
+
+	release, err := l.acquire("a")
+	assert.NoError(t, err)
+
+	_, err = l.acquire("a")
+	assert.Equal(t, ErrOverloaded, err)
+
+	for i := 0; i < 3; i++ {
+		r, err := l.acquire("b")
+		assert.NoError(t, err)
+		r()
+	}
+
+	release()
+
+	release, err = l.acquire("a")
+	assert.NoError(t, err)
+	release()
+}
+
+func TestLimiterBlocking(t *testing.T) {
+	stop := make(chan struct{})
+	l := newLimiter(map[string]int{"a": 1}, false, stop)
+
+	release, err := l.acquire("a")
+	assert.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		r, err := l.acquire("a")
+		assert.NoError(t, err)
+		r()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected acquire to block")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	release()
+	<-done
+
+	release, err = l.acquire("a")
+	assert.NoError(t, err)
+
+	stopped := make(chan error, 1)
+	go func() {
+		_, err := l.acquire("a")
+		stopped <- err
+	}()
+
+	close(stop)
+	assert.Equal(t, ErrStopped, <-stopped)
+	release()
+}
+
+func TestLimiterMachine(t *testing.T) {
+	machine, err := Start(Config{
+		Instructions:      []Instruction{&sleeper{}},
+		Standalone:        true,
+		FailFast:          true,
+		InstructionLimits: map[string]int{"sleeper": 1},
+	})
+	assert.NoError(t, err)
+	defer machine.Stop()
+
+	overloads := testutil.ToFloat64(overloadMetrics.WithLabelValues("sleeper"))
+
+	done := make(chan error, 1)
+	err = machine.ExecuteAsync(&sleeper{Duration: 50 * time.Millisecond}, func(err error) {
+		done <- err
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&sleeper{})
+	assert.Equal(t, ErrOverloaded, err)
+	assert.Equal(t, overloads+1, testutil.ToFloat64(overloadMetrics.WithLabelValues("sleeper")))
+
+	assert.NoError(t, <-done)
+
+	err = machine.Execute(&sleeper{})
+	assert.NoError(t, err)
+}
+
+func TestLimiterShutdown(t *testing.T) {
+	machine, err := Start(Config{
+		Instructions:      []Instruction{&sleeper{}},
+		Standalone:        true,
+		InstructionLimits: map[string]int{"sleeper": 1},
+	})
+	assert.NoError(t, err)
+
+	done := make(chan error, 1)
+	err = machine.ExecuteAsync(&sleeper{Duration: 50 * time.Millisecond}, func(err error) {
+		done <- err
+	})
+	assert.NoError(t, err)
+
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- machine.Execute(&sleeper{})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	err = machine.Shutdown(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, <-done)
+	assert.Equal(t, ErrStopped, <-blocked)
+}
+
+func TestLimiterConfig(t *testing.T) {
+	config := Config{
+		Standalone:        true,
+		InstructionLimits: map[string]int{"foo": 0},
+	}
+	assert.Equal(t, "turing: config validate: invalid instruction limit: foo", config.Validate().Error())
+}
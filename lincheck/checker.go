@@ -0,0 +1,258 @@
+package lincheck
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Model is the sequential specification of the checked instructions. States
+// must be treated as immutable by the model.
+type Model struct {
+	// The function that returns the initial state.
+	Init func() interface{}
+
+	// The function that applies the operation to the state. It returns
+	// whether the output of the operation is valid for the state and the
+	// resulting state. Operations that failed have no output and must be
+	// applied using the input alone.
+	Step func(state interface{}, op Operation) (bool, interface{})
+
+	// The function that compares two states.
+	//
+	// Default: reflect.DeepEqual.
+	Equal func(a, b interface{}) bool
+
+	// The function that splits a history into independent histories, for
+	// example by key. This greatly reduces the search space.
+	//
+	// Default: No partitioning.
+	Partition func(history []Operation) [][]Operation
+}
+
+// Check will check whether the history is linearizable with respect to the
+// model. It returns an error describing the first partition that could not
+// be linearized.
+func Check(model Model, history []Operation) error {
+	// set default equal
+	if model.Equal == nil {
+		model.Equal = reflect.DeepEqual
+	}
+
+	// partition history
+	partitions := [][]Operation{history}
+	if model.Partition != nil {
+		partitions = model.Partition(history)
+	}
+
+	// check partitions
+	for _, partition := range partitions {
+		if !check(model, partition) {
+			return fmt.Errorf("turing: lincheck: history not linearizable: %s", describe(partition))
+		}
+	}
+
+	return nil
+}
+
+const (
+	callEntry = iota
+	returnEntry
+	optionalEntry
+)
+
+type entry struct {
+	op    int
+	kind  int
+	time  time.Duration
+	match *entry
+	prev  *entry
+	next  *entry
+}
+
+func (e *entry) lift() {
+	// unlink call
+	e.prev.next = e.next
+	if e.next != nil {
+		e.next.prev = e.prev
+	}
+
+	// unlink return
+	e.match.prev.next = e.match.next
+	if e.match.next != nil {
+		e.match.next.prev = e.match.prev
+	}
+}
+
+func (e *entry) unlift() {
+	// relink return
+	e.match.prev.next = e.match
+	if e.match.next != nil {
+		e.match.next.prev = e.match
+	}
+
+	// relink call
+	e.prev.next = e
+	if e.next != nil {
+		e.next.prev = e
+	}
+}
+
+type frame struct {
+	entry *entry
+	state interface{}
+}
+
+type cached struct {
+	bits  []uint64
+	state interface{}
+}
+
+func check(model Model, history []Operation) bool {
+	// prepare entries
+	entries := make([]*entry, 0, len(history)*2)
+	for i, op := range history {
+		// skip failed reads
+		if op.Error != nil && op.Input.Effect() == 0 {
+			continue
+		}
+
+		// prepare call and return
+		call := &entry{op: i, kind: callEntry, time: op.Call}
+		ret := &entry{op: i, kind: returnEntry, time: op.Return}
+		call.match = ret
+
+		// stale reads may be placed anywhere
+		if op.Stale {
+			call.time = math.MinInt64
+			ret.time = math.MaxInt64
+		}
+
+		// failed operations may be placed anywhere after the call or omitted
+		if op.Error != nil {
+			ret.kind = optionalEntry
+			ret.time = math.MaxInt64
+		}
+
+		entries = append(entries, call, ret)
+	}
+
+	// sort entries by time and kind
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].time != entries[j].time {
+			return entries[i].time < entries[j].time
+		}
+		return entries[i].kind < entries[j].kind
+	})
+
+	// link entries
+	head := &entry{}
+	prev := head
+	for _, entry := range entries {
+		prev.next = entry
+		entry.prev = prev
+		prev = entry
+	}
+
+	// prepare search
+	state := model.Init()
+	bits := make([]uint64, (len(history)+63)/64)
+	cache := map[uint64][]cached{}
+	var stack []frame
+
+	// search linearization
+	current := head.next
+	for current != nil {
+		switch current.kind {
+		case callEntry:
+			// apply operation
+			ok, newState := model.Step(state, history[current.op])
+			if ok {
+				// set bit
+				bits[current.op/64] |= 1 << (current.op % 64)
+
+				// check cache
+				if !lookup(model, cache, bits, newState) {
+					// push frame and linearize
+					stack = append(stack, frame{entry: current, state: state})
+					state = newState
+					current.lift()
+					current = head.next
+					continue
+				}
+
+				// clear bit
+				bits[current.op/64] &^= 1 << (current.op % 64)
+			}
+
+			// try next
+			current = current.next
+		case returnEntry:
+			// check stack
+			if len(stack) == 0 {
+				return false
+			}
+
+			// pop frame and revert
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			state = top.state
+			bits[top.entry.op/64] &^= 1 << (top.entry.op % 64)
+			top.entry.unlift()
+
+			// try next
+			current = top.entry.next
+		case optionalEntry:
+			// all required operations are linearized
+			return true
+		}
+	}
+
+	return true
+}
+
+func lookup(model Model, cache map[uint64][]cached, bits []uint64, state interface{}) bool {
+	// hash bits
+	hash := fnv.New64a()
+	for _, word := range bits {
+		var buf [8]byte
+		for i := range buf {
+			buf[i] = byte(word >> (8 * i))
+		}
+		_, _ = hash.Write(buf[:])
+	}
+	key := hash.Sum64()
+
+	// check entries
+	for _, item := range cache[key] {
+		if reflect.DeepEqual(item.bits, bits) && model.Equal(item.state, state) {
+			return true
+		}
+	}
+
+	// add entry
+	cache[key] = append(cache[key], cached{
+		bits:  append([]uint64(nil), bits...),
+		state: state,
+	})
+
+	return false
+}
+
+func describe(history []Operation) string {
+	// count operations
+	var failed, stale int
+	for _, op := range history {
+		if op.Error != nil {
+			failed++
+		}
+		if op.Stale {
+			stale++
+		}
+	}
+
+	return fmt.Sprintf("%d operations (%d failed, %d stale)", len(history), failed, stale)
+}
@@ -0,0 +1,141 @@
+package lincheck
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing/stdset"
+)
+
+var register = Model{
+	Init: func() interface{} {
+		return ""
+	},
+	Step: func(state interface{}, op Operation) (bool, interface{}) {
+		switch input := op.Input.(type) {
+		case *stdset.Set:
+			return true, string(input.Value)
+		case *stdset.Get:
+			return string(op.Output.(*stdset.Get).Value) == state.(string), state
+		}
+		return false, state
+	},
+}
+
+func set(value string, call, ret int, err error) Operation {
+	op := Operation{
+		Input:  &stdset.Set{Key: []byte("foo"), Value: []byte(value)},
+		Error:  err,
+		Call:   time.Duration(call),
+		Return: time.Duration(ret),
+	}
+	if err == nil {
+		op.Output = op.Input
+	}
+	return op
+}
+
+func get(value string, call, ret int, stale bool) Operation {
+	return Operation{
+		Input:  &stdset.Get{Key: []byte("foo")},
+		Output: &stdset.Get{Key: []byte("foo"), Value: []byte(value)},
+		Stale:  stale,
+		Call:   time.Duration(call),
+		Return: time.Duration(ret),
+	}
+}
+
+func TestCheck(t *testing.T) {
+	// sequential
+	err := Check(register, []Operation{
+		set("1", 0, 1, nil),
+		get("1", 2, 3, false),
+		set("2", 4, 5, nil),
+		get("2", 6, 7, false),
+	})
+	assert.NoError(t, err)
+
+	// concurrent
+	err = Check(register, []Operation{
+		set("1", 0, 10, nil),
+		get("", 1, 2, false),
+		get("1", 3, 4, false),
+		get("1", 11, 12, false),
+	})
+	assert.NoError(t, err)
+
+	// stale read after write
+	err = Check(register, []Operation{
+		set("1", 0, 1, nil),
+		get("", 2, 3, false),
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "turing: lincheck: history not linearizable: 2 operations (0 failed, 0 stale)", err.Error())
+
+	// reads going back in time
+	err = Check(register, []Operation{
+		set("1", 0, 10, nil),
+		get("1", 1, 2, false),
+		get("", 3, 4, false),
+	})
+	assert.Error(t, err)
+
+	// serializable stale read
+	err = Check(register, []Operation{
+		set("1", 0, 1, nil),
+		get("", 2, 3, true),
+	})
+	assert.NoError(t, err)
+
+	// failed write applied
+	err = Check(register, []Operation{
+		set("1", 0, 1, errors.New("timeout")),
+		get("1", 2, 3, false),
+	})
+	assert.NoError(t, err)
+
+	// failed write omitted
+	err = Check(register, []Operation{
+		set("1", 0, 1, errors.New("timeout")),
+		get("", 2, 3, false),
+	})
+	assert.NoError(t, err)
+
+	// failed write before call
+	err = Check(register, []Operation{
+		get("1", 0, 1, false),
+		set("1", 2, 3, errors.New("timeout")),
+	})
+	assert.Error(t, err)
+}
+
+func TestCheckPartition(t *testing.T) {
+	model := register
+	model.Partition = func(history []Operation) [][]Operation {
+		var reads, writes []Operation
+		for _, op := range history {
+			if op.Input.Effect() == 0 {
+				reads = append(reads, op)
+			} else {
+				writes = append(writes, op)
+			}
+		}
+		return [][]Operation{writes, reads}
+	}
+
+	err := Check(model, []Operation{
+		set("1", 0, 1, nil),
+		get("", 2, 3, false),
+	})
+	assert.NoError(t, err)
+
+	err = Check(model, []Operation{
+		set("1", 0, 1, nil),
+		get("1", 2, 3, false),
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "turing: lincheck: history not linearizable: 1 operations (0 failed, 0 stale)", err.Error())
+}
@@ -0,0 +1,129 @@
+// Package lincheck records histories of executed instructions and checks them
+// for linearizability against a sequential model.
+//
+// Histories are recorded by executing instructions through a Recorder from
+// any number of goroutines and machines. The Check function then searches for
+// a sequential order of the recorded operations that respects their real-time
+// ordering and is accepted by the model. Stale reads only guarantee
+// serializability and are therefore placed without real-time constraints.
+// Operations that failed may or may not have taken effect and are placed
+// anywhere after their invocation or omitted.
+package lincheck
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/256dpi/turing"
+)
+
+// Operation is a recorded instruction execution.
+type Operation struct {
+	// The client that executed the operation.
+	Client int
+
+	// A copy of the instruction before it was executed.
+	Input turing.Instruction
+
+	// The instruction after it was executed. Unset if the execution failed.
+	Output turing.Instruction
+
+	// The error returned by the execution.
+	Error error
+
+	// Whether the operation has been executed as a stale read.
+	Stale bool
+
+	// The invocation and response times relative to the recorder start.
+	Call   time.Duration
+	Return time.Duration
+}
+
+// Recorder records the operations executed by multiple clients.
+type Recorder struct {
+	start time.Time
+	mutex sync.Mutex
+	ops   []Operation
+}
+
+// NewRecorder will create and return a new recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		start: time.Now(),
+	}
+}
+
+// Execute will execute the instruction on the specified machine and record
+// the operation for the specified client.
+func (r *Recorder) Execute(client int, machine *turing.Machine, ins turing.Instruction, opts ...turing.Options) error {
+	// copy input
+	input, err := clone(ins)
+	if err != nil {
+		return err
+	}
+
+	// check stale
+	stale := ins.Effect() == 0 && len(opts) > 0 && opts[0].StaleRead
+
+	// execute instruction
+	call := time.Since(r.start)
+	err = machine.Execute(ins, opts...)
+	ret := time.Since(r.start)
+
+	// prepare operation
+	op := Operation{
+		Client: client,
+		Input:  input,
+		Error:  err,
+		Stale:  stale,
+		Call:   call,
+		Return: ret,
+	}
+
+	// set output
+	if err == nil {
+		op.Output = ins
+	}
+
+	// acquire mutex
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// add operation
+	r.ops = append(r.ops, op)
+
+	return err
+}
+
+// History returns a copy of the recorded operations.
+func (r *Recorder) History() []Operation {
+	// acquire mutex
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Operation(nil), r.ops...)
+}
+
+func clone(ins turing.Instruction) (turing.Instruction, error) {
+	// encode instruction
+	bytes, ref, err := ins.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	// copy and release bytes
+	bytes = turing.Clone(bytes)
+	if ref != nil {
+		ref.Release()
+	}
+
+	// decode into new instruction
+	input := reflect.New(reflect.TypeOf(ins).Elem()).Interface().(turing.Instruction)
+	err = input.Decode(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
@@ -0,0 +1,107 @@
+package lincheck
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/stdset"
+	"github.com/256dpi/turing/testcluster"
+)
+
+func TestMain(m *testing.M) {
+	// disable logging
+	turing.SetLogger(nil)
+
+	// run tests
+	os.Exit(m.Run())
+}
+
+func TestRecorder(t *testing.T) {
+	machine := turing.Test(&stdset.Set{}, &stdset.Get{})
+	defer machine.Stop()
+
+	recorder := NewRecorder()
+
+	set := &stdset.Set{Key: []byte("foo"), Value: []byte("1")}
+	err := recorder.Execute(1, machine, set)
+	assert.NoError(t, err)
+
+	get := &stdset.Get{Key: []byte("foo")}
+	err = recorder.Execute(2, machine, get, turing.Options{StaleRead: true})
+	assert.NoError(t, err)
+
+	history := recorder.History()
+	assert.Len(t, history, 2)
+	assert.Equal(t, 1, history[0].Client)
+	assert.Equal(t, set, history[0].Input)
+	assert.Equal(t, set, history[0].Output)
+	assert.False(t, history[0].Stale)
+	assert.True(t, history[0].Call <= history[0].Return)
+	assert.Equal(t, 2, history[1].Client)
+	assert.Equal(t, []byte("foo"), history[1].Input.(*stdset.Get).Key)
+	assert.Empty(t, history[1].Input.(*stdset.Get).Value)
+	assert.Equal(t, []byte("1"), history[1].Output.(*stdset.Get).Value)
+	assert.True(t, history[1].Stale)
+
+	assert.NoError(t, Check(register, history))
+}
+
+func TestRecorderCluster(t *testing.T) {
+	cluster, err := testcluster.Start(testcluster.Options{
+		Instructions: []turing.Instruction{&stdset.Set{}, &stdset.Get{}},
+		Configure: func(config *turing.Config) {
+			config.ProposalTimeout = time.Second
+			config.LinearReadTimeout = time.Second
+		},
+	})
+	assert.NoError(t, err)
+	defer cluster.Stop()
+
+	leader, err := cluster.WaitForLeader(10 * time.Second)
+	assert.NoError(t, err)
+
+	recorder := NewRecorder()
+
+	var wg sync.WaitGroup
+	for _, member := range cluster.Members() {
+		machine := cluster.Machine(member.ID)
+		for i := 0; i < 2; i++ {
+			client := int(member.ID)*10 + i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					var err error
+					if j%2 == 0 {
+						err = recorder.Execute(client, machine, &stdset.Set{
+							Key:   []byte("foo"),
+							Value: []byte(strconv.Itoa(client*100 + j)),
+						})
+					} else {
+						err = recorder.Execute(client, machine, &stdset.Get{
+							Key: []byte("foo"),
+						}, turing.Options{StaleRead: j%3 == 0})
+					}
+					if err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	cluster.Kill(leader)
+
+	wg.Wait()
+
+	history := recorder.History()
+	assert.NotEmpty(t, history)
+	assert.NoError(t, Check(register, history))
+}
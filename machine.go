@@ -1,7 +1,12 @@
 package turing
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lni/dragonboat/v3/logger"
 )
 
 // Options define options used during instruction execution.
@@ -21,6 +26,10 @@ type Machine struct {
 	manager     *manager
 	coordinator *coordinator
 	controller  *controller
+	limiter     *limiter
+	done        chan struct{}
+	stopped     sync.Once
+	group       sync.WaitGroup
 }
 
 // Start will create a new machine using the specified configuration.
@@ -58,6 +67,9 @@ func Start(config Config) (*Machine, error) {
 		}
 	}
 
+	// prepare done
+	done := make(chan struct{})
+
 	// create machine
 	m := &Machine{
 		config:      config,
@@ -65,6 +77,14 @@ func Start(config Config) (*Machine, error) {
 		manager:     manager,
 		coordinator: coordinator,
 		controller:  controller,
+		limiter:     newLimiter(config.InstructionLimits, config.FailFast, done),
+		done:        done,
+	}
+
+	// run sweeper if enabled
+	if config.SweepInterval > 0 {
+		m.group.Add(1)
+		go m.sweeper()
 	}
 
 	return m, nil
@@ -116,6 +136,33 @@ func (m *Machine) execute(ins Instruction, fn func(error), opts ...Options) erro
 		return fmt.Errorf("turing: missing instruction: %s", desc.Name)
 	}
 
+	// acquire slot
+	release, err := m.limiter.acquire(desc.Name)
+	if err != nil {
+		return err
+	}
+
+	// release slot once executed if asynchronous
+	if fn != nil {
+		callback := fn
+		fn = func(err error) {
+			release()
+			callback(err)
+		}
+	}
+
+	// dispatch instruction
+	err = m.dispatch(ins, fn, effect, options)
+
+	// release slot if synchronous or not queued
+	if fn == nil || err != nil {
+		release()
+	}
+
+	return err
+}
+
+func (m *Machine) dispatch(ins Instruction, fn func(error), effect int, options Options) error {
 	// execute directly if standalone
 	if m.config.Standalone {
 		// perform lookup
@@ -129,7 +176,7 @@ func (m *Machine) execute(ins Instruction, fn func(error), opts ...Options) erro
 
 	// immediately perform read
 	if effect == 0 {
-		err = m.coordinator.lookup(ins, fn, options)
+		err := m.coordinator.lookup(ins, fn, options)
 		if err != nil {
 			return err
 		}
@@ -138,7 +185,7 @@ func (m *Machine) execute(ins Instruction, fn func(error), opts ...Options) erro
 	}
 
 	// perform update
-	err = m.coordinator.update(ins, fn)
+	err := m.coordinator.update(ins, fn)
 	if err != nil {
 		return err
 	}
@@ -146,6 +193,115 @@ func (m *Machine) execute(ins Instruction, fn func(error), opts ...Options) erro
 	return nil
 }
 
+func (m *Machine) sweeper() {
+	// ensure done
+	defer m.group.Done()
+
+	// get logger
+	lgr := logger.GetLogger("turing")
+
+	// prepare ticker
+	ticker := time.NewTicker(m.config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		// await tick
+		select {
+		case <-ticker.C:
+		case <-m.done:
+			return
+		}
+
+		// skip if not leader
+		if m.coordinator != nil && !m.coordinator.leader() {
+			continue
+		}
+
+		// check for expired keys
+		probe := &expiryProbe{}
+		err := m.Execute(probe, Options{StaleRead: true})
+		if err != nil {
+			lgr.Warningf("expiry probe failed: %s", err.Error())
+			continue
+		} else if !probe.Found {
+			continue
+		}
+
+		// sweep expired keys
+		err = m.Execute(&expirySweep{})
+		if err != nil {
+			lgr.Warningf("expiry sweep failed: %s", err.Error())
+		}
+	}
+}
+
+// Checksum will compute a hash of all user keys with the provided prefix. If
+// the machine is replicated, the keys are hashed in multiple steps to not
+// block other writes. As writes between the steps are included by later steps,
+// the hash is not a point-in-time hash of the keys at the returned index.
+// However, all replicas apply the steps at the same indexes and will compare
+// their hash with the returned hash and report mismatches using the
+// "turing_checksums" metric and the log.
+func (m *Machine) Checksum(prefix []byte) (uint64, uint64, error) {
+	// compute checksum
+	sum := &checksum{Prefix: prefix}
+	for {
+		err := m.Execute(sum)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		// check completion
+		if sum.Done {
+			break
+		}
+	}
+
+	// check index
+	if sum.Index == 0 && !m.config.Standalone {
+		return 0, 0, fmt.Errorf("turing: checksum expired")
+	}
+
+	// skip verification if not replicated
+	if sum.Index == 0 {
+		return sum.Index, sum.Hash, nil
+	}
+
+	// verify checksum
+	err := m.Execute(&checksumVerify{
+		Prefix: prefix,
+		Index:  sum.Index,
+		Hash:   sum.Hash,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return sum.Index, sum.Hash, nil
+}
+
+// Instructions will return the names of the configured instructions.
+func (m *Machine) Instructions() []string {
+	// copy names
+	names := make([]string, len(m.registry.names))
+	copy(names, m.registry.names)
+
+	return names
+}
+
+// Build will build and return a new instance of the configured instruction
+// with the specified name.
+func (m *Machine) Build(name string) (Instruction, error) {
+	// check name
+	for _, n := range m.registry.names {
+		if n == name {
+			return m.registry.build(name)
+		}
+	}
+
+	return nil, fmt.Errorf("turing: unknown instruction: %s", name)
+}
+
 // Subscribe will subscribe the provided observer.
 func (m *Machine) Subscribe(observer Observer) {
 	m.manager.subscribe(observer)
@@ -166,15 +322,49 @@ func (m *Machine) Status() Status {
 	return Status{}
 }
 
-// Stop will stop the machine.
+// Shutdown will gracefully stop the machine. New instructions are rejected
+// with ErrStopped while queued instructions are still executed. If the
+// context is cancelled before they complete, the remaining instructions fail
+// with ErrStopped. If the node is the leader, leadership is transferred to
+// another member before the node is stopped. Finally, observers are flushed
+// and the database is closed.
+func (m *Machine) Shutdown(ctx context.Context) error {
+	return m.stop(ctx, true)
+}
+
+// Stop will stop the machine immediately. Queued instructions fail with
+// ErrStopped.
 func (m *Machine) Stop() {
-	// close coordinator
-	if m.coordinator != nil {
-		m.coordinator.close()
-	}
+	// prepare cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
 
-	// close controller
-	if m.controller != nil {
-		_ = m.controller.close()
-	}
+	// stop machine
+	_ = m.stop(ctx, false)
+}
+
+func (m *Machine) stop(ctx context.Context, graceful bool) error {
+	var err error
+	m.stopped.Do(func() {
+		// stop sweeper
+		close(m.done)
+		m.group.Wait()
+
+		// close coordinator
+		if m.coordinator != nil {
+			err = m.coordinator.close(ctx, graceful)
+		}
+
+		// close controller
+		if m.controller != nil {
+			if e := m.controller.close(ctx); e != nil && err == nil {
+				err = e
+			}
+		}
+
+		// flush observers
+		m.manager.flush()
+	})
+
+	return err
 }
@@ -0,0 +1,136 @@
+package turing
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMachineBuild(t *testing.T) {
+	machine := Test(&randomizer{})
+	defer machine.Stop()
+
+	assert.Equal(t, []string{"randomizer"}, machine.Instructions())
+
+	ins, err := machine.Build("randomizer")
+	assert.NoError(t, err)
+	assert.Equal(t, &randomizer{}, ins)
+
+	ins, err = machine.Build("turing/ExpirySweep")
+	assert.Error(t, err)
+	assert.Nil(t, ins)
+}
+
+type flushingObserver struct {
+	mutex     sync.Mutex
+	processed int
+	flushed   int
+}
+
+func (o *flushingObserver) Init() {}
+
+func (o *flushingObserver) Process(Instruction) bool {
+	o.mutex.Lock()
+	o.processed++
+	o.mutex.Unlock()
+	return true
+}
+
+func (o *flushingObserver) Flush() {
+	o.mutex.Lock()
+	o.flushed++
+	o.mutex.Unlock()
+}
+
+func TestMachineShutdown(t *testing.T) {
+	machine, err := Start(Config{
+		Instructions:    []Instruction{&sleeper{}},
+		Standalone:      true,
+		UpdateBatchSize: 1,
+		QueueSize:       10,
+	})
+	assert.NoError(t, err)
+
+	observer := &flushingObserver{}
+	machine.Subscribe(observer)
+
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		err = machine.ExecuteAsync(&sleeper{Duration: time.Millisecond}, func(err error) {
+			errs <- err
+		})
+		assert.NoError(t, err)
+	}
+
+	err = machine.Shutdown(context.Background())
+	assert.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, <-errs)
+	}
+
+	assert.Equal(t, 5, observer.processed)
+	assert.Equal(t, 1, observer.flushed)
+
+	err = machine.Execute(&sleeper{})
+	assert.Equal(t, ErrStopped, err)
+
+	machine.Stop()
+	assert.Equal(t, 1, observer.flushed)
+}
+
+func TestMachineShutdownTimeout(t *testing.T) {
+	machine, err := Start(Config{
+		Instructions:    []Instruction{&sleeper{}},
+		Standalone:      true,
+		UpdateBatchSize: 1,
+		QueueSize:       10,
+	})
+	assert.NoError(t, err)
+
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		err = machine.ExecuteAsync(&sleeper{Duration: 10 * time.Millisecond}, func(err error) {
+			errs <- err
+		})
+		assert.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Millisecond)
+	defer cancel()
+
+	err = machine.Shutdown(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	var executed, stopped int
+	for i := 0; i < 10; i++ {
+		switch <-errs {
+		case nil:
+			executed++
+		case ErrStopped:
+			stopped++
+		}
+	}
+	assert.True(t, executed > 0, executed)
+	assert.True(t, stopped > 0, stopped)
+	assert.Equal(t, 10, executed+stopped)
+}
+
+func TestMachineStop(t *testing.T) {
+	machine, err := Start(Config{
+		Instructions: []Instruction{&sleeper{}},
+		Standalone:   true,
+	})
+	assert.NoError(t, err)
+
+	machine.Stop()
+
+	err = machine.Execute(&sleeper{})
+	assert.Equal(t, ErrStopped, err)
+
+	err = machine.Shutdown(context.Background())
+	assert.NoError(t, err)
+}
@@ -46,6 +46,16 @@ func (m *manager) process(ins Instruction) {
 	}
 }
 
+func (m *manager) flush() {
+	// call flush on all flushing observers
+	m.observers.Range(func(_, v interface{}) bool {
+		if flusher, ok := v.(Flusher); ok {
+			flusher.Flush()
+		}
+		return true
+	})
+}
+
 func (m *manager) unsubscribe(observer Observer) {
 	// remove observer
 	m.observers.Delete(observer)
@@ -1,6 +1,7 @@
 package turing
 
 import (
+	"fmt"
 	"io"
 	"sync"
 
@@ -142,7 +143,7 @@ func (m *merger) Finish(includesBase bool) ([]byte, io.Closer, error) {
 
 		return res, m, nil
 	default:
-		panic("unexpected condition")
+		return nil, nil, fmt.Errorf("turing: merger finish: unexpected cell type: %d", m.cells[0].Type)
 	}
 }
 
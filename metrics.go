@@ -27,11 +27,59 @@ var operatorMetrics = prometheus.NewCounterVec(prometheus.CounterOpts{
 	Help:      "Operator execution counter.",
 }, []string{"name"})
 
+var checksumMetrics = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "turing",
+	Subsystem: "",
+	Name:      "checksums",
+	Help:      "Checksum verification counter.",
+}, []string{"result"})
+
+var effectMetrics = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "turing",
+	Subsystem: "",
+	Name:      "effects",
+	Help:      "Declared and actual instruction effect counter.",
+}, []string{"name", "type"})
+
+var effectMisreports = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "turing",
+	Subsystem: "",
+	Name:      "effect_misreports",
+	Help:      "Counter of instructions exceeding their declared effect.",
+}, []string{"name"})
+
+var queueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "turing",
+	Subsystem: "",
+	Name:      "queue_depth",
+	Help:      "The number of queued instructions.",
+}, []string{"queue"})
+
+var queueWait = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+	Namespace: "turing",
+	Subsystem: "",
+	Name:      "queue_wait",
+	Help:      "Instruction queue wait timings in milliseconds.",
+}, []string{"queue"})
+
+var overloadMetrics = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "turing",
+	Subsystem: "",
+	Name:      "overloads",
+	Help:      "Counter of instructions rejected due to overload.",
+}, []string{"name"})
+
 func init() {
 	// register metrics
 	prometheus.MustRegister(systemMetrics)
 	prometheus.MustRegister(instructionMetrics)
 	prometheus.MustRegister(operatorMetrics)
+	prometheus.MustRegister(checksumMetrics)
+	prometheus.MustRegister(effectMetrics)
+	prometheus.MustRegister(effectMisreports)
+	prometheus.MustRegister(queueDepth)
+	prometheus.MustRegister(queueWait)
+	prometheus.MustRegister(overloadMetrics)
 }
 
 type timer struct {
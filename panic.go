@@ -0,0 +1,80 @@
+package turing
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/256dpi/fpack"
+	"github.com/lni/dragonboat/v3/logger"
+)
+
+// PanicError is returned for instructions that panicked during execution. The
+// changes of the instruction are discarded and the error is reported
+// consistently on all replicas. The stack trace is only logged. Unbounded
+// instructions only lose the changes of the step that panicked, as the steps
+// that already reached the effect limit have been committed.
+type PanicError struct {
+	// The name of the instruction or operator that panicked.
+	Name string
+
+	// The formatted panic value.
+	Value string
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("turing: panic in %s: %s", e.Name, e.Value)
+}
+
+// Encode will encode the error.
+func (e *PanicError) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarString(e.Name)
+		enc.VarString(e.Value)
+
+		return nil
+	})
+}
+
+// Decode will decode the error.
+func (e *PanicError) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("turing: decode panic error: invalid version")
+		}
+
+		// decode body
+		e.Name = dec.VarString(true)
+		e.Value = dec.VarString(true)
+
+		return nil
+	})
+}
+
+// panicOperation is the operation name used to return panic errors.
+const panicOperation = "turing/Panic"
+
+func recovered(name string, val interface{}) *PanicError {
+	// log panic with stack
+	logger.GetLogger("turing").Errorf("panic in %s: %v\n%s", name, val, debug.Stack())
+
+	return &PanicError{
+		Name:  name,
+		Value: fmt.Sprint(val),
+	}
+}
+
+func asPanic(err error) (*PanicError, bool) {
+	var perr *PanicError
+	if errors.As(err, &perr) {
+		return perr, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,363 @@
+package turing
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing/tape"
+)
+
+var panicOperator = &Operator{
+	Name: "panicOperator",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, Ref, error) {
+		value = Clone(value)
+		for _, op := range ops {
+			if string(op) == "bad" {
+				panic("bad operand")
+			}
+			value = append(value, op...)
+		}
+		return value, noopRef, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, Ref, error) {
+		var value []byte
+		for _, op := range ops {
+			if string(op) == "bad" {
+				panic("bad operand")
+			}
+			value = append(value, op...)
+		}
+		return value, noopRef, nil
+	},
+}
+
+type panicker struct {
+	Mode   string
+	Key    []byte
+	Value  []byte
+	Result []byte
+}
+
+var panickerDesc = &Description{
+	Name:      "panicker",
+	Operators: []*Operator{panicOperator},
+}
+
+func (p *panicker) Describe() *Description {
+	return panickerDesc
+}
+
+func (p *panicker) Effect() int {
+	switch p.Mode {
+	case "get":
+		return 0
+	case "steps":
+		return UnboundedEffect
+	}
+
+	return 1
+}
+
+func (p *panicker) Execute(mem Memory, _ Cache) error {
+	switch p.Mode {
+	case "set":
+		return mem.Set(p.Key, p.Value)
+	case "panic":
+		_ = mem.Set(p.Key, p.Value)
+		panic("boom")
+	case "merge":
+		return mem.Merge(p.Key, p.Value, panicOperator)
+	case "steps":
+		// panic in second step
+		if mem.Continuation() != nil {
+			_ = mem.Set(p.Key, p.Value)
+			panic("boom")
+		}
+
+		// set keys until the effect is maxed
+		for i := 0; ; i++ {
+			err := mem.Set(append(Clone(p.Key), byte('0'+i)), p.Value)
+			if err == ErrMaxEffect {
+				_ = mem.Continue([]byte("next"))
+				return err
+			} else if err != nil {
+				return err
+			}
+		}
+	case "get":
+		return mem.Use(p.Key, func(value []byte) error {
+			p.Result = Clone(value)
+			return nil
+		})
+	}
+
+	return nil
+}
+
+func (p *panicker) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		enc.VarString(p.Mode)
+		enc.VarBytes(p.Key)
+		enc.VarBytes(p.Value)
+		enc.VarBytes(p.Result)
+		return nil
+	})
+}
+
+func (p *panicker) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		p.Mode = dec.VarString(true)
+		p.Key = dec.VarBytes(true)
+		p.Value = dec.VarBytes(true)
+		p.Result = dec.VarBytes(true)
+		return nil
+	})
+}
+
+func TestPanicUpdate(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&panicker{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	errs := make([]error, 3)
+	err = db.update([]Instruction{
+		&panicker{Mode: "set", Key: []byte("foo"), Value: []byte("1")},
+		&panicker{Mode: "panic", Key: []byte("bar"), Value: []byte("2")},
+		&panicker{Mode: "set", Key: []byte("baz"), Value: []byte("3")},
+	}, errs, 1, now, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, errs[0])
+	assert.Equal(t, &PanicError{Name: "panicker", Value: "boom"}, errs[1])
+	assert.Nil(t, errs[2])
+	assert.Equal(t, 2, countKeys(db, userPrefix))
+	assert.Equal(t, uint64(1), db.state.Index)
+
+	err = db.update([]Instruction{
+		&panicker{Mode: "panic", Key: []byte("qux"), Value: []byte("4")},
+	}, nil, 2, now, 0)
+	assert.Equal(t, &PanicError{Name: "panicker", Value: "boom"}, err)
+
+	assert.NoError(t, db.close())
+}
+
+func TestPanicUnbounded(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&panicker{}},
+		Standalone:   true,
+		MaxEffect:    5,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	errs := make([]error, 2)
+	err = db.update([]Instruction{
+		&panicker{Mode: "steps", Key: []byte("foo"), Value: []byte("1")},
+		&panicker{Mode: "set", Key: []byte("bar"), Value: []byte("2")},
+	}, errs, 1, now, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, &PanicError{Name: "panicker", Value: "boom"}, errs[0])
+	assert.Nil(t, errs[1])
+
+	// the first step has been committed
+	assert.Equal(t, 5, countKeys(db, append(Clone(userPrefix), "foo"...)))
+	assert.Equal(t, 1, countKeys(db, append(Clone(userPrefix), "bar"...)))
+	assert.Equal(t, 0, countKeys(db, continuationKey))
+
+	assert.NoError(t, db.close())
+}
+
+func TestPanicMachine(t *testing.T) {
+	machine := Test(&panicker{})
+	defer machine.Stop()
+
+	err := machine.Execute(&panicker{Mode: "panic", Key: []byte("foo"), Value: []byte("1")})
+	assert.Equal(t, &PanicError{Name: "panicker", Value: "boom"}, err)
+
+	err = machine.Execute(&panicker{Mode: "merge", Key: []byte("foo"), Value: []byte("a")})
+	assert.NoError(t, err)
+
+	get := &panicker{Mode: "get", Key: []byte("foo")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a"), get.Result)
+
+	err = machine.Execute(&panicker{Mode: "merge", Key: []byte("foo"), Value: []byte("bad")})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&panicker{Mode: "merge", Key: []byte("foo"), Value: []byte("c")})
+	assert.NoError(t, err)
+
+	// dropped
+	get = &panicker{Mode: "get", Key: []byte("foo")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ac"), get.Result)
+
+	// overwrite
+	err = machine.Execute(&panicker{Mode: "set", Key: []byte("foo"), Value: []byte("b")})
+	assert.NoError(t, err)
+
+	get = &panicker{Mode: "get", Key: []byte("foo")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("b"), get.Result)
+}
+
+func TestPanicCompaction(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&panicker{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Now().UnixNano()
+
+	list := []Instruction{
+		&panicker{Mode: "set", Key: []byte("foo"), Value: []byte("x")},
+	}
+	for _, value := range []string{"a", "bad", "b"} {
+		list = append(list,
+			&panicker{Mode: "merge", Key: []byte("foo"), Value: []byte(value)},
+			&panicker{Mode: "merge", Key: []byte("bar"), Value: []byte(value)},
+		)
+	}
+
+	for i, ins := range list {
+		err = db.update([]Instruction{ins}, nil, uint64(i+1), now, 0)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, db.pebble.Flush())
+	assert.NoError(t, db.pebble.Compact(userPrefix, []byte("$")))
+
+	for key, value := range map[string]string{"foo": "xab", "bar": "ab"} {
+		get := &panicker{Mode: "get", Key: []byte(key)}
+		err = db.lookup([]Instruction{get}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(value), get.Result)
+	}
+
+	assert.NoError(t, db.close())
+}
+
+func TestPanicCombine(t *testing.T) {
+	cells := []tape.Cell{
+		{
+			Type: tape.StackCell,
+			Value: mustEncodeStack(tape.Stack{
+				Operands: []tape.Operand{
+					{Name: "panicOperator", Value: []byte("a")},
+					{Name: "panicOperator", Value: []byte("bad")},
+				},
+			}),
+		},
+	}
+
+	registry := &registry{
+		ops: map[string]*Operator{
+			"panicOperator": panicOperator,
+		},
+	}
+
+	computer := newComputer(registry)
+	result, ref, err := computer.combine(cells)
+	assert.NoError(t, err)
+	assert.Equal(t, cells[0], result)
+	ref.Release()
+}
+
+func TestPanicErrorEncoding(t *testing.T) {
+	err1 := &PanicError{Name: "foo", Value: "bar"}
+	bytes, _, err := err1.Encode()
+	assert.NoError(t, err)
+
+	var err2 PanicError
+	err = err2.Decode(bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, *err1, err2)
+	assert.Equal(t, "turing: panic in foo: bar", err2.Error())
+}
+
+func TestPanicReplicated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "turing")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	machine, err := Start(Config{
+		ID:            1,
+		Members:       []Member{{ID: 1, Host: "127.0.0.1", Port: 42003}},
+		Directory:     dir,
+		Instructions:  []Instruction{&panicker{}},
+		RoundTripTime: time.Millisecond,
+	})
+	assert.NoError(t, err)
+	defer machine.Stop()
+
+	for machine.Status().Role != RoleLeader {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	err = machine.Execute(&panicker{Mode: "panic", Key: []byte("foo"), Value: []byte("1")})
+	assert.Equal(t, &PanicError{Name: "panicker", Value: "boom"}, err)
+
+	err = machine.Execute(&panicker{Mode: "set", Key: []byte("foo"), Value: []byte("2")})
+	assert.NoError(t, err)
+
+	get := &panicker{Mode: "get", Key: []byte("foo")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("2"), get.Result)
+
+	errs := make([]chan error, 3)
+	for i, ins := range []*panicker{
+		{Mode: "set", Key: []byte("a"), Value: []byte("1")},
+		{Mode: "panic", Key: []byte("b"), Value: []byte("2")},
+		{Mode: "set", Key: []byte("c"), Value: []byte("3")},
+	} {
+		errs[i] = make(chan error, 1)
+		ch := errs[i]
+		err = machine.ExecuteAsync(ins, func(err error) {
+			ch <- err
+		})
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, <-errs[0])
+	assert.Equal(t, &PanicError{Name: "panicker", Value: "boom"}, <-errs[1])
+	assert.NoError(t, <-errs[2])
+
+	for key, value := range map[string][]byte{"a": []byte("1"), "b": nil, "c": []byte("3")} {
+		get = &panicker{Mode: "get", Key: []byte(key)}
+		err = machine.Execute(get)
+		assert.NoError(t, err)
+		assert.Equal(t, value, get.Result, key)
+	}
+}
@@ -5,9 +5,17 @@ import (
 	"reflect"
 )
 
+var builtins = []Instruction{
+	&expiryProbe{},
+	&expirySweep{},
+	&checksum{},
+	&checksumVerify{},
+}
+
 type registry struct {
-	ins map[string]Instruction
-	ops map[string]*Operator
+	ins   map[string]Instruction
+	ops   map[string]*Operator
+	names []string
 }
 
 func buildRegistry(config Config) (*registry, error) {
@@ -17,18 +25,31 @@ func buildRegistry(config Config) (*registry, error) {
 		ops: map[string]*Operator{},
 	}
 
+	// prepare instructions
+	list := make([]Instruction, 0, len(config.Instructions)+len(builtins))
+	list = append(list, config.Instructions...)
+	list = append(list, builtins...)
+
 	// add instructions
-	for _, ins := range config.Instructions {
+	for i, ins := range list {
 		// get description
 		desc := ins.Describe()
 
+		// add name of configured instructions
+		if i < len(config.Instructions) {
+			reg.names = append(reg.names, desc.Name)
+		}
+
 		// check existence
 		if reg.ins[desc.Name] != nil {
 			return nil, fmt.Errorf("turing: build registry: duplicate instruction: %s", desc.Name)
 		}
 
-		// set observer
+		// set observer and counters
 		desc.observer = instructionMetrics.WithLabelValues(desc.Name)
+		desc.declared = effectMetrics.WithLabelValues(desc.Name, "declared")
+		desc.actual = effectMetrics.WithLabelValues(desc.Name, "actual")
+		desc.misreports = effectMisreports.WithLabelValues(desc.Name)
 
 		// store instruction
 		reg.ins[desc.Name] = ins
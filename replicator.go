@@ -1,9 +1,11 @@
 package turing
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/cockroachdb/pebble"
+	"github.com/lni/dragonboat/v3/logger"
 	"github.com/lni/dragonboat/v3/statemachine"
 
 	"github.com/256dpi/turing/wire"
@@ -15,16 +17,23 @@ type replicator struct {
 	manager      *manager
 	database     *database
 	instructions []Instruction
+	errors       []error
 	operations   []wire.Operation
 	references   []Ref
 }
 
+type lookupRequest struct {
+	list []Instruction
+	errs []error
+}
+
 func newReplicator(config Config, registry *registry, manager *manager) *replicator {
 	return &replicator{
 		config:       config,
 		registry:     registry,
 		manager:      manager,
 		instructions: make([]Instruction, config.ProposalBatchSize),
+		errors:       make([]error, config.ProposalBatchSize),
 		operations:   make([]wire.Operation, config.ProposalBatchSize),
 		references:   make([]Ref, config.ProposalBatchSize),
 	}
@@ -50,101 +59,175 @@ func (r *replicator) Update(entries []statemachine.Entry) ([]statemachine.Entry,
 	timer := observe(replicatorUpdate)
 	defer timer.finish()
 
+	// track current entry
+	var current uint64
+
+	// log crashing entry
+	defer func() {
+		if val := recover(); val != nil {
+			logger.GetLogger("turing").Errorf("replicator update: entry %d: panic: %v", current, val)
+			panic(val)
+		}
+	}()
+
 	// handle entries
 	for i, entry := range entries {
-		// reset lists
-		instructions := r.instructions[:0]
-		operations := r.operations[:0]
-		references := r.references[:0]
-
-		// decode command
-		err := wire.WalkCommand(entry.Cmd, func(i int, op wire.Operation) (bool, error) {
-			// build instruction
-			ins, err := r.registry.build(op.Name)
-			if err != nil {
-				return false, err
-			}
+		// set current
+		current = entry.Index
 
-			// decode instruction
-			err = ins.Decode(op.Code)
-			if err != nil {
-				return false, err
-			}
+		// handle entry
+		err := r.update(&entries[i])
+		if err != nil {
+			return nil, fmt.Errorf("turing: replicator update: entry %d: %w", entry.Index, err)
+		}
+	}
+
+	return entries, nil
+}
 
-			// add instruction
-			instructions = append(instructions, ins)
+func (r *replicator) update(entry *statemachine.Entry) error {
+	// reset lists
+	instructions := r.instructions[:0]
+	operations := r.operations[:0]
+	references := r.references[:0]
 
-			return true, nil
-		})
+	// decode header
+	var cmd wire.Command
+	err := wire.DecodeHeader(entry.Cmd, &cmd)
+	if err != nil {
+		return err
+	}
+
+	// decode operations
+	err = wire.WalkCommand(entry.Cmd, func(i int, op wire.Operation) (bool, error) {
+		// build instruction
+		ins, err := r.registry.build(op.Name)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
 
-		// execute instructions
-		err = r.database.update(instructions, entry.Index)
+		// decode instruction
+		err = ins.Decode(op.Code)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
 
-		// encode operations
-		for _, ins := range instructions {
-			// append empty operation when no result
-			if ins.Describe().NoResult {
-				operations = append(operations, wire.Operation{
-					Name: ins.Describe().Name,
-				})
+		// add instruction
+		instructions = append(instructions, ins)
 
-				continue
-			}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// prepare errors
+	if cap(r.errors) < len(instructions) {
+		r.errors = make([]error, len(instructions))
+	}
+	errs := r.errors[:len(instructions)]
+	for i := range errs {
+		errs[i] = nil
+	}
+
+	// execute instructions
+	err = r.database.update(instructions, errs, entry.Index, cmd.Time, cmd.Seed)
+	if err != nil {
+		return err
+	}
 
-			// encode instruction
-			bytes, ref, err := ins.Encode()
+	// encode operations
+	for i, ins := range instructions {
+		// append panic operation when failed
+		if perr, ok := errs[i].(*PanicError); ok {
+			bytes, ref, err := perr.Encode()
 			if err != nil {
-				return nil, err
+				return err
 			}
 
-			// set append operation
+			// append operation and reference
 			operations = append(operations, wire.Operation{
-				Name: ins.Describe().Name,
+				Name: panicOperation,
 				Code: bytes,
 			})
+			references = append(references, ref)
 
-			// append reference
-			if ref != nil {
-				references = append(references, ref)
-			}
+			continue
+		}
 
-			// recycle instruction if possible
-			recycler := ins.Describe().Recycler
-			if recycler != nil {
-				recycler(ins)
+		// append effect operation when rejected
+		if eerr, ok := errs[i].(*EffectError); ok {
+			bytes, ref, err := eerr.Encode()
+			if err != nil {
+				return err
 			}
-		}
 
-		// prepare command
-		cmd := wire.Command{
-			Operations: operations,
+			// append operation and reference
+			operations = append(operations, wire.Operation{
+				Name: effectOperation,
+				Code: bytes,
+			})
+			references = append(references, ref)
+
+			continue
 		}
 
-		// TODO: Borrow slice.
-		//  Improve dragonboat to provide a release mechanism
+		// append empty operation when no result
+		if ins.Describe().NoResult {
+			operations = append(operations, wire.Operation{
+				Name: ins.Describe().Name,
+			})
+
+			continue
+		}
 
-		// encode command
-		bytes, _, err := cmd.Encode(false)
+		// encode instruction
+		bytes, ref, err := ins.Encode()
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		// release references
-		for _, ref := range references {
-			ref.Release()
+		// set append operation
+		operations = append(operations, wire.Operation{
+			Name: ins.Describe().Name,
+			Code: bytes,
+		})
+
+		// append reference
+		if ref != nil {
+			references = append(references, ref)
 		}
 
-		// set result
-		entries[i].Result.Data = bytes
+		// recycle instruction if possible
+		recycler := ins.Describe().Recycler
+		if recycler != nil {
+			recycler(ins)
+		}
 	}
 
-	return entries, nil
+	// prepare result
+	result := wire.Command{
+		Operations: operations,
+	}
+
+	// TODO: Borrow slice.
+	//  Improve dragonboat to provide a release mechanism
+
+	// encode command
+	bytes, _, err := result.Encode(false)
+	if err != nil {
+		return err
+	}
+
+	// release references
+	for _, ref := range references {
+		ref.Release()
+	}
+
+	// set result
+	entry.Result.Data = bytes
+
+	return nil
 }
 
 func (r *replicator) Sync() error {
@@ -158,11 +241,11 @@ func (r *replicator) Lookup(data interface{}) (interface{}, error) {
 	timer := observe(replicatorLookup)
 	defer timer.finish()
 
-	// get instructions
-	list := data.([]Instruction)
+	// get request
+	req := data.(lookupRequest)
 
 	// perform lookup
-	err := r.database.lookup(list)
+	err := r.database.lookup(req.list, req.errs)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,648 @@
+package resp
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/256dpi/turing/stdset"
+	"github.com/256dpi/turing/stdset/hash"
+	"github.com/256dpi/turing/stdset/zset"
+)
+
+type command struct {
+	// The number of arguments including the command name. A negative number
+	// specifies a minimum.
+	arity int
+
+	// The handler that writes the reply.
+	handler func(c *conn, args [][]byte)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"ping":          {-1, ping},
+		"echo":          {2, echo},
+		"hello":         {-1, hello},
+		"quit":          {1, quit},
+		"select":        {2, selectDB},
+		"command":       {-1, commandInfo},
+		"client":        {-2, client},
+		"get":           {2, get},
+		"set":           {-3, set},
+		"getset":        {3, getSet},
+		"del":           {-2, del},
+		"exists":        {-2, exists},
+		"mget":          {-2, mget},
+		"incr":          {2, incrBy(1)},
+		"decr":          {2, incrBy(-1)},
+		"incrby":        {3, incrBy(1)},
+		"decrby":        {3, incrBy(-1)},
+		"keys":          {2, keys},
+		"scan":          {-2, scan},
+		"hset":          {-4, hset},
+		"hget":          {3, hget},
+		"hgetall":       {2, hgetall},
+		"hdel":          {-3, hdel},
+		"zadd":          {-4, zadd},
+		"zrangebyscore": {-4, zrangebyscore},
+		"zrank":         {3, zrank},
+		"zrem":          {-3, zrem},
+	}
+}
+
+const (
+	errSyntax  = "ERR syntax error"
+	errInteger = "ERR value is not an integer or out of range"
+	errFloat   = "ERR value is not a valid float"
+)
+
+// maxCursors is the maximum number of scan cursors kept per connection.
+const maxCursors = 64
+
+func ping(c *conn, args [][]byte) {
+	// reply pong or message
+	if len(args) == 0 {
+		c.writer.Simple("PONG")
+	} else if len(args) == 1 {
+		c.writer.Bulk(args[0])
+	} else {
+		c.writer.Error("ERR wrong number of arguments for 'ping' command")
+	}
+}
+
+func echo(c *conn, args [][]byte) {
+	c.writer.Bulk(args[0])
+}
+
+func hello(c *conn, args [][]byte) {
+	// parse version
+	version := c.writer.Version()
+	if len(args) > 0 {
+		v, err := strconv.Atoi(string(args[0]))
+		if err != nil || v < 2 || v > 3 {
+			c.writer.Error("NOPROTO unsupported protocol version")
+			return
+		}
+		version = v
+		args = args[1:]
+	}
+
+	// parse options
+	for len(args) > 0 {
+		switch strings.ToLower(string(args[0])) {
+		case "auth":
+			if len(args) < 3 {
+				c.writer.Error(errSyntax)
+				return
+			}
+			args = args[3:]
+		case "setname":
+			if len(args) < 2 {
+				c.writer.Error(errSyntax)
+				return
+			}
+			args = args[2:]
+		default:
+			c.writer.Error(errSyntax)
+			return
+		}
+	}
+
+	// set version
+	c.writer.SetVersion(version)
+
+	// write info
+	c.writer.Map(7)
+	c.writer.Bulk([]byte("server"))
+	c.writer.Bulk([]byte("turing"))
+	c.writer.Bulk([]byte("version"))
+	c.writer.Bulk([]byte("0.0.0"))
+	c.writer.Bulk([]byte("proto"))
+	c.writer.Integer(int64(version))
+	c.writer.Bulk([]byte("id"))
+	c.writer.Integer(int64(c.id))
+	c.writer.Bulk([]byte("mode"))
+	c.writer.Bulk([]byte("standalone"))
+	c.writer.Bulk([]byte("role"))
+	c.writer.Bulk([]byte("master"))
+	c.writer.Bulk([]byte("modules"))
+	c.writer.Array(0)
+}
+
+func quit(c *conn, _ [][]byte) {
+	c.writer.Simple("OK")
+	c.quit = true
+}
+
+func selectDB(c *conn, args [][]byte) {
+	// only the default database is supported
+	if string(args[0]) != "0" {
+		c.writer.Error("ERR DB index is out of range")
+		return
+	}
+
+	c.writer.Simple("OK")
+}
+
+func commandInfo(c *conn, _ [][]byte) {
+	c.writer.Array(0)
+}
+
+func client(c *conn, args [][]byte) {
+	switch strings.ToLower(string(args[0])) {
+	case "id":
+		c.writer.Integer(int64(c.id))
+	case "setname", "setinfo":
+		c.writer.Simple("OK")
+	default:
+		c.writer.Error("ERR unknown subcommand '" + string(args[0]) + "'")
+	}
+}
+
+func get(c *conn, args [][]byte) {
+	// get value
+	get := &stdset.Get{Key: args[0]}
+	if !c.execute(get, true) {
+		return
+	}
+
+	// write value
+	if get.Exists {
+		c.writer.Bulk(get.Value)
+	} else {
+		c.writer.Null()
+	}
+}
+
+func set(c *conn, args [][]byte) {
+	// parse options
+	var ttl time.Duration
+	var nx bool
+	for i := 2; i < len(args); i++ {
+		switch strings.ToLower(string(args[i])) {
+		case "ex", "px":
+			if i+1 >= len(args) {
+				c.writer.Error(errSyntax)
+				return
+			}
+			n, err := strconv.ParseInt(string(args[i+1]), 10, 64)
+			if err != nil || n <= 0 {
+				c.writer.Error("ERR invalid expire time in 'set' command")
+				return
+			}
+			if strings.ToLower(string(args[i])) == "ex" {
+				ttl = time.Duration(n) * time.Second
+			} else {
+				ttl = time.Duration(n) * time.Millisecond
+			}
+			i++
+		case "nx":
+			nx = true
+		default:
+			c.writer.Error(errSyntax)
+			return
+		}
+	}
+
+	// handle nx
+	if nx {
+		// set value
+		setNX := &stdset.SetNX{Key: args[0], Value: args[1], TTL: ttl}
+		if !c.execute(setNX, false) {
+			return
+		}
+
+		// write result
+		if setNX.Applied {
+			c.writer.Simple("OK")
+		} else {
+			c.writer.Null()
+		}
+
+		return
+	}
+
+	// set value
+	if !c.execute(&stdset.Set{Key: args[0], Value: args[1], TTL: ttl}, false) {
+		return
+	}
+
+	c.writer.Simple("OK")
+}
+
+func getSet(c *conn, args [][]byte) {
+	// set value
+	getSet := &stdset.GetSet{Key: args[0], Value: args[1]}
+	if !c.execute(getSet, false) {
+		return
+	}
+
+	// write old value
+	if getSet.Existed {
+		c.writer.Bulk(getSet.Old)
+	} else {
+		c.writer.Null()
+	}
+}
+
+func del(c *conn, args [][]byte) {
+	// unset keys
+	unset := &stdset.MUnset{Keys: args}
+	if !c.execute(unset, false) {
+		return
+	}
+
+	c.writer.Integer(int64(unset.Deleted))
+}
+
+func exists(c *conn, args [][]byte) {
+	// get values
+	mget := &stdset.MGet{Keys: args}
+	if !c.execute(mget, true) {
+		return
+	}
+
+	// count existing keys
+	var count int64
+	for _, ok := range mget.Exists {
+		if ok {
+			count++
+		}
+	}
+
+	c.writer.Integer(count)
+}
+
+func mget(c *conn, args [][]byte) {
+	// get values
+	mget := &stdset.MGet{Keys: args}
+	if !c.execute(mget, true) {
+		return
+	}
+
+	// write values
+	c.writer.Array(len(args))
+	for i := range args {
+		if mget.Exists[i] {
+			c.writer.Bulk(mget.Values[i])
+		} else {
+			c.writer.Null()
+		}
+	}
+}
+
+func incrBy(sign int64) func(c *conn, args [][]byte) {
+	return func(c *conn, args [][]byte) {
+		// parse increment
+		n := int64(1)
+		if len(args) == 2 {
+			var err error
+			n, err = strconv.ParseInt(string(args[1]), 10, 64)
+			if err != nil {
+				c.writer.Error(errInteger)
+				return
+			}
+		}
+
+		// increment value
+		inc := &stdset.IncGet{Key: args[0], Value: sign * n}
+		if !c.execute(inc, false) {
+			return
+		}
+
+		// check value
+		if inc.Invalid {
+			c.writer.Error(errInteger)
+			return
+		}
+
+		c.writer.Integer(inc.Result)
+	}
+}
+
+func keys(c *conn, args [][]byte) {
+	// list keys with the literal prefix of the pattern
+	list := &stdset.List{Prefix: literalPrefix(args[0])}
+	if !c.execute(list, true) {
+		return
+	}
+
+	// filter keys
+	var matches [][]byte
+	for _, key := range list.Keys {
+		if !stdset.IsReserved(key) && match(args[0], key) {
+			matches = append(matches, key)
+		}
+	}
+
+	// write keys
+	c.writer.Array(len(matches))
+	for _, key := range matches {
+		c.writer.Bulk(key)
+	}
+}
+
+func scan(c *conn, args [][]byte) {
+	// parse cursor
+	id, err := strconv.ParseUint(string(args[0]), 10, 64)
+	if err != nil {
+		c.writer.Error("ERR invalid cursor")
+		return
+	}
+
+	// get start
+	var start []byte
+	if id != 0 {
+		var ok bool
+		start, ok = c.cursors[id]
+		if !ok {
+			c.writer.Error("ERR invalid cursor")
+			return
+		}
+		delete(c.cursors, id)
+	}
+
+	// parse options
+	var pattern []byte
+	count := 10
+	for i := 1; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			c.writer.Error(errSyntax)
+			return
+		}
+		switch strings.ToLower(string(args[i])) {
+		case "match":
+			pattern = args[i+1]
+		case "count":
+			count, err = strconv.Atoi(string(args[i+1]))
+			if err != nil || count <= 0 {
+				c.writer.Error(errSyntax)
+				return
+			}
+		default:
+			c.writer.Error(errSyntax)
+			return
+		}
+	}
+
+	// scan keys
+	scan := &stdset.Scan{Start: start, Limit: count}
+	if !c.execute(scan, true) {
+		return
+	}
+
+	// store cursor and evict the oldest
+	id = 0
+	if scan.Cursor != nil {
+		c.cursor++
+		id = c.cursor
+		c.cursors[id] = scan.Cursor
+		delete(c.cursors, id-maxCursors)
+	}
+
+	// filter keys
+	var matches [][]byte
+	for _, key := range scan.Keys {
+		if !stdset.IsReserved(key) && (pattern == nil || match(pattern, key)) {
+			matches = append(matches, key)
+		}
+	}
+
+	// write reply
+	c.writer.Array(2)
+	c.writer.Bulk([]byte(strconv.FormatUint(id, 10)))
+	c.writer.Array(len(matches))
+	for _, key := range matches {
+		c.writer.Bulk(key)
+	}
+}
+
+func hset(c *conn, args [][]byte) {
+	// check pairs
+	if len(args)%2 != 1 {
+		c.writer.Error("ERR wrong number of arguments for 'hset' command")
+		return
+	}
+
+	// prepare fields
+	fields := make([]hash.Field, 0, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		fields = append(fields, hash.Field{Field: args[i], Value: args[i+1]})
+	}
+
+	// set fields
+	set := &hash.Set{Key: args[0], Fields: fields}
+	if !c.execute(set, false) {
+		return
+	}
+
+	c.writer.Integer(int64(set.Added))
+}
+
+func hget(c *conn, args [][]byte) {
+	// get field
+	get := &hash.Get{Key: args[0], Field: args[1]}
+	if !c.execute(get, true) {
+		return
+	}
+
+	// write value
+	if get.Exists {
+		c.writer.Bulk(get.Value)
+	} else {
+		c.writer.Null()
+	}
+}
+
+func hgetall(c *conn, args [][]byte) {
+	// get fields
+	getAll := &hash.GetAll{Key: args[0]}
+	if !c.execute(getAll, true) {
+		return
+	}
+
+	// write fields
+	c.writer.Map(len(getAll.Fields))
+	for _, field := range getAll.Fields {
+		c.writer.Bulk(field.Field)
+		c.writer.Bulk(field.Value)
+	}
+}
+
+func hdel(c *conn, args [][]byte) {
+	// delete fields
+	del := &hash.Delete{Key: args[0], Fields: args[1:]}
+	if !c.execute(del, false) {
+		return
+	}
+
+	c.writer.Integer(int64(del.Deleted))
+}
+
+func zadd(c *conn, args [][]byte) {
+	// check pairs
+	if len(args)%2 != 1 {
+		c.writer.Error(errSyntax)
+		return
+	}
+
+	// prepare members
+	members := make([]zset.Member, 0, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		score, err := strconv.ParseFloat(string(args[i]), 64)
+		if err != nil || math.IsNaN(score) {
+			c.writer.Error(errFloat)
+			return
+		}
+		members = append(members, zset.Member{Member: args[i+1], Score: score})
+	}
+
+	// add members
+	add := &zset.Add{Key: args[0], Members: members}
+	if !c.execute(add, false) {
+		return
+	}
+
+	c.writer.Integer(int64(add.Added))
+}
+
+func zrangebyscore(c *conn, args [][]byte) {
+	// parse bounds
+	min, ok1 := parseBound(args[1], math.Inf(1))
+	max, ok2 := parseBound(args[2], math.Inf(-1))
+	if !ok1 || !ok2 {
+		c.writer.Error("ERR min or max is not a float")
+		return
+	}
+
+	// parse options
+	var withScores bool
+	offset, count := 0, -1
+	for i := 3; i < len(args); i++ {
+		switch strings.ToLower(string(args[i])) {
+		case "withscores":
+			withScores = true
+		case "limit":
+			if i+2 >= len(args) {
+				c.writer.Error(errSyntax)
+				return
+			}
+			var err1, err2 error
+			offset, err1 = strconv.Atoi(string(args[i+1]))
+			count, err2 = strconv.Atoi(string(args[i+2]))
+			if err1 != nil || err2 != nil {
+				c.writer.Error(errInteger)
+				return
+			}
+			i += 2
+		default:
+			c.writer.Error(errSyntax)
+			return
+		}
+	}
+
+	// check offset
+	if offset < 0 {
+		c.writer.Array(0)
+		return
+	}
+
+	// prepare instruction
+	rng := &zset.RangeByScore{Key: args[0], Min: min, Max: max}
+	if count >= 0 {
+		if count == 0 {
+			c.writer.Array(0)
+			return
+		}
+		rng.Limit = offset + count
+	}
+
+	// get members
+	if !c.execute(rng, true) {
+		return
+	}
+
+	// apply offset
+	members := rng.Members
+	if offset >= len(members) {
+		members = nil
+	} else {
+		members = members[offset:]
+	}
+
+	// write members
+	if !withScores {
+		c.writer.Array(len(members))
+		for _, member := range members {
+			c.writer.Bulk(member.Member)
+		}
+		return
+	}
+
+	// write members with scores
+	if c.writer.Version() >= 3 {
+		c.writer.Array(len(members))
+		for _, member := range members {
+			c.writer.Array(2)
+			c.writer.Bulk(member.Member)
+			c.writer.Double(member.Score)
+		}
+	} else {
+		c.writer.Array(len(members) * 2)
+		for _, member := range members {
+			c.writer.Bulk(member.Member)
+			c.writer.Double(member.Score)
+		}
+	}
+}
+
+func zrank(c *conn, args [][]byte) {
+	// get rank
+	rank := &zset.Rank{Key: args[0], Member: args[1]}
+	if !c.execute(rank, true) {
+		return
+	}
+
+	// write rank
+	if rank.Found {
+		c.writer.Integer(int64(rank.Rank))
+	} else {
+		c.writer.Null()
+	}
+}
+
+func zrem(c *conn, args [][]byte) {
+	// remove members
+	remove := &zset.Remove{Key: args[0], Members: args[1:]}
+	if !c.execute(remove, false) {
+		return
+	}
+
+	c.writer.Integer(int64(remove.Removed))
+}
+
+func parseBound(arg []byte, towards float64) (float64, bool) {
+	// check exclusive
+	str := string(arg)
+	exclusive := strings.HasPrefix(str, "(")
+	if exclusive {
+		str = str[1:]
+	}
+
+	// parse number
+	num, err := strconv.ParseFloat(str, 64)
+	if err != nil || math.IsNaN(num) {
+		return 0, false
+	}
+
+	// use next number if exclusive
+	if exclusive {
+		num = math.Nextafter(num, towards)
+	}
+
+	return num, true
+}
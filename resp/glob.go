@@ -0,0 +1,112 @@
+package resp
+
+// match reports whether the key matches the Redis style glob pattern. The
+// pattern supports "*", "?", character classes like "[a-z]" or "[^abc]" and
+// escaping using "\".
+func match(pattern, key []byte) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			// collapse stars
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+
+			// try all suffixes
+			for i := 0; i <= len(key); i++ {
+				if match(pattern, key[i:]) {
+					return true
+				}
+			}
+
+			return false
+		case '?':
+			// match any byte
+			if len(key) == 0 {
+				return false
+			}
+			pattern = pattern[1:]
+			key = key[1:]
+		case '[':
+			// check key
+			if len(key) == 0 {
+				return false
+			}
+
+			// check negation
+			pattern = pattern[1:]
+			negate := len(pattern) > 0 && pattern[0] == '^'
+			if negate {
+				pattern = pattern[1:]
+			}
+
+			// match class
+			matched := false
+			for len(pattern) > 0 && pattern[0] != ']' {
+				if pattern[0] == '\\' && len(pattern) > 1 {
+					pattern = pattern[1:]
+					matched = matched || pattern[0] == key[0]
+					pattern = pattern[1:]
+				} else if len(pattern) > 2 && pattern[1] == '-' && pattern[2] != ']' {
+					lo, hi := pattern[0], pattern[2]
+					if lo > hi {
+						lo, hi = hi, lo
+					}
+					matched = matched || (key[0] >= lo && key[0] <= hi)
+					pattern = pattern[3:]
+				} else {
+					matched = matched || pattern[0] == key[0]
+					pattern = pattern[1:]
+				}
+			}
+
+			// skip closing bracket
+			if len(pattern) > 0 {
+				pattern = pattern[1:]
+			}
+
+			// check result
+			if matched == negate {
+				return false
+			}
+			key = key[1:]
+		case '\\':
+			// match escaped byte
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			// match literal byte
+			if len(key) == 0 || pattern[0] != key[0] {
+				return false
+			}
+			pattern = pattern[1:]
+			key = key[1:]
+		}
+	}
+
+	return len(key) == 0
+}
+
+// literalPrefix returns the prefix of the pattern that contains no special
+// characters.
+func literalPrefix(pattern []byte) []byte {
+	var prefix []byte
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?', '[':
+			return prefix
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+		}
+		prefix = append(prefix, pattern[i])
+	}
+
+	return prefix
+}
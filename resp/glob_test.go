@@ -0,0 +1,43 @@
+package resp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	table := []struct {
+		pattern string
+		key     string
+		result  bool
+	}{
+		{"*", "", true},
+		{"*", "foo", true},
+		{"foo", "foo", true},
+		{"foo", "fo", false},
+		{"f*o", "fooo", true},
+		{"f*o", "foob", false},
+		{"f?o", "foo", true},
+		{"f?o", "fo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{"h[a-c]llo", "hdllo", false},
+		{"f\\*o", "f*o", true},
+		{"f\\*o", "foo", false},
+	}
+
+	for _, item := range table {
+		assert.Equal(t, item.result, match([]byte(item.pattern), []byte(item.key)), item)
+	}
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	assert.Equal(t, []byte(nil), literalPrefix([]byte("*")))
+	assert.Equal(t, []byte("foo"), literalPrefix([]byte("foo*bar")))
+	assert.Equal(t, []byte("f*o"), literalPrefix([]byte("f\\*o?")))
+	assert.Equal(t, []byte("foo"), literalPrefix([]byte("foo")))
+}
@@ -0,0 +1,230 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// MaxBulkLength is the maximum length of a bulk string accepted by the reader.
+const MaxBulkLength = 512 << 20
+
+// MaxArrayLength is the maximum length of an array accepted by the reader.
+const MaxArrayLength = 1 << 20
+
+// ErrProtocol is returned if the client sent malformed data.
+var ErrProtocol = fmt.Errorf("resp: protocol error")
+
+// Reader reads commands from a connection.
+type Reader struct {
+	reader *bufio.Reader
+}
+
+// NewReader creates and returns a new reader.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{
+		reader: bufio.NewReader(r),
+	}
+}
+
+// Buffered returns the number of bytes that can be read without blocking.
+func (r *Reader) Buffered() int {
+	return r.reader.Buffered()
+}
+
+// ReadCommand reads a single command sent as an array of bulk strings or as
+// an inline command.
+func (r *Reader) ReadCommand() ([][]byte, error) {
+	for {
+		// read line
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+
+		// handle inline commands
+		if len(line) == 0 || line[0] != '*' {
+			args := bytes.Fields(line)
+			if len(args) == 0 {
+				continue
+			}
+
+			return args, nil
+		}
+
+		// parse length
+		length, err := parseLength(line[1:], MaxArrayLength)
+		if err != nil {
+			return nil, err
+		} else if length <= 0 {
+			continue
+		}
+
+		// read arguments
+		args := make([][]byte, length)
+		for i := range args {
+			args[i], err = r.readBulk()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return args, nil
+	}
+}
+
+func (r *Reader) readBulk() ([]byte, error) {
+	// read line
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	// check type
+	if len(line) == 0 || line[0] != '$' {
+		return nil, ErrProtocol
+	}
+
+	// parse length
+	length, err := parseLength(line[1:], MaxBulkLength)
+	if err != nil {
+		return nil, err
+	} else if length < 0 {
+		return nil, ErrProtocol
+	}
+
+	// read data and terminator
+	buf := make([]byte, length+2)
+	_, err = io.ReadFull(r.reader, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// check terminator
+	if buf[length] != '\r' || buf[length+1] != '\n' {
+		return nil, ErrProtocol
+	}
+
+	return buf[:length], nil
+}
+
+func (r *Reader) readLine() ([]byte, error) {
+	// read line
+	line, err := r.reader.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, ErrProtocol
+	} else if err != nil {
+		return nil, err
+	}
+
+	// trim terminator
+	line = bytes.TrimSuffix(line[:len(line)-1], []byte{'\r'})
+
+	return line, nil
+}
+
+func parseLength(buf []byte, max int) (int, error) {
+	// parse number
+	n, err := strconv.Atoi(string(buf))
+	if err != nil || n > max {
+		return 0, ErrProtocol
+	}
+
+	return n, nil
+}
+
+// Writer writes replies to a connection. RESP3 types are downgraded to their
+// RESP2 equivalents unless the protocol version has been set to 3.
+type Writer struct {
+	writer  *bufio.Writer
+	version int
+}
+
+// NewWriter creates and returns a new writer that uses RESP2.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		writer:  bufio.NewWriter(w),
+		version: 2,
+	}
+}
+
+// Version returns the protocol version.
+func (w *Writer) Version() int {
+	return w.version
+}
+
+// SetVersion sets the protocol version.
+func (w *Writer) SetVersion(version int) {
+	w.version = version
+}
+
+// Flush writes buffered replies to the connection.
+func (w *Writer) Flush() error {
+	return w.writer.Flush()
+}
+
+// Simple writes a simple string.
+func (w *Writer) Simple(str string) {
+	w.line('+', str)
+}
+
+// Error writes an error. The message should start with an error code e.g.
+// "ERR" or "WRONGTYPE".
+func (w *Writer) Error(msg string) {
+	w.line('-', msg)
+}
+
+// Integer writes an integer.
+func (w *Writer) Integer(num int64) {
+	w.line(':', strconv.FormatInt(num, 10))
+}
+
+// Bulk writes a bulk string.
+func (w *Writer) Bulk(buf []byte) {
+	w.line('$', strconv.Itoa(len(buf)))
+	_, _ = w.writer.Write(buf)
+	_, _ = w.writer.WriteString("\r\n")
+}
+
+// Null writes a null value.
+func (w *Writer) Null() {
+	if w.version >= 3 {
+		_, _ = w.writer.WriteString("_\r\n")
+	} else {
+		_, _ = w.writer.WriteString("$-1\r\n")
+	}
+}
+
+// Double writes a floating point number. RESP2 clients receive a bulk string.
+func (w *Writer) Double(num float64) {
+	str := strconv.FormatFloat(num, 'g', -1, 64)
+	if w.version >= 3 {
+		w.line(',', str)
+	} else {
+		w.Bulk([]byte(str))
+	}
+}
+
+// Array writes the header of an array with the provided length. The elements
+// must be written afterwards.
+func (w *Writer) Array(length int) {
+	w.line('*', strconv.Itoa(length))
+}
+
+// Map writes the header of a map with the provided number of pairs. The keys
+// and values must be written afterwards. RESP2 clients receive a flat array.
+func (w *Writer) Map(length int) {
+	if w.version >= 3 {
+		w.line('%', strconv.Itoa(length))
+	} else {
+		w.line('*', strconv.Itoa(length*2))
+	}
+}
+
+func (w *Writer) line(kind byte, str string) {
+	_ = w.writer.WriteByte(kind)
+	_, _ = w.writer.WriteString(str)
+	_, _ = w.writer.WriteString("\r\n")
+}
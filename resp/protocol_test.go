@@ -0,0 +1,58 @@
+package resp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader(t *testing.T) {
+	r := NewReader(strings.NewReader("*2\r\n$3\r\nGET\r\n$3\r\nfoo\r\nPING  hello\r\n\r\n*0\r\n*1\r\n$0\r\n\r\n"))
+
+	args, err := r.ReadCommand()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("GET"), []byte("foo")}, args)
+
+	args, err = r.ReadCommand()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("PING"), []byte("hello")}, args)
+
+	args, err = r.ReadCommand()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{{}}, args)
+
+	r = NewReader(strings.NewReader("*1\r\n+foo\r\n"))
+	_, err = r.ReadCommand()
+	assert.Equal(t, ErrProtocol, err)
+
+	r = NewReader(strings.NewReader("*1\r\n$3\r\nfooo\r\n"))
+	_, err = r.ReadCommand()
+	assert.Equal(t, ErrProtocol, err)
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	w.Simple("OK")
+	w.Error("ERR foo")
+	w.Integer(-42)
+	w.Bulk([]byte("foo"))
+	w.Null()
+	w.Double(1.5)
+	w.Map(1)
+	w.Bulk([]byte("a"))
+	w.Integer(1)
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "+OK\r\n-ERR foo\r\n:-42\r\n$3\r\nfoo\r\n$-1\r\n$3\r\n1.5\r\n*2\r\n$1\r\na\r\n:1\r\n", buf.String())
+
+	buf.Reset()
+	w.SetVersion(3)
+	w.Null()
+	w.Double(1.5)
+	w.Map(1)
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "_\r\n,1.5\r\n%1\r\n", buf.String())
+}
@@ -0,0 +1,227 @@
+// Package resp provides a server that speaks the Redis serialization protocol
+// (RESP2 and RESP3) and maps commands onto instructions executed by a machine.
+package resp
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/stdset"
+	"github.com/256dpi/turing/stdset/hash"
+	"github.com/256dpi/turing/stdset/zset"
+)
+
+// Instructions returns the instructions that must be registered with the
+// machine used by the server.
+func Instructions() []turing.Instruction {
+	return []turing.Instruction{
+		&stdset.Get{},
+		&stdset.Set{},
+		&stdset.SetNX{},
+		&stdset.GetSet{},
+		&stdset.MUnset{},
+		&stdset.MGet{},
+		&stdset.IncGet{},
+		&stdset.List{},
+		&stdset.Scan{},
+		&hash.Set{},
+		&hash.Get{},
+		&hash.GetAll{},
+		&hash.Delete{},
+		&zset.Add{},
+		&zset.RangeByScore{},
+		&zset.Rank{},
+		&zset.Remove{},
+	}
+}
+
+// Config is used to configure a server.
+type Config struct {
+	// The machine used to execute instructions.
+	Machine *turing.Machine
+
+	// StaleRead can be set to execute read commands as stale reads.
+	StaleRead bool
+}
+
+// Server serves RESP connections.
+type Server struct {
+	config    Config
+	counter   uint64
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	group     sync.WaitGroup
+}
+
+// NewServer creates and returns a new server.
+func NewServer(config Config) *Server {
+	return &Server{
+		config:    config,
+		listeners: map[net.Listener]struct{}{},
+		conns:     map[net.Conn]struct{}{},
+	}
+}
+
+// Serve will accept and serve connections from the provided listener until
+// the listener fails or the server is closed.
+func (s *Server) Serve(listener net.Listener) error {
+	// add listener
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return listener.Close()
+	}
+	s.listeners[listener] = struct{}{}
+	s.mutex.Unlock()
+
+	// ensure removal
+	defer func() {
+		s.mutex.Lock()
+		delete(s.listeners, listener)
+		s.mutex.Unlock()
+	}()
+
+	for {
+		// accept connection
+		conn, err := listener.Accept()
+		if err != nil {
+			// check if closed
+			s.mutex.Lock()
+			closed := s.closed
+			s.mutex.Unlock()
+			if closed {
+				return nil
+			}
+
+			return err
+		}
+
+		// add connection
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			_ = conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.group.Add(1)
+		s.mutex.Unlock()
+
+		// serve connection
+		go s.serve(conn)
+	}
+}
+
+// Close will close all listeners and connections and wait until all
+// connections have been closed.
+func (s *Server) Close() error {
+	// set flag and close listeners and connections
+	s.mutex.Lock()
+	s.closed = true
+	for listener := range s.listeners {
+		_ = listener.Close()
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mutex.Unlock()
+
+	// wait for connections
+	s.group.Wait()
+
+	return nil
+}
+
+func (s *Server) serve(netConn net.Conn) {
+	// ensure removal
+	defer func() {
+		_ = netConn.Close()
+		s.mutex.Lock()
+		delete(s.conns, netConn)
+		s.mutex.Unlock()
+		s.group.Done()
+	}()
+
+	// prepare connection
+	c := &conn{
+		server:  s,
+		id:      atomic.AddUint64(&s.counter, 1),
+		reader:  NewReader(netConn),
+		writer:  NewWriter(netConn),
+		cursors: map[uint64][]byte{},
+	}
+
+	for {
+		// read command
+		args, err := c.reader.ReadCommand()
+		if err == ErrProtocol {
+			c.writer.Error("ERR Protocol error")
+			_ = c.writer.Flush()
+			return
+		} else if err != nil {
+			return
+		}
+
+		// handle command
+		c.handle(args)
+
+		// flush if no more commands are pending or the connection is closing
+		if c.quit || c.reader.Buffered() == 0 {
+			err = c.writer.Flush()
+			if err != nil || c.quit {
+				return
+			}
+		}
+	}
+}
+
+type conn struct {
+	server  *Server
+	id      uint64
+	reader  *Reader
+	writer  *Writer
+	cursors map[uint64][]byte
+	cursor  uint64
+	quit    bool
+}
+
+func (c *conn) handle(args [][]byte) {
+	// lookup command
+	name := strings.ToLower(string(args[0]))
+	cmd, ok := commands[name]
+	if !ok {
+		c.writer.Error("ERR unknown command '" + string(args[0]) + "'")
+		return
+	}
+
+	// check arity
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		c.writer.Error("ERR wrong number of arguments for '" + name + "' command")
+		return
+	}
+
+	// run command
+	cmd.handler(c, args[1:])
+}
+
+func (c *conn) execute(ins turing.Instruction, read bool) bool {
+	// prepare options
+	var opts turing.Options
+	if read {
+		opts.StaleRead = c.server.config.StaleRead
+	}
+
+	// execute instruction
+	err := c.server.config.Machine.Execute(ins, opts)
+	if err != nil {
+		c.writer.Error("ERR " + err.Error())
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,247 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestMain(m *testing.M) {
+	// disable logging
+	turing.SetLogger(nil)
+
+	// run tests
+	os.Exit(m.Run())
+}
+
+type testClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (c *testClient) do(t *testing.T, args ...string) interface{} {
+	// write command
+	cmd := "*" + strconv.Itoa(len(args)) + "\r\n"
+	for _, arg := range args {
+		cmd += "$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n"
+	}
+	_, err := c.conn.Write([]byte(cmd))
+	assert.NoError(t, err)
+
+	// read reply
+	reply, err := c.read()
+	assert.NoError(t, err)
+
+	return reply
+}
+
+func (c *testClient) read() (interface{}, error) {
+	// read line
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = line[:len(line)-2]
+
+	// parse reply
+	switch line[0] {
+	case '+', ',':
+		return line[1:], nil
+	case '-':
+		return fmt.Errorf("%s", line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '_':
+		return nil, nil
+	case '$':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		_, err = io.ReadFull(c.reader, buf)
+		return string(buf[:n]), err
+	case '*', '%':
+		n, _ := strconv.Atoi(line[1:])
+		if line[0] == '%' {
+			n *= 2
+		}
+		list := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := c.read()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	}
+
+	return nil, fmt.Errorf("unexpected reply: %q", line)
+}
+
+func testServer(t *testing.T) (*testClient, func()) {
+	machine := turing.Test(Instructions()...)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	server := NewServer(Config{Machine: machine, StaleRead: true})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	assert.NoError(t, err)
+
+	return &testClient{conn: conn, reader: bufio.NewReader(conn)}, func() {
+		_ = conn.Close()
+		_ = server.Close()
+		machine.Stop()
+	}
+}
+
+func TestServerStrings(t *testing.T) {
+	c, done := testServer(t)
+	defer done()
+
+	assert.Equal(t, "PONG", c.do(t, "PING"))
+	assert.Equal(t, "hello", c.do(t, "ECHO", "hello"))
+	assert.Equal(t, nil, c.do(t, "GET", "foo"))
+	assert.Equal(t, "OK", c.do(t, "SET", "foo", "bar"))
+	assert.Equal(t, "bar", c.do(t, "GET", "foo"))
+	assert.Equal(t, nil, c.do(t, "SET", "foo", "baz", "NX"))
+	assert.Equal(t, "OK", c.do(t, "SET", "bar", "baz", "NX"))
+	assert.Equal(t, "bar", c.do(t, "GETSET", "foo", "qux"))
+	assert.Equal(t, "OK", c.do(t, "SET", "tmp", "1", "EX", "60"))
+	assert.Equal(t, nil, c.do(t, "SET", "tmp", "2", "NX", "EX", "60"))
+	assert.Equal(t, "OK", c.do(t, "SET", "tmp2", "1", "NX", "PX", "60000"))
+	assert.Equal(t, "1", c.do(t, "GET", "tmp2"))
+	assert.Equal(t, []interface{}{"qux", nil, "baz"}, c.do(t, "MGET", "foo", "nope", "bar"))
+	assert.Equal(t, int64(2), c.do(t, "EXISTS", "foo", "bar", "nope"))
+	assert.Equal(t, int64(2), c.do(t, "DEL", "foo", "nope", "tmp", "foo"))
+	assert.Equal(t, int64(1), c.do(t, "INCR", "cnt"))
+	assert.Equal(t, int64(11), c.do(t, "INCRBY", "cnt", "10"))
+	assert.Equal(t, int64(10), c.do(t, "DECR", "cnt"))
+	assert.Equal(t, int64(5), c.do(t, "DECRBY", "cnt", "5"))
+	assert.Equal(t, fmt.Errorf("ERR value is not an integer or out of range"), c.do(t, "INCRBY", "cnt", "x"))
+	assert.Equal(t, "OK", c.do(t, "SET", "str", "x"))
+	assert.Equal(t, fmt.Errorf("ERR value is not an integer or out of range"), c.do(t, "INCR", "str"))
+	assert.Equal(t, "x", c.do(t, "GET", "str"))
+	assert.Equal(t, fmt.Errorf("ERR unknown command 'FOO'"), c.do(t, "FOO"))
+	assert.Equal(t, fmt.Errorf("ERR wrong number of arguments for 'get' command"), c.do(t, "GET"))
+}
+
+func TestServerKeys(t *testing.T) {
+	c, done := testServer(t)
+	defer done()
+
+	for i := 0; i < 25; i++ {
+		assert.Equal(t, "OK", c.do(t, "SET", fmt.Sprintf("key%02d", i), "x"))
+	}
+	assert.Equal(t, "OK", c.do(t, "SET", "other", "x"))
+	assert.Equal(t, int64(1), c.do(t, "HSET", "hash", "a", "1"))
+	assert.Equal(t, int64(1), c.do(t, "ZADD", "zset", "1", "a"))
+	assert.Equal(t, "OK", c.do(t, "SET", "\x00qfoo", "x"))
+
+	assert.Equal(t, []interface{}{"key20", "key21", "key22", "key23", "key24"}, c.do(t, "KEYS", "key2?"))
+	assert.Equal(t, []interface{}{"other"}, c.do(t, "KEYS", "*er"))
+	assert.Len(t, c.do(t, "KEYS", "*"), 26)
+
+	var keys []interface{}
+	cursor := "0"
+	for {
+		reply := c.do(t, "SCAN", cursor, "MATCH", "key*", "COUNT", "10").([]interface{})
+		keys = append(keys, reply[1].([]interface{})...)
+		cursor = reply[0].(string)
+		if cursor == "0" {
+			break
+		}
+	}
+	assert.Len(t, keys, 25)
+
+	keys = nil
+	cursor = "0"
+	for {
+		reply := c.do(t, "SCAN", cursor).([]interface{})
+		keys = append(keys, reply[1].([]interface{})...)
+		cursor = reply[0].(string)
+		if cursor == "0" {
+			break
+		}
+	}
+	assert.Len(t, keys, 26)
+
+	assert.Equal(t, fmt.Errorf("ERR invalid cursor"), c.do(t, "SCAN", "42"))
+
+	first := c.do(t, "SCAN", "0").([]interface{})[0].(string)
+	for i := 0; i < maxCursors; i++ {
+		c.do(t, "SCAN", "0")
+	}
+	assert.Equal(t, fmt.Errorf("ERR invalid cursor"), c.do(t, "SCAN", first))
+}
+
+func TestServerHash(t *testing.T) {
+	c, done := testServer(t)
+	defer done()
+
+	assert.Equal(t, int64(2), c.do(t, "HSET", "foo", "a", "1", "b", "2"))
+	assert.Equal(t, int64(0), c.do(t, "HSET", "foo", "a", "3"))
+	assert.Equal(t, "3", c.do(t, "HGET", "foo", "a"))
+	assert.Equal(t, nil, c.do(t, "HGET", "foo", "c"))
+	assert.Equal(t, []interface{}{"a", "3", "b", "2"}, c.do(t, "HGETALL", "foo"))
+	assert.Equal(t, int64(1), c.do(t, "HDEL", "foo", "a", "c"))
+	assert.Equal(t, []interface{}{"b", "2"}, c.do(t, "HGETALL", "foo"))
+}
+
+func TestServerSortedSet(t *testing.T) {
+	c, done := testServer(t)
+	defer done()
+
+	assert.Equal(t, int64(3), c.do(t, "ZADD", "foo", "1", "a", "2", "b", "3", "c"))
+	assert.Equal(t, int64(0), c.do(t, "ZADD", "foo", "2.5", "a"))
+	assert.Equal(t, []interface{}{"b", "a", "c"}, c.do(t, "ZRANGEBYSCORE", "foo", "-inf", "+inf"))
+	assert.Equal(t, []interface{}{"a", "c"}, c.do(t, "ZRANGEBYSCORE", "foo", "(2", "3"))
+	assert.Equal(t, []interface{}{"a", "2.5"}, c.do(t, "ZRANGEBYSCORE", "foo", "0", "10", "WITHSCORES", "LIMIT", "1", "1"))
+	assert.Equal(t, int64(1), c.do(t, "ZRANK", "foo", "a"))
+	assert.Equal(t, nil, c.do(t, "ZRANK", "foo", "d"))
+	assert.Equal(t, int64(1), c.do(t, "ZREM", "foo", "a", "d"))
+	assert.Equal(t, []interface{}{"b", "c"}, c.do(t, "ZRANGEBYSCORE", "foo", "-inf", "+inf"))
+}
+
+func TestServerRESP3(t *testing.T) {
+	c, done := testServer(t)
+	defer done()
+
+	reply := c.do(t, "HELLO", "3").([]interface{})
+	assert.Equal(t, []interface{}{"server", "turing"}, reply[:2])
+	assert.Equal(t, []interface{}{"proto", int64(3)}, reply[4:6])
+
+	assert.Equal(t, nil, c.do(t, "GET", "foo"))
+	assert.Equal(t, int64(1), c.do(t, "ZADD", "foo", "1.5", "a"))
+	assert.Equal(t, []interface{}{[]interface{}{"a", "1.5"}}, c.do(t, "ZRANGEBYSCORE", "foo", "-inf", "+inf", "WITHSCORES"))
+	assert.Equal(t, fmt.Errorf("NOPROTO unsupported protocol version"), c.do(t, "HELLO", "4"))
+	assert.Equal(t, "OK", c.do(t, "QUIT"))
+}
+
+func TestServerPipelining(t *testing.T) {
+	c, done := testServer(t)
+	defer done()
+
+	_, err := c.conn.Write([]byte("*3\r\n$3\r\nSET\r\n$3\r\nfoo\r\n$3\r\nbar\r\nGET foo\r\nPING\r\n"))
+	assert.NoError(t, err)
+
+	for _, expected := range []interface{}{"OK", "bar", "PONG"} {
+		reply, err := c.read()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, reply)
+	}
+}
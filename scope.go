@@ -0,0 +1,230 @@
+package turing
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// Scoped is an optional interface implemented by instructions that declare
+// the keys they access. Bounded instructions of an update batch with
+// non-overlapping scopes are executed concurrently using separate
+// transactions. Their changes are merged in batch order, which yields the
+// same result as the sequential execution as long as the declared scopes are
+// accurate. Instructions executed concurrently use separate empty caches.
+type Scoped interface {
+	Instruction
+
+	// Scope should return the keys read and written by the instruction. Each
+	// key is treated as a prefix and covers all keys that start with it.
+	// Merged keys must be listed as reads too, as merging into an expired
+	// value reads the key.
+	Scope() (reads, writes [][]byte)
+}
+
+// scope is the collected scope of multiple instructions.
+type scope struct {
+	reads  [][]byte
+	writes [][]byte
+}
+
+func (s *scope) add(reads, writes [][]byte) {
+	s.reads = append(s.reads, reads...)
+	s.writes = append(s.writes, writes...)
+}
+
+func (s *scope) conflicts(reads, writes [][]byte) bool {
+	return overlaps(s.writes, reads) || overlaps(s.writes, writes) || overlaps(s.reads, writes)
+}
+
+func overlaps(a, b [][]byte) bool {
+	// check all pairs
+	for _, x := range a {
+		for _, y := range b {
+			if bytes.HasPrefix(x, y) || bytes.HasPrefix(y, x) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// collect will return the number of instructions at the start of the list
+// that can be executed concurrently.
+func (d *database) collect(list []Instruction) int {
+	// check concurrency
+	if d.config.ConcurrentUpdaters <= 1 {
+		return 0
+	}
+
+	// collect non-conflicting scoped instructions
+	var total, n int
+	var collected scope
+	for _, ins := range list {
+		// check instruction
+		scoped, ok := ins.(Scoped)
+		if !ok {
+			break
+		}
+
+		// check effect
+		effect := ins.Effect()
+		if effect <= 0 || total+2*effect >= d.config.MaxEffect {
+			break
+		}
+
+		// check scope
+		reads, writes := scoped.Scope()
+		if collected.conflicts(reads, writes) {
+			break
+		}
+
+		// add instruction
+		collected.add(reads, writes)
+		total += 2 * effect
+		n++
+	}
+
+	return n
+}
+
+type outcome struct {
+	batch       *pebble.Batch
+	effect      int
+	writes      int
+	effectMaxed bool
+	err         error
+}
+
+// parallel will execute the provided instructions concurrently and merge
+// their changes in order into a new batch. It returns the number of applied
+// instructions. An instruction that maxed the effect and all following
+// instructions are not applied and must be executed sequentially. Every
+// instruction uses its own cache that is discarded afterwards.
+func (d *database) parallel(txn *transaction, batch *pebble.Batch, list []Instruction, errs []error, offset int, seed int64) (*pebble.Batch, int, error) {
+	// commit current batch
+	err := batch.Commit(pebble.NoSync)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// prepare outcomes and tokens
+	outcomes := make([]outcome, len(list))
+	tokens := make(chan struct{}, d.config.ConcurrentUpdaters)
+
+	// execute instructions
+	var wg sync.WaitGroup
+	for i, ins := range list {
+		wg.Add(1)
+		tokens <- struct{}{}
+		go func(i int, ins Instruction) {
+			defer wg.Done()
+			defer func() { <-tokens }()
+
+			// begin observation
+			timer := observe(ins.Describe().observer)
+			defer timer.finish()
+
+			// prepare batch
+			batch := d.pebble.NewIndexedBatch()
+
+			// prepare transaction
+			sub := newTransaction()
+			sub.config = d.config
+			sub.registry = d.registry
+			sub.reader = batch
+			sub.writer = batch
+			sub.index = txn.index
+			sub.now = txn.now
+			sub.seed = seed + int64(offset+i)
+
+			// ensure recycle
+			defer recycleTransaction(sub)
+
+			// execute transaction
+			effectMaxed, err := sub.execute(ins, newCache())
+			outcomes[i] = outcome{
+				batch:       batch,
+				effect:      sub.effect,
+				writes:      sub.writes,
+				effectMaxed: effectMaxed,
+				err:         err,
+			}
+		}(i, ins)
+	}
+
+	// await completion
+	wg.Wait()
+
+	// ensure batches are closed
+	defer func() {
+		for _, outcome := range outcomes {
+			_ = outcome.batch.Close()
+		}
+	}()
+
+	// create new batch
+	batch = d.pebble.NewIndexedBatch()
+
+	// reset transaction
+	txn.reader = batch
+	txn.writer = batch
+	txn.effect = 0
+	txn.writes = 0
+
+	// merge changes in order
+	var applied int
+	for i, outcome := range outcomes {
+		// stop if effect is maxed
+		if outcome.effectMaxed {
+			break
+		}
+
+		// handle error
+		if rejected(outcome.err) && errs != nil {
+			// set error
+			errs[offset+i] = outcome.err
+		} else if outcome.err != nil {
+			_ = batch.Close()
+			return nil, 0, outcome.err
+		} else {
+			// apply changes
+			err = batch.Apply(outcome.batch, nil)
+			if err != nil {
+				_ = batch.Close()
+				return nil, 0, err
+			}
+
+			// increment effect
+			txn.effect += outcome.effect
+			txn.writes += outcome.writes
+		}
+
+		// update state
+		d.state.Batch = txn.index
+		d.state.Last = uint16(offset + i)
+		applied++
+	}
+
+	// set state if instructions have been applied
+	if applied > 0 {
+		// encode state
+		encodedState, ref, err := d.state.Encode(true)
+		if err != nil {
+			_ = batch.Close()
+			return nil, 0, err
+		}
+
+		// set state
+		err = batch.Set(stateKey, encodedState, nil)
+		ref.Release()
+		if err != nil {
+			_ = batch.Close()
+			return nil, 0, err
+		}
+	}
+
+	return batch, applied, nil
+}
@@ -0,0 +1,241 @@
+package turing
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/stretchr/testify/assert"
+)
+
+type swapper struct {
+	Mode     string
+	Key      []byte
+	Value    []byte
+	Previous []byte
+}
+
+var swapperDesc = &Description{
+	Name: "swapper",
+}
+
+func (s *swapper) Describe() *Description {
+	return swapperDesc
+}
+
+func (s *swapper) Effect() int {
+	return 1
+}
+
+func (s *swapper) Scope() ([][]byte, [][]byte) {
+	return [][]byte{s.Key}, [][]byte{s.Key}
+}
+
+func (s *swapper) Execute(mem Memory, cache Cache) error {
+	// get previous value
+	err := mem.Use(s.Key, func(value []byte) error {
+		s.Previous = Clone(value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch s.Mode {
+	case "panic":
+		_ = mem.Set(s.Key, s.Value)
+		panic("boom")
+	case "cache":
+		_, ok := cache.Get("seen")
+		cache.Set("seen", true)
+		s.Previous = []byte(strconv.FormatBool(ok))
+		return mem.Set(s.Key, s.Value)
+	case "flood":
+		var i int
+		if resume := mem.Continuation(); resume != nil {
+			i = int(resume[0])
+		}
+		for ; i < 25; i++ {
+			err = mem.Set([]byte(fmt.Sprintf("%s-%d", s.Key, i)), s.Value)
+			if err == ErrMaxEffect {
+				_ = mem.Continue([]byte{byte(i)})
+				return err
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+
+	return mem.Set(s.Key, s.Value)
+}
+
+func (s *swapper) Encode() ([]byte, Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		enc.VarString(s.Mode)
+		enc.VarBytes(s.Key)
+		enc.VarBytes(s.Value)
+		enc.VarBytes(s.Previous)
+		return nil
+	})
+}
+
+func (s *swapper) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		s.Mode = dec.VarString(true)
+		s.Key = dec.VarBytes(true)
+		s.Value = dec.VarBytes(true)
+		s.Previous = dec.VarBytes(true)
+		return nil
+	})
+}
+
+func TestScopeOverlaps(t *testing.T) {
+	assert.False(t, overlaps(nil, [][]byte{[]byte("a")}))
+	assert.False(t, overlaps([][]byte{[]byte("a")}, [][]byte{[]byte("b")}))
+	assert.True(t, overlaps([][]byte{[]byte("a")}, [][]byte{[]byte("a")}))
+	assert.True(t, overlaps([][]byte{[]byte("a")}, [][]byte{[]byte("ab")}))
+	assert.True(t, overlaps([][]byte{[]byte("ab")}, [][]byte{[]byte("b"), []byte("a")}))
+
+	var s scope
+	s.add([][]byte{[]byte("r")}, [][]byte{[]byte("w")})
+	assert.False(t, s.conflicts([][]byte{[]byte("r")}, nil))
+	assert.False(t, s.conflicts([][]byte{[]byte("x")}, [][]byte{[]byte("y")}))
+	assert.True(t, s.conflicts([][]byte{[]byte("w")}, nil))
+	assert.True(t, s.conflicts(nil, [][]byte{[]byte("w")}))
+	assert.True(t, s.conflicts(nil, [][]byte{[]byte("r")}))
+}
+
+func TestScopeCollect(t *testing.T) {
+	db, _, err := openDatabase(Config{
+		ConcurrentUpdaters: 4,
+		MaxEffect:          5,
+	}, nil, newManager())
+	assert.NoError(t, err)
+
+	n := db.collect([]Instruction{
+		&swapper{Key: []byte("a")},
+		&swapper{Key: []byte("b")},
+		&swapper{Key: []byte("c")},
+	})
+	assert.Equal(t, 2, n)
+
+	n = db.collect([]Instruction{
+		&swapper{Key: []byte("a")},
+		&swapper{Key: []byte("ab")},
+	})
+	assert.Equal(t, 1, n)
+
+	n = db.collect([]Instruction{
+		&swapper{Key: []byte("a")},
+		&expiringSet{Key: []byte("b")},
+	})
+	assert.Equal(t, 1, n)
+
+	db.config.ConcurrentUpdaters = 1
+	n = db.collect([]Instruction{
+		&swapper{Key: []byte("a")},
+		&swapper{Key: []byte("b")},
+	})
+	assert.Equal(t, 0, n)
+
+	assert.NoError(t, db.close())
+}
+
+func TestScopeParallelUpdate(t *testing.T) {
+	now := time.Now().UnixNano()
+
+	run := func(concurrency int) ([]Instruction, []error, uint64) {
+		config := Config{
+			Instructions:       []Instruction{&swapper{}, &expiringSet{}},
+			Standalone:         true,
+			MaxEffect:          20,
+			ConcurrentUpdaters: concurrency,
+		}
+		assert.NoError(t, config.Validate())
+
+		registry, err := buildRegistry(config)
+		assert.NoError(t, err)
+
+		db, _, err := openDatabase(config, registry, newManager())
+		assert.NoError(t, err)
+
+		list := []Instruction{
+			&swapper{Key: []byte("a"), Value: []byte("1")},
+			&swapper{Key: []byte("b"), Value: []byte("1")},
+			&swapper{Key: []byte("c"), Value: []byte("1"), Mode: "panic"},
+			&swapper{Key: []byte("a"), Value: []byte("2")},
+			&swapper{Key: []byte("d"), Value: []byte("1")},
+			&swapper{Key: []byte("e"), Value: []byte("1"), Mode: "flood"},
+			&swapper{Key: []byte("f"), Value: []byte("1")},
+			&expiringSet{Key: []byte("b"), Value: []byte("2"), TTL: time.Hour},
+			&swapper{Key: []byte("b"), Value: []byte("3")},
+			&swapper{Key: []byte("c"), Value: []byte("3")},
+		}
+		errs := make([]error, len(list))
+		err = db.update(list, errs, 1, now, 0)
+		assert.NoError(t, err)
+
+		sum := &checksum{}
+		err = db.update([]Instruction{sum}, nil, 2, now, 0)
+		assert.NoError(t, err)
+
+		assert.NoError(t, db.close())
+
+		return list, errs, sum.Hash
+	}
+
+	list1, errs1, hash1 := run(1)
+	list2, errs2, hash2 := run(4)
+	assert.Equal(t, list1, list2)
+	assert.Equal(t, errs1, errs2)
+	assert.Equal(t, hash1, hash2)
+
+	assert.Equal(t, []byte("1"), list2[3].(*swapper).Previous)
+	assert.Equal(t, []byte("2"), list2[8].(*swapper).Previous)
+	assert.Nil(t, list2[9].(*swapper).Previous)
+	assert.Error(t, errs2[2])
+}
+
+func TestScopeParallelCache(t *testing.T) {
+	config := Config{
+		Instructions:       []Instruction{&swapper{}},
+		Standalone:         true,
+		ConcurrentUpdaters: 4,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	list := []Instruction{
+		&swapper{Key: []byte("a"), Mode: "cache"},
+		&swapper{Key: []byte("a"), Mode: "cache"},
+		&swapper{Key: []byte("b"), Mode: "cache"},
+		&swapper{Key: []byte("a"), Mode: "cache"},
+		&swapper{Key: []byte("a"), Mode: "cache"},
+	}
+	err = db.update(list, nil, 1, time.Now().UnixNano(), 0)
+	assert.NoError(t, err)
+
+	var seen []string
+	for _, ins := range list {
+		seen = append(seen, string(ins.(*swapper).Previous))
+	}
+	assert.Equal(t, []string{"false", "false", "false", "false", "true"}, seen)
+
+	assert.NoError(t, db.close())
+}
+
+func TestScopeDefault(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&swapper{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, 1, config.ConcurrentUpdaters)
+}
@@ -0,0 +1,147 @@
+package stdset
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Append is an operator used by Push to append elements to a list. Lists are
+// encoded as a sequence of length prefixed elements, see EncodeList and
+// DecodeList.
+var Append = &turing.Operator{
+	Name: "turing/Append",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		// prepare slices
+		slices := make([][]byte, 0, len(ops)+1)
+		slices = append(slices, value)
+		slices = append(slices, ops...)
+
+		// concat slices
+		buf, ref := fpack.Concat(slices...)
+
+		return buf, ref, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		// concat operands
+		buf, ref := fpack.Concat(ops...)
+
+		return buf, ref, nil
+	},
+}
+
+// EncodeList will encode the provided elements as a list.
+func EncodeList(elements [][]byte) []byte {
+	// encode elements
+	buf, _, _ := fpack.Encode(false, func(enc *fpack.Encoder) error {
+		for _, element := range elements {
+			enc.VarBytes(element)
+		}
+		return nil
+	})
+
+	return buf
+}
+
+// DecodeList will decode the provided list. The returned elements are not
+// copied and reference the provided slice.
+func DecodeList(list []byte) ([][]byte, error) {
+	// decode elements
+	var elements [][]byte
+	for len(list) > 0 {
+		// read length
+		length, n := binary.Uvarint(list)
+		if n <= 0 || uint64(len(list)-n) < length {
+			return nil, fmt.Errorf("stdset: decode list: invalid encoding")
+		}
+
+		// add element
+		elements = append(elements, list[n:n+int(length)])
+		list = list[n+int(length):]
+	}
+
+	return elements, nil
+}
+
+// Push will append elements to a list.
+type Push struct {
+	Key      []byte
+	Elements [][]byte
+}
+
+var pushDesc = &turing.Description{
+	Name:      "turing/Push",
+	Operators: []*turing.Operator{Append},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (p *Push) Describe() *turing.Description {
+	return pushDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (p *Push) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (p *Push) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mem.Merge(p.Key, EncodeList(p.Elements), Append)
+}
+
+// Encode implements the turing.Instruction interface.
+func (p *Push) Encode() ([]byte, turing.Ref, error) {
+	return encodeElements(p.Key, p.Elements)
+}
+
+// Decode implements the turing.Instruction interface.
+func (p *Push) Decode(bytes []byte) error {
+	return decodeElements(bytes, "push", &p.Key, &p.Elements)
+}
+
+func encodeElements(key []byte, elements [][]byte) ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode key
+		enc.VarBytes(key)
+
+		// encode length
+		enc.VarUint(uint64(len(elements)))
+
+		// encode elements
+		for _, element := range elements {
+			enc.VarBytes(element)
+		}
+
+		return nil
+	})
+}
+
+func decodeElements(bytes []byte, name string, key *[]byte, elements *[][]byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode %s: invalid version", name)
+		}
+
+		// decode key
+		*key = dec.VarBytes(true)
+
+		// decode length
+		length := dec.VarUint()
+
+		// decode elements
+		*elements = make([][]byte, length)
+		for i := 0; i < int(length); i++ {
+			(*elements)[i] = dec.VarBytes(true)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,46 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestAppend(t *testing.T) {
+	operands := [][]byte{
+		EncodeList([][]byte{[]byte("a")}),
+		EncodeList([][]byte{[]byte("b"), []byte("c")}),
+		EncodeList([][]byte{[]byte("d")}),
+	}
+
+	list, err := DecodeList(checkOperator(t, Append, operands))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}, list)
+}
+
+func TestPush(t *testing.T) {
+	machine := turing.Test(&Push{}, &Get{})
+	defer machine.Stop()
+
+	err := machine.Execute(&Push{
+		Key:      []byte("foo"),
+		Elements: [][]byte{[]byte("a"), []byte("b")},
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&Push{
+		Key:      []byte("foo"),
+		Elements: [][]byte{[]byte("c")},
+	})
+	assert.NoError(t, err)
+
+	get := &Get{Key: []byte("foo")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+
+	list, err := DecodeList(get.Value)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, list)
+}
@@ -0,0 +1,150 @@
+package stdset
+
+import (
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Or is an operator used by OrBits to combine values using a bitwise OR.
+// Shorter values are extended with unset bits.
+var Or = &turing.Operator{
+	Name: "turing/Or",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := combineBits(value, ops, 0x00, func(a, b byte) byte {
+			return a | b
+		})
+		return buf, ref, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := combineBits(nil, ops, 0x00, func(a, b byte) byte {
+			return a | b
+		})
+		return buf, ref, nil
+	},
+}
+
+// And is an operator used by AndBits to combine values using a bitwise AND.
+// Shorter values are extended with set bits.
+var And = &turing.Operator{
+	Name: "turing/And",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := combineBits(value, ops, 0xff, func(a, b byte) byte {
+			return a & b
+		})
+		return buf, ref, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := combineBits(nil, ops, 0xff, func(a, b byte) byte {
+			return a & b
+		})
+		return buf, ref, nil
+	},
+}
+
+func combineBits(value []byte, ops [][]byte, fill byte, fn func(a, b byte) byte) ([]byte, turing.Ref) {
+	// get length
+	length := len(value)
+	for _, op := range ops {
+		if len(op) > length {
+			length = len(op)
+		}
+	}
+
+	// borrow slice
+	buf, ref := fpack.Borrow(length)
+
+	// copy and extend value
+	n := copy(buf, value)
+	for i := n; i < length; i++ {
+		buf[i] = fill
+	}
+
+	// combine operands
+	for _, op := range ops {
+		for i := range buf {
+			if i < len(op) {
+				buf[i] = fn(buf[i], op[i])
+			} else {
+				buf[i] = fn(buf[i], fill)
+			}
+		}
+	}
+
+	return buf, ref
+}
+
+// OrBits will combine a value with the current value using a bitwise OR.
+type OrBits struct {
+	Key   []byte
+	Value []byte
+}
+
+var orBitsDesc = &turing.Description{
+	Name:      "turing/OrBits",
+	Operators: []*turing.Operator{Or},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (o *OrBits) Describe() *turing.Description {
+	return orBitsDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (o *OrBits) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (o *OrBits) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mem.Merge(o.Key, o.Value, Or)
+}
+
+// Encode implements the turing.Instruction interface.
+func (o *OrBits) Encode() ([]byte, turing.Ref, error) {
+	return encodePair(o.Key, o.Value)
+}
+
+// Decode implements the turing.Instruction interface.
+func (o *OrBits) Decode(bytes []byte) error {
+	return decodePair(bytes, "or bits", &o.Key, &o.Value)
+}
+
+// AndBits will combine a value with the current value using a bitwise AND.
+type AndBits struct {
+	Key   []byte
+	Value []byte
+}
+
+var andBitsDesc = &turing.Description{
+	Name:      "turing/AndBits",
+	Operators: []*turing.Operator{And},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (a *AndBits) Describe() *turing.Description {
+	return andBitsDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (a *AndBits) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (a *AndBits) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mem.Merge(a.Key, a.Value, And)
+}
+
+// Encode implements the turing.Instruction interface.
+func (a *AndBits) Encode() ([]byte, turing.Ref, error) {
+	return encodePair(a.Key, a.Value)
+}
+
+// Decode implements the turing.Instruction interface.
+func (a *AndBits) Decode(bytes []byte) error {
+	return decodePair(bytes, "and bits", &a.Key, &a.Value)
+}
@@ -0,0 +1,46 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestOrAnd(t *testing.T) {
+	operands := [][]byte{{0x01, 0x0f}, {0x02}, {0x04, 0xf0, 0x01}}
+	assert.Equal(t, []byte{0x07, 0xff, 0x01}, checkOperator(t, Or, operands))
+
+	operands = [][]byte{{0x0f, 0x3c}, {0xff}, {0x03, 0xf0, 0x01}}
+	assert.Equal(t, []byte{0x03, 0x30, 0x01}, checkOperator(t, And, operands))
+}
+
+func TestOrAndBits(t *testing.T) {
+	machine := turing.Test(&OrBits{}, &AndBits{}, &Get{})
+	defer machine.Stop()
+
+	for _, value := range [][]byte{{0x01}, {0x03}, {0x06}} {
+		err := machine.Execute(&OrBits{
+			Key:   []byte("or"),
+			Value: value,
+		})
+		assert.NoError(t, err)
+
+		err = machine.Execute(&AndBits{
+			Key:   []byte("and"),
+			Value: value,
+		})
+		assert.NoError(t, err)
+	}
+
+	get := &Get{Key: []byte("or")}
+	err := machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x07}, get.Value)
+
+	get = &Get{Key: []byte("and")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x00}, get.Value)
+}
@@ -0,0 +1,307 @@
+package stdset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+const (
+	bloomBits   = 1 << 16
+	bloomSize   = bloomBits / 8
+	bloomHashes = 7
+)
+
+// Bloom is an operator used by BloomAdd to maintain a Bloom filter with 65536
+// bits and seven hash functions (~1% false positives at 6800 items). Values are
+// stored as a dense bitmap while operands may be sparse lists of bit positions.
+var Bloom = &turing.Operator{
+	Name: "turing/Bloom",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		// borrow bitmap
+		bitmap, ref := fpack.Borrow(bloomSize)
+
+		// copy value
+		if len(value) == bloomSize {
+			copy(bitmap, value)
+		} else {
+			for i := range bitmap {
+				bitmap[i] = 0
+			}
+		}
+
+		// apply operands
+		for _, op := range ops {
+			err := walkBloom(op, func(pos uint16) {
+				bitmap[pos/8] |= 1 << (pos % 8)
+			})
+			if err != nil {
+				ref.Release()
+				return nil, nil, err
+			}
+		}
+
+		return bitmap, ref, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		// collect positions
+		positions := map[uint16]struct{}{}
+		for _, op := range ops {
+			err := walkBloom(op, func(pos uint16) {
+				positions[pos] = struct{}{}
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// encode dense operand if smaller
+		if len(positions)*2 >= bloomSize {
+			buf := make([]byte, 1+bloomSize)
+			buf[0] = sketchDense
+			for pos := range positions {
+				buf[1+pos/8] |= 1 << (pos % 8)
+			}
+
+			return buf, nil, nil
+		}
+
+		// sort positions
+		list := make([]int, 0, len(positions))
+		for pos := range positions {
+			list = append(list, int(pos))
+		}
+		sort.Ints(list)
+
+		// encode sparse operand
+		buf := make([]byte, 1, 1+len(list)*2)
+		buf[0] = sketchSparse
+		for _, pos := range list {
+			buf = append(buf, byte(pos>>8), byte(pos))
+		}
+
+		return buf, nil, nil
+	},
+}
+
+func walkBloom(op []byte, fn func(pos uint16)) error {
+	// check length
+	if len(op) == 0 {
+		return fmt.Errorf("stdset: invalid bloom operand")
+	}
+
+	// handle type
+	switch op[0] {
+	case sketchSparse:
+		// check length
+		if (len(op)-1)%2 != 0 {
+			return fmt.Errorf("stdset: invalid sparse bloom operand")
+		}
+
+		// yield positions
+		for i := 1; i < len(op); i += 2 {
+			fn(binary.BigEndian.Uint16(op[i:]))
+		}
+	case sketchDense:
+		// check length
+		if len(op) != 1+bloomSize {
+			return fmt.Errorf("stdset: invalid dense bloom operand")
+		}
+
+		// yield set bits
+		for i, b := range op[1:] {
+			for j := 0; j < 8; j++ {
+				if b&(1<<j) != 0 {
+					fn(uint16(i*8 + j))
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("stdset: invalid bloom operand")
+	}
+
+	return nil
+}
+
+func bloomPositions(item []byte, fn func(pos uint16) bool) {
+	// derive positions using double hashing
+	h1 := hashItem(item, 0)
+	h2 := hashItem(item, 1) | 1
+	for i := uint64(0); i < bloomHashes; i++ {
+		if !fn(uint16((h1 + i*h2) % bloomBits)) {
+			return
+		}
+	}
+}
+
+func bloomOperand(items [][]byte) []byte {
+	// prepare operand
+	op := make([]byte, 1, 1+len(items)*bloomHashes*2)
+	op[0] = sketchSparse
+
+	// add positions
+	for _, item := range items {
+		bloomPositions(item, func(pos uint16) bool {
+			op = append(op, byte(pos>>8), byte(pos))
+			return true
+		})
+	}
+
+	return op
+}
+
+// MatchBloom will test whether the provided item may have been added to the
+// provided Bloom filter value.
+func MatchBloom(value, item []byte) bool {
+	// check length
+	if len(value) != bloomSize {
+		return false
+	}
+
+	// check positions
+	ok := true
+	bloomPositions(item, func(pos uint16) bool {
+		ok = value[pos/8]&(1<<(pos%8)) != 0
+		return ok
+	})
+
+	return ok
+}
+
+// BloomAdd will add items to a Bloom filter.
+type BloomAdd struct {
+	Key   []byte
+	Items [][]byte
+}
+
+var bloomAddDesc = &turing.Description{
+	Name:      "turing/BloomAdd",
+	Operators: []*turing.Operator{Bloom},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (a *BloomAdd) Describe() *turing.Description {
+	return bloomAddDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (a *BloomAdd) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (a *BloomAdd) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mem.Merge(a.Key, bloomOperand(a.Items), Bloom)
+}
+
+// Encode implements the turing.Instruction interface.
+func (a *BloomAdd) Encode() ([]byte, turing.Ref, error) {
+	return encodeElements(a.Key, a.Items)
+}
+
+// Decode implements the turing.Instruction interface.
+func (a *BloomAdd) Decode(bytes []byte) error {
+	return decodeElements(bytes, "bloom add", &a.Key, &a.Items)
+}
+
+// BloomTest will test whether items may have been added to a Bloom filter.
+type BloomTest struct {
+	Key     []byte
+	Items   [][]byte
+	Results []bool
+}
+
+var bloomTestDesc = &turing.Description{
+	Name: "turing/BloomTest",
+}
+
+// Describe implements the turing.Instruction interface.
+func (t *BloomTest) Describe() *turing.Description {
+	return bloomTestDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (t *BloomTest) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (t *BloomTest) Execute(mem turing.Memory, _ turing.Cache) error {
+	// prepare results
+	t.Results = make([]bool, len(t.Items))
+
+	// test items
+	return mem.Use(t.Key, func(value []byte) error {
+		for i, item := range t.Items {
+			t.Results[i] = MatchBloom(value, item)
+		}
+
+		return nil
+	})
+}
+
+// Encode implements the turing.Instruction interface.
+func (t *BloomTest) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode key
+		enc.VarBytes(t.Key)
+
+		// encode length
+		enc.VarUint(uint64(len(t.Items)))
+
+		// encode items
+		for _, item := range t.Items {
+			enc.VarBytes(item)
+		}
+
+		// encode results
+		enc.VarUint(uint64(len(t.Results)))
+		for _, result := range t.Results {
+			enc.Bool(result)
+		}
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (t *BloomTest) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode bloom test: invalid version")
+		}
+
+		// decode key
+		t.Key = dec.VarBytes(true)
+
+		// decode length
+		length := dec.VarUint()
+
+		// decode items
+		t.Items = make([][]byte, length)
+		for i := 0; i < int(length); i++ {
+			t.Items[i] = dec.VarBytes(true)
+		}
+
+		// decode results
+		length = dec.VarUint()
+		t.Results = nil
+		if length > 0 {
+			t.Results = make([]bool, length)
+			for i := range t.Results {
+				t.Results[i] = dec.Bool()
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package stdset
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestBloom(t *testing.T) {
+	operands := [][]byte{
+		bloomOperand([][]byte{[]byte("a"), []byte("b")}),
+		bloomOperand([][]byte{[]byte("c")}),
+		bloomOperand([][]byte{[]byte("a"), []byte("d")}),
+	}
+
+	value := checkOperator(t, Bloom, operands)
+	assert.Len(t, value, bloomSize)
+	assert.True(t, MatchBloom(value, []byte("a")))
+	assert.True(t, MatchBloom(value, []byte("b")))
+	assert.True(t, MatchBloom(value, []byte("c")))
+	assert.True(t, MatchBloom(value, []byte("d")))
+	assert.False(t, MatchBloom(value, []byte("e")))
+}
+
+func TestBloomAdd(t *testing.T) {
+	machine := turing.Test(&BloomAdd{}, &BloomTest{})
+	defer machine.Stop()
+
+	test := &BloomTest{Key: []byte("foo"), Items: [][]byte{[]byte("1"), []byte("bar")}}
+	err := machine.Execute(test)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, false}, test.Results)
+
+	for i := 0; i < 10; i++ {
+		err = machine.Execute(&BloomAdd{
+			Key:   []byte("foo"),
+			Items: [][]byte{[]byte(strconv.Itoa(i))},
+		})
+		assert.NoError(t, err)
+	}
+
+	err = machine.Execute(test)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, test.Results)
+}
+
+func BenchmarkBloomAdd(b *testing.B) {
+	machine := turing.Test(&BloomAdd{})
+	defer machine.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(&BloomAdd{
+			Key:   []byte("foo"),
+			Items: [][]byte{[]byte(strconv.Itoa(i))},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package stdset
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// CAS will set a value if the current value equals the old value. If Absent is
+// set, the value is only set if the key does not exist. If Versioned is set,
+// the version of the current value is compared with Version instead and the
+// value is stored with the next version. Versioned values use the LWW encoding
+// and can be decoded using DecodeLWW. A missing key has the version zero. After
+// execution, Version holds the version of the current value.
+type CAS struct {
+	Key       []byte
+	Old       []byte
+	Value     []byte
+	Absent    bool
+	Versioned bool
+	Version   uint64
+	Applied   bool
+}
+
+var casDesc = &turing.Description{
+	Name: "turing/CAS",
+}
+
+// Describe implements the turing.Instruction interface.
+func (c *CAS) Describe() *turing.Description {
+	return casDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (c *CAS) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (c *CAS) Execute(mem turing.Memory, _ turing.Cache) error {
+	// get current value
+	var found bool
+	var current uint64
+	var matches bool
+	err := mem.Use(c.Key, func(value []byte) error {
+		// set flag
+		found = true
+
+		// compare value if not versioned
+		if !c.Versioned {
+			matches = bytes.Equal(value, c.Old)
+			return nil
+		}
+
+		// compare version
+		version, _, err := DecodeLWW(value)
+		if err == nil {
+			current = version
+			matches = version == c.Version
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// check condition
+	if c.Absent {
+		c.Applied = !found
+	} else if c.Versioned {
+		c.Applied = matches || (!found && c.Version == 0)
+	} else {
+		c.Applied = found && matches
+	}
+
+	// set current version
+	if c.Versioned {
+		c.Version = current
+	}
+
+	// skip if not applied
+	if !c.Applied {
+		return nil
+	}
+
+	// set plain value if not versioned
+	if !c.Versioned {
+		return mem.Set(c.Key, c.Value)
+	}
+
+	// set versioned value
+	c.Version = current + 1
+	err = mem.Set(c.Key, EncodeLWW(c.Version, c.Value))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (c *CAS) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Bool(c.Absent)
+		enc.Bool(c.Versioned)
+		enc.Uint64(c.Version)
+		enc.Bool(c.Applied)
+		enc.VarBytes(c.Key)
+		enc.VarBytes(c.Old)
+		enc.Tail(c.Value)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (c *CAS) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode cas: invalid version")
+		}
+
+		// decode body
+		c.Absent = dec.Bool()
+		c.Versioned = dec.Bool()
+		c.Version = dec.Uint64()
+		c.Applied = dec.Bool()
+		c.Key = dec.VarBytes(true)
+		c.Old = dec.VarBytes(true)
+		c.Value = dec.Tail(true)
+
+		return nil
+	})
+}
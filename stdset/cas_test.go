@@ -0,0 +1,151 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestCAS(t *testing.T) {
+	machine := turing.Test(&CAS{}, &Set{}, &Get{})
+	defer machine.Stop()
+
+	cas := &CAS{
+		Key:   []byte("foo"),
+		Old:   []byte("bar"),
+		Value: []byte("baz"),
+	}
+
+	err := machine.Execute(cas)
+	assert.NoError(t, err)
+	assert.False(t, cas.Applied)
+
+	err = machine.Execute(&Set{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(cas)
+	assert.NoError(t, err)
+	assert.True(t, cas.Applied)
+
+	err = machine.Execute(cas)
+	assert.NoError(t, err)
+	assert.False(t, cas.Applied)
+
+	get := &Get{
+		Key: []byte("foo"),
+	}
+
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("baz"), get.Value)
+}
+
+func TestCASAbsent(t *testing.T) {
+	machine := turing.Test(&CAS{}, &Set{}, &Get{})
+	defer machine.Stop()
+
+	cas := &CAS{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	}
+
+	err := machine.Execute(cas)
+	assert.NoError(t, err)
+	assert.False(t, cas.Applied)
+
+	cas.Absent = true
+	err = machine.Execute(cas)
+	assert.NoError(t, err)
+	assert.True(t, cas.Applied)
+
+	err = machine.Execute(cas)
+	assert.NoError(t, err)
+	assert.False(t, cas.Applied)
+
+	err = machine.Execute(&Set{
+		Key:   []byte("bar"),
+		Value: []byte{},
+	})
+	assert.NoError(t, err)
+
+	cas = &CAS{
+		Key:   []byte("bar"),
+		Value: []byte("baz"),
+	}
+	err = machine.Execute(cas)
+	assert.NoError(t, err)
+	assert.True(t, cas.Applied)
+}
+
+func TestCASVersioned(t *testing.T) {
+	machine := turing.Test(&CAS{}, &Get{})
+	defer machine.Stop()
+
+	cas := &CAS{
+		Key:       []byte("foo"),
+		Value:     []byte("bar"),
+		Versioned: true,
+	}
+	err := machine.Execute(cas)
+	assert.NoError(t, err)
+	assert.True(t, cas.Applied)
+	assert.Equal(t, uint64(1), cas.Version)
+
+	stale := &CAS{
+		Key:       []byte("foo"),
+		Value:     []byte("baz"),
+		Versioned: true,
+	}
+	err = machine.Execute(stale)
+	assert.NoError(t, err)
+	assert.False(t, stale.Applied)
+	assert.Equal(t, uint64(1), stale.Version)
+
+	err = machine.Execute(stale)
+	assert.NoError(t, err)
+	assert.True(t, stale.Applied)
+	assert.Equal(t, uint64(2), stale.Version)
+
+	get := &Get{Key: []byte("foo")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+
+	version, data, err := DecodeLWW(get.Value)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+	assert.Equal(t, []byte("baz"), data)
+}
+
+func BenchmarkCAS(b *testing.B) {
+	machine := turing.Test(&CAS{}, &Set{})
+	defer machine.Stop()
+
+	err := machine.Execute(&Set{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	cas := &CAS{
+		Key:   []byte("foo"),
+		Old:   []byte("bar"),
+		Value: []byte("bar"),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(cas)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
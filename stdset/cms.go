@@ -0,0 +1,332 @@
+package stdset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+const (
+	cmsDepth = 4
+	cmsWidth = 1024
+	cmsSize  = cmsDepth * cmsWidth * 8
+	cmsEntry = 1 + 2 + 8
+)
+
+// CMS is an operator used by CMSAdd to maintain a Count-Min sketch with four
+// rows of 1024 counters. Values are stored as a dense list of counters while
+// operands may be sparse lists of counter increments.
+var CMS = &turing.Operator{
+	Name: "turing/CMS",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		// borrow counters
+		counters, ref := fpack.Borrow(cmsSize)
+
+		// copy value
+		if len(value) == cmsSize {
+			copy(counters, value)
+		} else {
+			for i := range counters {
+				counters[i] = 0
+			}
+		}
+
+		// apply operands
+		for _, op := range ops {
+			err := walkCMS(op, func(cell int, count uint64) {
+				pos := cell * 8
+				binary.BigEndian.PutUint64(counters[pos:], binary.BigEndian.Uint64(counters[pos:])+count)
+			})
+			if err != nil {
+				ref.Release()
+				return nil, nil, err
+			}
+		}
+
+		return counters, ref, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		// collect increments
+		increments := map[int]uint64{}
+		for _, op := range ops {
+			err := walkCMS(op, func(cell int, count uint64) {
+				increments[cell] += count
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// encode dense operand if smaller
+		if len(increments)*cmsEntry >= cmsSize {
+			buf := make([]byte, 1+cmsSize)
+			buf[0] = sketchDense
+			for cell, count := range increments {
+				binary.BigEndian.PutUint64(buf[1+cell*8:], count)
+			}
+
+			return buf, nil, nil
+		}
+
+		// sort cells
+		cells := make([]int, 0, len(increments))
+		for cell := range increments {
+			cells = append(cells, cell)
+		}
+		sort.Ints(cells)
+
+		// encode sparse operand
+		buf := make([]byte, 1, 1+len(cells)*cmsEntry)
+		buf[0] = sketchSparse
+		for _, cell := range cells {
+			buf = appendCMSEntry(buf, cell, increments[cell])
+		}
+
+		return buf, nil, nil
+	},
+}
+
+func appendCMSEntry(buf []byte, cell int, count uint64) []byte {
+	// append row, column and count
+	buf = append(buf, byte(cell/cmsWidth))
+	buf = append(buf, byte(cell%cmsWidth>>8), byte(cell%cmsWidth))
+	buf = append(buf, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(buf[len(buf)-8:], count)
+
+	return buf
+}
+
+func walkCMS(op []byte, fn func(cell int, count uint64)) error {
+	// check length
+	if len(op) == 0 {
+		return fmt.Errorf("stdset: invalid cms operand")
+	}
+
+	// handle type
+	switch op[0] {
+	case sketchSparse:
+		// check length
+		if (len(op)-1)%cmsEntry != 0 {
+			return fmt.Errorf("stdset: invalid sparse cms operand")
+		}
+
+		// yield increments
+		for i := 1; i < len(op); i += cmsEntry {
+			row := int(op[i])
+			col := int(binary.BigEndian.Uint16(op[i+1:]))
+			if row >= cmsDepth || col >= cmsWidth {
+				return fmt.Errorf("stdset: invalid sparse cms operand")
+			}
+			fn(row*cmsWidth+col, binary.BigEndian.Uint64(op[i+3:]))
+		}
+	case sketchDense:
+		// check length
+		if len(op) != 1+cmsSize {
+			return fmt.Errorf("stdset: invalid dense cms operand")
+		}
+
+		// yield counters
+		for cell := 0; cell < cmsDepth*cmsWidth; cell++ {
+			count := binary.BigEndian.Uint64(op[1+cell*8:])
+			if count > 0 {
+				fn(cell, count)
+			}
+		}
+	default:
+		return fmt.Errorf("stdset: invalid cms operand")
+	}
+
+	return nil
+}
+
+func cmsOperand(items [][]byte, count uint64) []byte {
+	// prepare operand
+	op := make([]byte, 1, 1+len(items)*cmsDepth*cmsEntry)
+	op[0] = sketchSparse
+
+	// add increments
+	for _, item := range items {
+		for row := 0; row < cmsDepth; row++ {
+			op = appendCMSEntry(op, cmsCell(item, row), count)
+		}
+	}
+
+	return op
+}
+
+func cmsCell(item []byte, row int) int {
+	return row*cmsWidth + int(hashItem(item, uint64(row))%cmsWidth)
+}
+
+// EstimateCMS will estimate the frequency of the provided item using the
+// provided Count-Min sketch value.
+func EstimateCMS(value, item []byte) uint64 {
+	// check length
+	if len(value) != cmsSize {
+		return 0
+	}
+
+	// find minimum counter
+	var min uint64
+	for row := 0; row < cmsDepth; row++ {
+		count := binary.BigEndian.Uint64(value[cmsCell(item, row)*8:])
+		if row == 0 || count < min {
+			min = count
+		}
+	}
+
+	return min
+}
+
+// CMSAdd will add items to a Count-Min sketch. If no count is specified, the
+// items are counted once.
+type CMSAdd struct {
+	Key   []byte
+	Items [][]byte
+	Count uint64
+}
+
+var cmsAddDesc = &turing.Description{
+	Name:      "turing/CMSAdd",
+	Operators: []*turing.Operator{CMS},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (a *CMSAdd) Describe() *turing.Description {
+	return cmsAddDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (a *CMSAdd) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (a *CMSAdd) Execute(mem turing.Memory, _ turing.Cache) error {
+	// get count
+	count := a.Count
+	if count == 0 {
+		count = 1
+	}
+
+	return mem.Merge(a.Key, cmsOperand(a.Items, count), CMS)
+}
+
+// Encode implements the turing.Instruction interface.
+func (a *CMSAdd) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode count
+		enc.Uint64(a.Count)
+
+		// encode key
+		enc.VarBytes(a.Key)
+
+		// encode length
+		enc.VarUint(uint64(len(a.Items)))
+
+		// encode items
+		for _, item := range a.Items {
+			enc.VarBytes(item)
+		}
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (a *CMSAdd) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode cms add: invalid version")
+		}
+
+		// decode count
+		a.Count = dec.Uint64()
+
+		// decode key
+		a.Key = dec.VarBytes(true)
+
+		// decode length
+		length := dec.VarUint()
+
+		// decode items
+		a.Items = make([][]byte, length)
+		for i := 0; i < int(length); i++ {
+			a.Items[i] = dec.VarBytes(true)
+		}
+
+		return nil
+	})
+}
+
+// CMSEstimate will estimate the frequency of an item using a Count-Min sketch.
+type CMSEstimate struct {
+	Key   []byte
+	Item  []byte
+	Count uint64
+}
+
+var cmsEstimateDesc = &turing.Description{
+	Name: "turing/CMSEstimate",
+}
+
+// Describe implements the turing.Instruction interface.
+func (e *CMSEstimate) Describe() *turing.Description {
+	return cmsEstimateDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (e *CMSEstimate) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (e *CMSEstimate) Execute(mem turing.Memory, _ turing.Cache) error {
+	// estimate frequency
+	e.Count = 0
+	return mem.Use(e.Key, func(value []byte) error {
+		e.Count = EstimateCMS(value, e.Item)
+		return nil
+	})
+}
+
+// Encode implements the turing.Instruction interface.
+func (e *CMSEstimate) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Uint64(e.Count)
+		enc.VarBytes(e.Key)
+		enc.Tail(e.Item)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (e *CMSEstimate) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode cms estimate: invalid version")
+		}
+
+		// decode body
+		e.Count = dec.Uint64()
+		e.Key = dec.VarBytes(true)
+		e.Item = dec.Tail(true)
+
+		return nil
+	})
+}
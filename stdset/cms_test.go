@@ -0,0 +1,77 @@
+package stdset
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestCMS(t *testing.T) {
+	operands := [][]byte{
+		cmsOperand([][]byte{[]byte("a"), []byte("b")}, 1),
+		cmsOperand([][]byte{[]byte("a")}, 5),
+		cmsOperand([][]byte{[]byte("c"), []byte("a")}, 2),
+	}
+
+	value := checkOperator(t, CMS, operands)
+	assert.Len(t, value, cmsSize)
+	assert.Equal(t, uint64(8), EstimateCMS(value, []byte("a")))
+	assert.Equal(t, uint64(1), EstimateCMS(value, []byte("b")))
+	assert.Equal(t, uint64(2), EstimateCMS(value, []byte("c")))
+	assert.Equal(t, uint64(0), EstimateCMS(value, []byte("d")))
+}
+
+func TestCMSAdd(t *testing.T) {
+	machine := turing.Test(&CMSAdd{}, &CMSEstimate{})
+	defer machine.Stop()
+
+	estimate := &CMSEstimate{Key: []byte("foo"), Item: []byte("bar")}
+	err := machine.Execute(estimate)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), estimate.Count)
+
+	for i := 0; i < 10; i++ {
+		err = machine.Execute(&CMSAdd{
+			Key:   []byte("foo"),
+			Items: [][]byte{[]byte("bar"), []byte(strconv.Itoa(i))},
+		})
+		assert.NoError(t, err)
+	}
+
+	err = machine.Execute(&CMSAdd{
+		Key:   []byte("foo"),
+		Items: [][]byte{[]byte("bar")},
+		Count: 5,
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(estimate)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(15), estimate.Count)
+
+	estimate = &CMSEstimate{Key: []byte("foo"), Item: []byte("7")}
+	err = machine.Execute(estimate)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), estimate.Count)
+}
+
+func BenchmarkCMSAdd(b *testing.B) {
+	machine := turing.Test(&CMSAdd{})
+	defer machine.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(&CMSAdd{
+			Key:   []byte("foo"),
+			Items: [][]byte{[]byte(strconv.Itoa(i))},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
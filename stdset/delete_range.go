@@ -0,0 +1,140 @@
+package stdset
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// deleteRangeStep is the maximum number of values deleted per execution.
+const deleteRangeStep = 1000
+
+// DeleteRange will delete all values in the range [Start, End) and report the
+// number of deleted values. The effect is unbounded and large ranges are
+// deleted in multiple steps using continuations.
+type DeleteRange struct {
+	Start   []byte
+	End     []byte
+	Deleted int64
+}
+
+var deleteRangeDesc = &turing.Description{
+	Name: "turing/DeleteRange",
+}
+
+// Describe implements the turing.Instruction interface.
+func (d *DeleteRange) Describe() *turing.Description {
+	return deleteRangeDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (d *DeleteRange) Effect() int {
+	return turing.UnboundedEffect
+}
+
+// Execute implements the turing.Instruction interface.
+func (d *DeleteRange) Execute(mem turing.Memory, _ turing.Cache) error {
+	// get start and count from continuation
+	start := d.Start
+	d.Deleted = 0
+	if resume := mem.Continuation(); resume != nil {
+		err := fpack.Decode(resume, func(dec *fpack.Decoder) error {
+			d.Deleted = dec.VarInt()
+			start = dec.Tail(true)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// collect keys of the next step
+	var keys [][]byte
+	iter := mem.Range(start, d.End, turing.RangeOptions{Limit: deleteRangeStep + 1})
+	for iter.First(); iter.Valid(); iter.Next() {
+		keys = append(keys, turing.Clone(iter.TempKey()))
+	}
+
+	// close iterator
+	err := iter.Close()
+	if err != nil {
+		return err
+	}
+
+	// delete keys
+	for i, key := range keys {
+		// continue with next step
+		if i == deleteRangeStep {
+			return d.suspend(mem, key)
+		}
+
+		// unset key
+		err = mem.Unset(key)
+		if err == turing.ErrMaxEffect {
+			return d.suspend(mem, key)
+		} else if err != nil {
+			return err
+		}
+
+		// increment
+		d.Deleted++
+	}
+
+	return nil
+}
+
+func (d *DeleteRange) suspend(mem turing.Memory, key []byte) error {
+	// encode continuation
+	state, ref, err := fpack.Encode(true, func(enc *fpack.Encoder) error {
+		enc.VarInt(d.Deleted)
+		enc.Tail(key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// ensure release
+	defer ref.Release()
+
+	// record continuation
+	err = mem.Continue(state)
+	if err != nil {
+		return err
+	}
+
+	return turing.ErrMaxEffect
+}
+
+// Encode implements the turing.Instruction interface.
+func (d *DeleteRange) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarInt(d.Deleted)
+		enc.VarBytes(d.Start)
+		enc.Tail(d.End)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (d *DeleteRange) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode delete range: invalid version")
+		}
+
+		// decode body
+		d.Deleted = dec.VarInt()
+		d.Start = dec.VarBytes(true)
+		d.End = dec.Tail(true)
+
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+package stdset
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestDeleteRange(t *testing.T) {
+	machine := turing.Test(&DeleteRange{}, &Set{}, &List{})
+	defer machine.Stop()
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		err := machine.Execute(&Set{
+			Key:   []byte(key),
+			Value: []byte(key),
+		})
+		assert.NoError(t, err)
+	}
+
+	del := &DeleteRange{
+		Start: []byte("b"),
+		End:   []byte("d"),
+	}
+	err := machine.Execute(del)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), del.Deleted)
+
+	list := &List{}
+	err = machine.Execute(list)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("d")}, list.Keys)
+
+	err = machine.Execute(del)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), del.Deleted)
+}
+
+func TestDeleteRangeSteps(t *testing.T) {
+	machine, err := turing.Start(turing.Config{
+		Instructions: []turing.Instruction{&DeleteRange{}, &Set{}, &List{}},
+		Standalone:   true,
+		MaxEffect:    5,
+	})
+	assert.NoError(t, err)
+	defer machine.Stop()
+
+	for i := 0; i < deleteRangeStep+20; i++ {
+		err := machine.Execute(&Set{
+			Key:   []byte(fmt.Sprintf("k%04d", i)),
+			Value: []byte("x"),
+		})
+		assert.NoError(t, err)
+	}
+
+	del := &DeleteRange{
+		Start: []byte("k0002"),
+	}
+	err = machine.Execute(del)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(deleteRangeStep+18), del.Deleted)
+
+	list := &List{}
+	err = machine.Execute(list)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("k0000"), []byte("k0001")}, list.Keys)
+}
+
+func BenchmarkDeleteRange(b *testing.B) {
+	machine := turing.Test(&DeleteRange{})
+	defer machine.Stop()
+
+	del := &DeleteRange{
+		Start: []byte("a"),
+		End:   []byte("b"),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(del)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
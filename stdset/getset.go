@@ -0,0 +1,87 @@
+package stdset
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// GetSet will set a value and return the previous value.
+type GetSet struct {
+	Key     []byte
+	Value   []byte
+	Old     []byte
+	Existed bool
+}
+
+var getSetDesc = &turing.Description{
+	Name: "turing/GetSet",
+}
+
+// Describe implements the turing.Instruction interface.
+func (g *GetSet) Describe() *turing.Description {
+	return getSetDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (g *GetSet) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (g *GetSet) Execute(mem turing.Memory, _ turing.Cache) error {
+	// get old value
+	g.Old = nil
+	g.Existed = false
+	err := mem.Use(g.Key, func(value []byte) error {
+		g.Old = turing.Clone(value)
+		g.Existed = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// set pair
+	err = mem.Set(g.Key, g.Value)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (g *GetSet) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Bool(g.Existed)
+		enc.VarBytes(g.Key)
+		enc.VarBytes(g.Value)
+		enc.Tail(g.Old)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (g *GetSet) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode getset: invalid version")
+		}
+
+		// decode body
+		g.Existed = dec.Bool()
+		g.Key = dec.VarBytes(true)
+		g.Value = dec.VarBytes(true)
+		g.Old = dec.Tail(true)
+
+		return nil
+	})
+}
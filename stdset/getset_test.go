@@ -0,0 +1,54 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestGetSet(t *testing.T) {
+	machine := turing.Test(&GetSet{})
+	defer machine.Stop()
+
+	getset := &GetSet{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	}
+
+	err := machine.Execute(getset)
+	assert.NoError(t, err)
+	assert.False(t, getset.Existed)
+	assert.Nil(t, getset.Old)
+
+	getset = &GetSet{
+		Key:   []byte("foo"),
+		Value: []byte("baz"),
+	}
+
+	err = machine.Execute(getset)
+	assert.NoError(t, err)
+	assert.True(t, getset.Existed)
+	assert.Equal(t, []byte("bar"), getset.Old)
+}
+
+func BenchmarkGetSet(b *testing.B) {
+	machine := turing.Test(&GetSet{})
+	defer machine.Stop()
+
+	getset := &GetSet{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(getset)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
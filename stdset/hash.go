@@ -0,0 +1,18 @@
+package stdset
+
+import "hash/fnv"
+
+func hashItem(item []byte, seed uint64) uint64 {
+	// hash item
+	h := fnv.New64a()
+	_, _ = h.Write(item)
+	x := h.Sum64() ^ seed
+
+	// finalize using splitmix64 to improve the distribution
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	x = x ^ (x >> 31)
+
+	return x
+}
@@ -0,0 +1,111 @@
+package hash
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Delete will delete fields of a hash (HDEL).
+type Delete struct {
+	Key     []byte
+	Fields  [][]byte
+	Deleted int
+}
+
+var deleteDesc = &turing.Description{
+	Name: "turing/hash/Delete",
+}
+
+// Describe implements the turing.Instruction interface.
+func (d *Delete) Describe() *turing.Description {
+	return deleteDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (d *Delete) Effect() int {
+	return len(d.Fields)
+}
+
+// Execute implements the turing.Instruction interface.
+func (d *Delete) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check key
+	err := checkKey(d.Key)
+	if err != nil {
+		return err
+	}
+
+	// delete fields
+	d.Deleted = 0
+	for _, field := range d.Fields {
+		// check existence
+		key := fieldKey(d.Key, field)
+		exists := false
+		err = mem.Use(key, func([]byte) error {
+			exists = true
+			return nil
+		})
+		if err != nil {
+			return err
+		} else if !exists {
+			continue
+		}
+
+		// unset field
+		err = mem.Unset(key)
+		if err != nil {
+			return err
+		}
+
+		d.Deleted++
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (d *Delete) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode deleted
+		enc.VarUint(uint64(d.Deleted))
+
+		// encode key
+		enc.VarBytes(d.Key)
+
+		// encode fields
+		enc.VarUint(uint64(len(d.Fields)))
+		for _, field := range d.Fields {
+			enc.VarBytes(field)
+		}
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (d *Delete) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("hash: decode delete: invalid version")
+		}
+
+		// decode deleted
+		d.Deleted = int(dec.VarUint())
+
+		// decode key
+		d.Key = dec.VarBytes(true)
+
+		// decode fields
+		d.Fields = make([][]byte, dec.VarUint())
+		for i := range d.Fields {
+			d.Fields[i] = dec.VarBytes(true)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,30 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelete(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	err := machine.Execute(&Set{Key: []byte("foo"), Fields: []Field{
+		{Field: []byte("a"), Value: []byte("1")},
+		{Field: []byte("b"), Value: []byte("2")},
+	}})
+	assert.NoError(t, err)
+
+	del := &Delete{Key: []byte("foo"), Fields: [][]byte{[]byte("a"), []byte("c")}}
+	err = machine.Execute(del)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, del.Deleted)
+
+	all := &GetAll{Key: []byte("foo")}
+	err = machine.Execute(all)
+	assert.NoError(t, err)
+	assert.Equal(t, []Field{
+		{Field: []byte("b"), Value: []byte("2")},
+	}, all.Fields)
+}
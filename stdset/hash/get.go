@@ -0,0 +1,89 @@
+package hash
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Get will get a single field of a hash (HGET).
+type Get struct {
+	Key    []byte
+	Field  []byte
+	Value  []byte
+	Exists bool
+}
+
+var getDesc = &turing.Description{
+	Name: "turing/hash/Get",
+}
+
+// Describe implements the turing.Instruction interface.
+func (g *Get) Describe() *turing.Description {
+	return getDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (g *Get) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (g *Get) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check key
+	err := checkKey(g.Key)
+	if err != nil {
+		return err
+	}
+
+	// reset result
+	g.Value = nil
+	g.Exists = false
+
+	// get field
+	err = mem.Use(fieldKey(g.Key, g.Field), func(value []byte) error {
+		g.Value = turing.Clone(value)
+		g.Exists = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (g *Get) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Bool(g.Exists)
+		enc.VarBytes(g.Key)
+		enc.VarBytes(g.Field)
+		enc.Tail(g.Value)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (g *Get) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("hash: decode get: invalid version")
+		}
+
+		// decode body
+		g.Exists = dec.Bool()
+		g.Key = dec.VarBytes(true)
+		g.Field = dec.VarBytes(true)
+		g.Value = dec.Tail(true)
+
+		return nil
+	})
+}
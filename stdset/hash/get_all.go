@@ -0,0 +1,98 @@
+package hash
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// GetAll will get all fields of a hash ordered by field (HGETALL).
+type GetAll struct {
+	Key    []byte
+	Fields []Field
+}
+
+var getAllDesc = &turing.Description{
+	Name: "turing/hash/GetAll",
+}
+
+// Describe implements the turing.Instruction interface.
+func (g *GetAll) Describe() *turing.Description {
+	return getAllDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (g *GetAll) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (g *GetAll) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check key
+	err := checkKey(g.Key)
+	if err != nil {
+		return err
+	}
+
+	// reset fields
+	g.Fields = nil
+
+	// create iterator
+	prefix := makePrefix(g.Key)
+	iter := mem.Iterate(prefix)
+
+	// collect fields
+	for iter.First(); iter.Valid(); iter.Next() {
+		// get value
+		value, err := iter.TempValue()
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		// add field
+		g.Fields = append(g.Fields, Field{
+			Field: turing.Clone(iter.TempKey()[len(prefix):]),
+			Value: turing.Clone(value),
+		})
+	}
+
+	// close iterator
+	err = iter.Close()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (g *GetAll) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarBytes(g.Key)
+		encodeFields(enc, g.Fields)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (g *GetAll) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("hash: decode get all: invalid version")
+		}
+
+		// decode body
+		g.Key = dec.VarBytes(true)
+		g.Fields = decodeFields(dec)
+
+		return nil
+	})
+}
@@ -0,0 +1,36 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAll(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	err := machine.Execute(&Set{Key: []byte("foo"), Fields: []Field{
+		{Field: []byte("b"), Value: []byte("2")},
+		{Field: []byte("a"), Value: []byte("1")},
+	}})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&Set{Key: []byte("fo"), Fields: []Field{
+		{Field: []byte("o"), Value: []byte("3")},
+	}})
+	assert.NoError(t, err)
+
+	all := &GetAll{Key: []byte("foo")}
+	err = machine.Execute(all)
+	assert.NoError(t, err)
+	assert.Equal(t, []Field{
+		{Field: []byte("a"), Value: []byte("1")},
+		{Field: []byte("b"), Value: []byte("2")},
+	}, all.Fields)
+
+	all = &GetAll{Key: []byte("bar")}
+	err = machine.Execute(all)
+	assert.NoError(t, err)
+	assert.Empty(t, all.Fields)
+}
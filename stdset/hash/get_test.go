@@ -0,0 +1,28 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	get := &Get{Key: []byte("foo"), Field: []byte("a")}
+	err := machine.Execute(get)
+	assert.NoError(t, err)
+	assert.False(t, get.Exists)
+	assert.Nil(t, get.Value)
+
+	err = machine.Execute(&Set{Key: []byte("foo"), Fields: []Field{
+		{Field: []byte("a"), Value: []byte("1")},
+	}})
+	assert.NoError(t, err)
+
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.True(t, get.Exists)
+	assert.Equal(t, []byte("1"), get.Value)
+}
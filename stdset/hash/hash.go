@@ -0,0 +1,82 @@
+// Package hash provides instructions that implement hashes modeled after the
+// Redis hash commands.
+//
+// Every hash is stored under a key prefix derived from its name that starts with
+// a namespace reserved for hashes. Each field is stored in a composite key
+// consisting of the prefix and the field.
+package hash
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing/stdset"
+)
+
+// ErrInvalidKey is returned if a hash key is empty or too long.
+var ErrInvalidKey = fmt.Errorf("hash: invalid key")
+
+// Namespace is the prefix of all keys used by hashes.
+const Namespace = stdset.Reserved + "h"
+
+const fieldKind = 'f'
+
+// Field is a single field of a hash.
+type Field struct {
+	Field []byte
+	Value []byte
+}
+
+func checkKey(key []byte) error {
+	// check length
+	if len(key) == 0 || len(key) > 0xffff {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
+
+func makePrefix(key []byte) []byte {
+	// prepare prefix
+	n := len(Namespace)
+	prefix := make([]byte, n+2+len(key)+1)
+	copy(prefix, Namespace)
+	binary.BigEndian.PutUint16(prefix[n:], uint16(len(key)))
+	copy(prefix[n+2:], key)
+	prefix[n+2+len(key)] = fieldKind
+
+	return prefix
+}
+
+func fieldKey(key, field []byte) []byte {
+	return append(makePrefix(key), field...)
+}
+
+func encodeFields(enc *fpack.Encoder, fields []Field) {
+	// encode length
+	enc.VarUint(uint64(len(fields)))
+
+	// encode fields
+	for _, field := range fields {
+		enc.VarBytes(field.Field)
+		enc.VarBytes(field.Value)
+	}
+}
+
+func decodeFields(dec *fpack.Decoder) []Field {
+	// decode length
+	length := dec.VarUint()
+	if length == 0 {
+		return nil
+	}
+
+	// decode fields
+	fields := make([]Field, length)
+	for i := range fields {
+		fields[i].Field = dec.VarBytes(true)
+		fields[i].Value = dec.VarBytes(true)
+	}
+
+	return fields
+}
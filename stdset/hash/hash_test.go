@@ -0,0 +1,27 @@
+package hash
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestMain(m *testing.M) {
+	// disable logging
+	turing.SetLogger(nil)
+
+	// run tests
+	os.Exit(m.Run())
+}
+
+func testMachine() *turing.Machine {
+	return turing.Test(&Set{}, &Get{}, &GetAll{}, &Delete{})
+}
+
+func TestKeys(t *testing.T) {
+	assert.Equal(t, []byte{0, 'h', 0, 3, 'f', 'o', 'o', 'f', 'b', 'a', 'r'}, fieldKey([]byte("foo"), []byte("bar")))
+	assert.Equal(t, ErrInvalidKey, checkKey(nil))
+}
@@ -0,0 +1,98 @@
+package hash
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Set will set fields of a hash (HSET).
+type Set struct {
+	Key    []byte
+	Fields []Field
+	Added  int
+}
+
+var setDesc = &turing.Description{
+	Name: "turing/hash/Set",
+}
+
+// Describe implements the turing.Instruction interface.
+func (s *Set) Describe() *turing.Description {
+	return setDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (s *Set) Effect() int {
+	return len(s.Fields)
+}
+
+// Execute implements the turing.Instruction interface.
+func (s *Set) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check key
+	err := checkKey(s.Key)
+	if err != nil {
+		return err
+	}
+
+	// set fields
+	s.Added = 0
+	for _, field := range s.Fields {
+		// check existence
+		key := fieldKey(s.Key, field.Field)
+		exists := false
+		err = mem.Use(key, func([]byte) error {
+			exists = true
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// set field
+		err = mem.Set(key, field.Value)
+		if err != nil {
+			return err
+		}
+
+		// increment
+		if !exists {
+			s.Added++
+		}
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (s *Set) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarUint(uint64(s.Added))
+		enc.VarBytes(s.Key)
+		encodeFields(enc, s.Fields)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (s *Set) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("hash: decode set: invalid version")
+		}
+
+		// decode body
+		s.Added = int(dec.VarUint())
+		s.Key = dec.VarBytes(true)
+		s.Fields = decodeFields(dec)
+
+		return nil
+	})
+}
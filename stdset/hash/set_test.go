@@ -0,0 +1,58 @@
+package hash
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	set := &Set{Key: []byte("foo"), Fields: []Field{
+		{Field: []byte("a"), Value: []byte("1")},
+		{Field: []byte("b"), Value: []byte("2")},
+	}}
+	err := machine.Execute(set)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, set.Added)
+
+	set = &Set{Key: []byte("foo"), Fields: []Field{
+		{Field: []byte("b"), Value: []byte("3")},
+		{Field: []byte("c"), Value: []byte("4")},
+	}}
+	err = machine.Execute(set)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, set.Added)
+
+	get := &Get{Key: []byte("foo"), Field: []byte("b")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.True(t, get.Exists)
+	assert.Equal(t, []byte("3"), get.Value)
+
+	err = machine.Execute(&Set{})
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+func BenchmarkSet(b *testing.B) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(&Set{
+			Key: []byte("foo"),
+			Fields: []Field{
+				{Field: []byte(strconv.Itoa(i)), Value: []byte("bar")},
+			},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package stdset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+const (
+	hllPrecision = 12
+	hllRegisters = 1 << hllPrecision
+)
+
+const (
+	sketchSparse = 0
+	sketchDense  = 1
+)
+
+// HLL is an operator used by HLLAdd to maintain a HyperLogLog with 4096
+// registers (~1.6% standard error). Values are stored as a dense list of
+// registers while operands may be sparse lists of register updates.
+var HLL = &turing.Operator{
+	Name: "turing/HLL",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		// borrow registers
+		registers, ref := fpack.Borrow(hllRegisters)
+
+		// copy value
+		if len(value) == hllRegisters {
+			copy(registers, value)
+		} else {
+			for i := range registers {
+				registers[i] = 0
+			}
+		}
+
+		// apply operands
+		for _, op := range ops {
+			err := walkHLL(op, func(index uint16, rank uint8) {
+				if rank > registers[index] {
+					registers[index] = rank
+				}
+			})
+			if err != nil {
+				ref.Release()
+				return nil, nil, err
+			}
+		}
+
+		return registers, ref, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		// collect updates
+		updates := map[uint16]uint8{}
+		for _, op := range ops {
+			err := walkHLL(op, func(index uint16, rank uint8) {
+				if rank > updates[index] {
+					updates[index] = rank
+				}
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// encode dense operand if smaller
+		if len(updates)*3 >= hllRegisters {
+			buf := make([]byte, 1+hllRegisters)
+			buf[0] = sketchDense
+			for index, rank := range updates {
+				buf[1+int(index)] = rank
+			}
+
+			return buf, nil, nil
+		}
+
+		// sort indexes
+		indexes := make([]int, 0, len(updates))
+		for index := range updates {
+			indexes = append(indexes, int(index))
+		}
+		sort.Ints(indexes)
+
+		// encode sparse operand
+		buf := make([]byte, 1, 1+len(indexes)*3)
+		buf[0] = sketchSparse
+		for _, index := range indexes {
+			buf = append(buf, byte(index>>8), byte(index), updates[uint16(index)])
+		}
+
+		return buf, nil, nil
+	},
+}
+
+func walkHLL(op []byte, fn func(index uint16, rank uint8)) error {
+	// check length
+	if len(op) == 0 {
+		return fmt.Errorf("stdset: invalid hll operand")
+	}
+
+	// handle type
+	switch op[0] {
+	case sketchSparse:
+		// check length
+		if (len(op)-1)%3 != 0 {
+			return fmt.Errorf("stdset: invalid sparse hll operand")
+		}
+
+		// yield updates
+		for i := 1; i < len(op); i += 3 {
+			index := binary.BigEndian.Uint16(op[i:])
+			if index >= hllRegisters {
+				return fmt.Errorf("stdset: invalid sparse hll operand")
+			}
+			fn(index, op[i+2])
+		}
+	case sketchDense:
+		// check length
+		if len(op) != 1+hllRegisters {
+			return fmt.Errorf("stdset: invalid dense hll operand")
+		}
+
+		// yield registers
+		for i, rank := range op[1:] {
+			if rank > 0 {
+				fn(uint16(i), rank)
+			}
+		}
+	default:
+		return fmt.Errorf("stdset: invalid hll operand")
+	}
+
+	return nil
+}
+
+func hllOperand(items [][]byte) []byte {
+	// prepare operand
+	op := make([]byte, 1, 1+len(items)*3)
+	op[0] = sketchSparse
+
+	// add updates
+	for _, item := range items {
+		hash := hashItem(item, 0)
+		index := uint16(hash >> (64 - hllPrecision))
+		rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1)
+		op = append(op, byte(index>>8), byte(index), rank)
+	}
+
+	return op
+}
+
+// EstimateHLL will estimate the cardinality of the provided HyperLogLog value.
+func EstimateHLL(value []byte) uint64 {
+	// check length
+	if len(value) != hllRegisters {
+		return 0
+	}
+
+	// compute harmonic mean
+	var sum float64
+	var zeros int
+	for _, rank := range value {
+		sum += 1 / float64(uint64(1)<<rank)
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	// compute raw estimate
+	m := float64(hllRegisters)
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+
+	// use linear counting for small cardinalities
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(estimate + 0.5)
+}
+
+// HLLAdd will add items to a HyperLogLog.
+type HLLAdd struct {
+	Key   []byte
+	Items [][]byte
+}
+
+var hllAddDesc = &turing.Description{
+	Name:      "turing/HLLAdd",
+	Operators: []*turing.Operator{HLL},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (a *HLLAdd) Describe() *turing.Description {
+	return hllAddDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (a *HLLAdd) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (a *HLLAdd) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mem.Merge(a.Key, hllOperand(a.Items), HLL)
+}
+
+// Encode implements the turing.Instruction interface.
+func (a *HLLAdd) Encode() ([]byte, turing.Ref, error) {
+	return encodeElements(a.Key, a.Items)
+}
+
+// Decode implements the turing.Instruction interface.
+func (a *HLLAdd) Decode(bytes []byte) error {
+	return decodeElements(bytes, "hll add", &a.Key, &a.Items)
+}
+
+// HLLEstimate will estimate the cardinality of a HyperLogLog.
+type HLLEstimate struct {
+	Key   []byte
+	Count uint64
+}
+
+var hllEstimateDesc = &turing.Description{
+	Name: "turing/HLLEstimate",
+}
+
+// Describe implements the turing.Instruction interface.
+func (e *HLLEstimate) Describe() *turing.Description {
+	return hllEstimateDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (e *HLLEstimate) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (e *HLLEstimate) Execute(mem turing.Memory, _ turing.Cache) error {
+	// estimate cardinality
+	e.Count = 0
+	return mem.Use(e.Key, func(value []byte) error {
+		e.Count = EstimateHLL(value)
+		return nil
+	})
+}
+
+// Encode implements the turing.Instruction interface.
+func (e *HLLEstimate) Encode() ([]byte, turing.Ref, error) {
+	return encodeNumber(e.Key, e.Count)
+}
+
+// Decode implements the turing.Instruction interface.
+func (e *HLLEstimate) Decode(bytes []byte) error {
+	return decodeNumber(bytes, "hll estimate", &e.Key, &e.Count)
+}
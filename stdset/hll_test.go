@@ -0,0 +1,68 @@
+package stdset
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestHLL(t *testing.T) {
+	var operands [][]byte
+	for i := 0; i < 3; i++ {
+		var items [][]byte
+		for j := 0; j < 1000; j++ {
+			items = append(items, []byte(strconv.Itoa(i*500+j)))
+		}
+
+		operands = append(operands, hllOperand(items))
+	}
+
+	value := checkOperator(t, HLL, operands)
+	assert.Len(t, value, hllRegisters)
+	assert.InEpsilon(t, 2000, float64(EstimateHLL(value)), 0.05)
+}
+
+func TestHLLAdd(t *testing.T) {
+	machine := turing.Test(&HLLAdd{}, &HLLEstimate{})
+	defer machine.Stop()
+
+	estimate := &HLLEstimate{Key: []byte("foo")}
+	err := machine.Execute(estimate)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), estimate.Count)
+
+	for i := 0; i < 10; i++ {
+		add := &HLLAdd{Key: []byte("foo")}
+		for j := 0; j < 100; j++ {
+			add.Items = append(add.Items, []byte(strconv.Itoa(i*100+j)))
+		}
+
+		err = machine.Execute(add)
+		assert.NoError(t, err)
+	}
+
+	err = machine.Execute(estimate)
+	assert.NoError(t, err)
+	assert.InEpsilon(t, 1000, float64(estimate.Count), 0.05)
+}
+
+func BenchmarkHLLAdd(b *testing.B) {
+	machine := turing.Test(&HLLAdd{})
+	defer machine.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(&HLLAdd{
+			Key:   []byte("foo"),
+			Items: [][]byte{[]byte(strconv.Itoa(i))},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
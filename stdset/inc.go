@@ -81,6 +81,11 @@ func (i *Inc) Effect() int {
 	return 1
 }
 
+// Scope implements the turing.Scoped interface.
+func (i *Inc) Scope() ([][]byte, [][]byte) {
+	return [][]byte{i.Key}, [][]byte{i.Key}
+}
+
 // Execute implements the turing.Instruction interface.
 func (i *Inc) Execute(mem turing.Memory, _ turing.Cache) error {
 	// borrow slice
@@ -129,3 +134,116 @@ func (i *Inc) Decode(bytes []byte) error {
 		return nil
 	})
 }
+
+// IncGet will increment a numerical value and return the result. If the
+// current value is not an integer, it is left untouched and Invalid is set.
+type IncGet struct {
+	Key     []byte
+	Value   int64
+	Result  int64
+	Invalid bool
+}
+
+var incGetDesc = &turing.Description{
+	Name:      "turing/IncGet",
+	Operators: []*turing.Operator{Add},
+}
+
+// Describe implements the turing.Instruction interface.
+func (i *IncGet) Describe() *turing.Description {
+	return incGetDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (i *IncGet) Effect() int {
+	return 1
+}
+
+// Scope implements the turing.Scoped interface.
+func (i *IncGet) Scope() ([][]byte, [][]byte) {
+	return [][]byte{i.Key}, [][]byte{i.Key}
+}
+
+// Execute implements the turing.Instruction interface.
+func (i *IncGet) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check current value
+	i.Invalid = false
+	err := mem.Use(i.Key, func(value []byte) error {
+		_, err := strconv.ParseInt(cast.ToString(value), 10, 64)
+		i.Invalid = err != nil
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// stop if invalid
+	if i.Invalid {
+		i.Result = 0
+		return nil
+	}
+
+	// borrow slice
+	buf, ref := fpack.Borrow(int64Len)
+	defer ref.Release()
+
+	// encode count
+	buf = buf[:0]
+	buf = strconv.AppendInt(buf, i.Value, 10)
+
+	// add value
+	err = mem.Merge(i.Key, buf, Add)
+	if err != nil {
+		return err
+	}
+
+	// get result
+	i.Result = 0
+	err = mem.Use(i.Key, func(value []byte) error {
+		i.Result, _ = strconv.ParseInt(cast.ToString(value), 10, 64)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (i *IncGet) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(2)
+
+		// encode body
+		enc.Int64(i.Value)
+		enc.Int64(i.Result)
+		enc.Bool(i.Invalid)
+		enc.Tail(i.Key)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (i *IncGet) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		version := dec.Uint8()
+		if version != 1 && version != 2 {
+			return fmt.Errorf("stdset: decode inc get: invalid version")
+		}
+
+		// decode body
+		i.Value = dec.Int64()
+		i.Result = dec.Int64()
+		i.Invalid = false
+		if version >= 2 {
+			i.Invalid = dec.Bool()
+		}
+		i.Key = dec.Tail(true)
+
+		return nil
+	})
+}
@@ -41,6 +41,58 @@ func TestInc(t *testing.T) {
 	err = machine.Execute(&get)
 	assert.NoError(t, err)
 	assert.Equal(t, []byte("6"), get.Value)
+
+	reads, writes := (&Inc{Key: []byte("foo")}).Scope()
+	assert.Equal(t, [][]byte{[]byte("foo")}, reads)
+	assert.Equal(t, [][]byte{[]byte("foo")}, writes)
+}
+
+func TestIncGet(t *testing.T) {
+	machine := turing.Test(&Inc{}, &IncGet{}, &Set{}, &Get{})
+	defer machine.Stop()
+
+	inc := &IncGet{
+		Key:   []byte("foo"),
+		Value: 2,
+	}
+	err := machine.Execute(inc)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), inc.Result)
+
+	err = machine.Execute(&Inc{
+		Key:   []byte("foo"),
+		Value: 3,
+	})
+	assert.NoError(t, err)
+
+	inc = &IncGet{
+		Key:   []byte("foo"),
+		Value: -7,
+	}
+	err = machine.Execute(inc)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-2), inc.Result)
+	assert.False(t, inc.Invalid)
+
+	err = machine.Execute(&Set{
+		Key:   []byte("bar"),
+		Value: []byte("baz"),
+	})
+	assert.NoError(t, err)
+
+	inc = &IncGet{
+		Key:   []byte("bar"),
+		Value: 1,
+	}
+	err = machine.Execute(inc)
+	assert.NoError(t, err)
+	assert.True(t, inc.Invalid)
+	assert.Equal(t, int64(0), inc.Result)
+
+	get := &Get{Key: []byte("bar")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("baz"), get.Value)
 }
 
 func BenchmarkInc(b *testing.B) {
@@ -0,0 +1,140 @@
+package stdset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// LWW is an operator used by SetLatest to retain the value with the latest
+// timestamp. Values are encoded as an 8 byte big endian timestamp followed by
+// the data, see EncodeLWW and DecodeLWW. Ties are broken by comparing the data
+// lexicographically. Empty values are treated as absent.
+var LWW = &turing.Operator{
+	Name: "turing/LWW",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := latest(value, ops)
+		return buf, ref, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := latest(nil, ops)
+		return buf, ref, nil
+	},
+}
+
+func latest(value []byte, ops [][]byte) ([]byte, turing.Ref) {
+	// pick latest value
+	for _, op := range ops {
+		if len(op) >= 8 && (len(value) < 8 || compareLWW(op, value) > 0) {
+			value = op
+		}
+	}
+
+	// copy value
+	return fpack.Clone(value)
+}
+
+func compareLWW(a, b []byte) int {
+	// compare timestamps
+	ta, tb := binary.BigEndian.Uint64(a), binary.BigEndian.Uint64(b)
+	if ta > tb {
+		return 1
+	} else if ta < tb {
+		return -1
+	}
+
+	return bytes.Compare(a[8:], b[8:])
+}
+
+// EncodeLWW will encode a timestamped value.
+func EncodeLWW(timestamp uint64, data []byte) []byte {
+	// encode value
+	buf := make([]byte, 8+len(data))
+	binary.BigEndian.PutUint64(buf, timestamp)
+	copy(buf[8:], data)
+
+	return buf
+}
+
+// DecodeLWW will decode a timestamped value. The returned data is not copied
+// and references the provided slice.
+func DecodeLWW(value []byte) (uint64, []byte, error) {
+	// check length
+	if len(value) < 8 {
+		return 0, nil, fmt.Errorf("stdset: decode lww: invalid length")
+	}
+
+	return binary.BigEndian.Uint64(value), value[8:], nil
+}
+
+// SetLatest will set a value if its timestamp is later than the timestamp of
+// the current value. If no timestamp is provided, the proposal time of the
+// instruction is used.
+type SetLatest struct {
+	Key       []byte
+	Value     []byte
+	Timestamp uint64
+}
+
+var setLatestDesc = &turing.Description{
+	Name:      "turing/SetLatest",
+	Operators: []*turing.Operator{LWW},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (s *SetLatest) Describe() *turing.Description {
+	return setLatestDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (s *SetLatest) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (s *SetLatest) Execute(mem turing.Memory, _ turing.Cache) error {
+	// get timestamp
+	timestamp := s.Timestamp
+	if timestamp == 0 {
+		timestamp = uint64(mem.Now().UnixNano())
+	}
+
+	return mem.Merge(s.Key, EncodeLWW(timestamp, s.Value), LWW)
+}
+
+// Encode implements the turing.Instruction interface.
+func (s *SetLatest) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Uint64(s.Timestamp)
+		enc.VarBytes(s.Key)
+		enc.Tail(s.Value)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (s *SetLatest) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode set latest: invalid version")
+		}
+
+		// decode body
+		s.Timestamp = dec.Uint64()
+		s.Key = dec.VarBytes(true)
+		s.Value = dec.Tail(true)
+
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestLWW(t *testing.T) {
+	operands := [][]byte{
+		EncodeLWW(2, []byte("b")),
+		EncodeLWW(3, []byte("a")),
+		EncodeLWW(1, []byte("c")),
+		EncodeLWW(3, []byte("b")),
+	}
+
+	timestamp, data, err := DecodeLWW(checkOperator(t, LWW, operands))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), timestamp)
+	assert.Equal(t, []byte("b"), data)
+}
+
+func TestSetLatest(t *testing.T) {
+	machine := turing.Test(&SetLatest{}, &Get{})
+	defer machine.Stop()
+
+	err := machine.Execute(&SetLatest{
+		Key:       []byte("foo"),
+		Value:     []byte("bar"),
+		Timestamp: 2,
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&SetLatest{
+		Key:       []byte("foo"),
+		Value:     []byte("baz"),
+		Timestamp: 1,
+	})
+	assert.NoError(t, err)
+
+	get := &Get{Key: []byte("foo")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+
+	timestamp, data, err := DecodeLWW(get.Value)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), timestamp)
+	assert.Equal(t, []byte("bar"), data)
+
+	err = machine.Execute(&SetLatest{
+		Key:   []byte("foo"),
+		Value: []byte("qux"),
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+
+	_, data, err = DecodeLWW(get.Value)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("qux"), data)
+}
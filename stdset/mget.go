@@ -0,0 +1,107 @@
+package stdset
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// MGet will get multiple values.
+type MGet struct {
+	Keys   [][]byte
+	Values [][]byte
+	Exists []bool
+}
+
+var mgetDesc = &turing.Description{
+	Name: "turing/MGet",
+}
+
+// Describe implements the turing.Instruction interface.
+func (g *MGet) Describe() *turing.Description {
+	return mgetDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (g *MGet) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (g *MGet) Execute(mem turing.Memory, _ turing.Cache) error {
+	// prepare lists
+	g.Values = make([][]byte, len(g.Keys))
+	g.Exists = make([]bool, len(g.Keys))
+
+	// get values
+	err := mem.GetMany(g.Keys, func(i int, value []byte) error {
+		g.Values[i] = turing.Clone(value)
+		g.Exists[i] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (g *MGet) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode length
+		enc.VarUint(uint64(len(g.Keys)))
+
+		// encode keys
+		for _, key := range g.Keys {
+			enc.VarBytes(key)
+		}
+
+		// encode length
+		enc.VarUint(uint64(len(g.Values)))
+
+		// encode values
+		for i, value := range g.Values {
+			enc.Bool(g.Exists[i])
+			enc.VarBytes(value)
+		}
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (g *MGet) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode mget: invalid version")
+		}
+
+		// decode length
+		length := dec.VarUint()
+
+		// decode keys
+		g.Keys = make([][]byte, length)
+		for i := 0; i < int(length); i++ {
+			g.Keys[i] = dec.VarBytes(true)
+		}
+
+		// decode length
+		length = dec.VarUint()
+
+		// decode values
+		g.Values = make([][]byte, length)
+		g.Exists = make([]bool, length)
+		for i := 0; i < int(length); i++ {
+			g.Exists[i] = dec.Bool()
+			g.Values[i] = dec.VarBytes(true)
+		}
+
+		return nil
+	})
+}
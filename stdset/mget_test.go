@@ -0,0 +1,67 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestMGet(t *testing.T) {
+	machine := turing.Test(&MGet{}, &Set{})
+	defer machine.Stop()
+
+	mget := &MGet{
+		Keys: [][]byte{[]byte("foo"), []byte("bar"), []byte("baz")},
+	}
+
+	err := machine.Execute(mget)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, false, false}, mget.Exists)
+	assert.Equal(t, [][]byte{nil, nil, nil}, mget.Values)
+
+	err = machine.Execute(&Set{
+		Key:   []byte("foo"),
+		Value: []byte("1"),
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&Set{
+		Key:   []byte("baz"),
+		Value: []byte("3"),
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(mget)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, mget.Exists)
+	assert.Equal(t, [][]byte{[]byte("1"), nil, []byte("3")}, mget.Values)
+}
+
+func BenchmarkMGet(b *testing.B) {
+	machine := turing.Test(&MGet{}, &Set{})
+	defer machine.Stop()
+
+	err := machine.Execute(&Set{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	mget := &MGet{
+		Keys: [][]byte{[]byte("foo"), []byte("bar")},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(mget)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package stdset
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Max is an operator used by Maximize to retain the biggest value. Values are
+// compared lexicographically, numbers should therefore use an order preserving
+// encoding. Empty values are treated as absent.
+var Max = &turing.Operator{
+	Name: "turing/Max",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := pick(value, ops, 1)
+		return buf, ref, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := pick(nil, ops, 1)
+		return buf, ref, nil
+	},
+}
+
+// Min is an operator used by Minimize to retain the smallest value. Values are
+// compared lexicographically, numbers should therefore use an order preserving
+// encoding. Empty values are treated as absent.
+var Min = &turing.Operator{
+	Name: "turing/Min",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := pick(value, ops, -1)
+		return buf, ref, nil
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := pick(nil, ops, -1)
+		return buf, ref, nil
+	},
+}
+
+func pick(value []byte, ops [][]byte, dir int) ([]byte, turing.Ref) {
+	// pick value
+	for _, op := range ops {
+		if len(op) > 0 && (len(value) == 0 || bytes.Compare(op, value) == dir) {
+			value = op
+		}
+	}
+
+	// copy value
+	return fpack.Clone(value)
+}
+
+// Maximize will set a value if it is bigger than the current value.
+type Maximize struct {
+	Key   []byte
+	Value []byte
+}
+
+var maximizeDesc = &turing.Description{
+	Name:      "turing/Maximize",
+	Operators: []*turing.Operator{Max},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (m *Maximize) Describe() *turing.Description {
+	return maximizeDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (m *Maximize) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (m *Maximize) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mem.Merge(m.Key, m.Value, Max)
+}
+
+// Encode implements the turing.Instruction interface.
+func (m *Maximize) Encode() ([]byte, turing.Ref, error) {
+	return encodePair(m.Key, m.Value)
+}
+
+// Decode implements the turing.Instruction interface.
+func (m *Maximize) Decode(bytes []byte) error {
+	return decodePair(bytes, "maximize", &m.Key, &m.Value)
+}
+
+// Minimize will set a value if it is smaller than the current value.
+type Minimize struct {
+	Key   []byte
+	Value []byte
+}
+
+var minimizeDesc = &turing.Description{
+	Name:      "turing/Minimize",
+	Operators: []*turing.Operator{Min},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (m *Minimize) Describe() *turing.Description {
+	return minimizeDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (m *Minimize) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (m *Minimize) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mem.Merge(m.Key, m.Value, Min)
+}
+
+// Encode implements the turing.Instruction interface.
+func (m *Minimize) Encode() ([]byte, turing.Ref, error) {
+	return encodePair(m.Key, m.Value)
+}
+
+// Decode implements the turing.Instruction interface.
+func (m *Minimize) Decode(bytes []byte) error {
+	return decodePair(bytes, "minimize", &m.Key, &m.Value)
+}
+
+func encodePair(key, value []byte) ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarBytes(key)
+		enc.Tail(value)
+
+		return nil
+	})
+}
+
+func decodePair(bytes []byte, name string, key, value *[]byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode %s: invalid version", name)
+		}
+
+		// decode body
+		*key = dec.VarBytes(true)
+		*value = dec.Tail(true)
+
+		return nil
+	})
+}
@@ -0,0 +1,50 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestMaxMin(t *testing.T) {
+	operands := [][]byte{[]byte("b"), []byte("d"), []byte("a"), []byte("c")}
+	assert.Equal(t, []byte("d"), checkOperator(t, Max, operands))
+	assert.Equal(t, []byte("a"), checkOperator(t, Min, operands))
+}
+
+func TestMaximizeMinimize(t *testing.T) {
+	machine := turing.Test(&Maximize{}, &Minimize{}, &Get{})
+	defer machine.Stop()
+
+	for _, value := range []string{"b", "d", "a", "c"} {
+		err := machine.Execute(&Maximize{
+			Key:   []byte("max"),
+			Value: []byte(value),
+		})
+		assert.NoError(t, err)
+
+		err = machine.Execute(&Minimize{
+			Key:   []byte("min"),
+			Value: []byte(value),
+		})
+		assert.NoError(t, err)
+	}
+
+	get := &Get{
+		Key: []byte("max"),
+	}
+
+	err := machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("d"), get.Value)
+
+	get = &Get{
+		Key: []byte("min"),
+	}
+
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a"), get.Value)
+}
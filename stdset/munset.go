@@ -0,0 +1,118 @@
+package stdset
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// MUnset will remove multiple values atomically. The number of keys must not
+// exceed the configured maximum effect of the machine.
+type MUnset struct {
+	Keys    [][]byte
+	Deleted int
+}
+
+var munsetDesc = &turing.Description{
+	Name: "turing/MUnset",
+}
+
+// Describe implements the turing.Instruction interface.
+func (u *MUnset) Describe() *turing.Description {
+	return munsetDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (u *MUnset) Effect() int {
+	// ensure the instruction is never executed as a read
+	if len(u.Keys) == 0 {
+		return 1
+	}
+
+	return len(u.Keys)
+}
+
+// Scope implements the turing.Scoped interface.
+func (u *MUnset) Scope() ([][]byte, [][]byte) {
+	return u.Keys, u.Keys
+}
+
+// Execute implements the turing.Instruction interface.
+func (u *MUnset) Execute(mem turing.Memory, _ turing.Cache) error {
+	// reset count
+	u.Deleted = 0
+
+	for _, key := range u.Keys {
+		// check existence
+		var existed bool
+		err := mem.Use(key, func([]byte) error {
+			existed = true
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// skip if missing
+		if !existed {
+			continue
+		}
+
+		// unset key
+		err = mem.Unset(key)
+		if err != nil {
+			return err
+		}
+
+		// increment
+		u.Deleted++
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (u *MUnset) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode count
+		enc.VarInt(int64(u.Deleted))
+
+		// encode length
+		enc.VarUint(uint64(len(u.Keys)))
+
+		// encode keys
+		for _, key := range u.Keys {
+			enc.VarBytes(key)
+		}
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (u *MUnset) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode munset: invalid version")
+		}
+
+		// decode count
+		u.Deleted = int(dec.VarInt())
+
+		// decode length
+		length := dec.VarUint()
+
+		// decode keys
+		u.Keys = make([][]byte, length)
+		for i := 0; i < int(length); i++ {
+			u.Keys[i] = dec.VarBytes(true)
+		}
+
+		return nil
+	})
+}
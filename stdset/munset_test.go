@@ -0,0 +1,100 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestMUnset(t *testing.T) {
+	machine := turing.Test(&MUnset{}, &Set{}, &MGet{})
+	defer machine.Stop()
+
+	munset := &MUnset{
+		Keys: [][]byte{[]byte("foo"), []byte("bar"), []byte("foo")},
+	}
+
+	err := machine.Execute(munset)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, munset.Deleted)
+
+	err = machine.Execute(&Set{
+		Key:   []byte("foo"),
+		Value: []byte("1"),
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&Set{
+		Key:   []byte("baz"),
+		Value: []byte("3"),
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(munset)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, munset.Deleted)
+
+	mget := &MGet{
+		Keys: [][]byte{[]byte("foo"), []byte("bar"), []byte("baz")},
+	}
+
+	err = machine.Execute(mget)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{false, false, true}, mget.Exists)
+}
+
+func TestMUnsetEffect(t *testing.T) {
+	machine, err := turing.Start(turing.Config{
+		Instructions: []turing.Instruction{&MUnset{}, &Set{}},
+		Standalone:   true,
+		MaxEffect:    3,
+	})
+	assert.NoError(t, err)
+	defer machine.Stop()
+
+	munset := &MUnset{}
+	assert.Equal(t, 1, munset.Effect())
+
+	err = machine.Execute(munset)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, munset.Deleted)
+
+	for _, key := range []string{"a", "b", "c"} {
+		err = machine.Execute(&Set{Key: []byte(key), Value: []byte(key)})
+		assert.NoError(t, err)
+	}
+
+	munset = &MUnset{
+		Keys: [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")},
+	}
+	err = machine.Execute(munset)
+	assert.Error(t, err)
+
+	munset = &MUnset{
+		Keys: [][]byte{[]byte("a"), []byte("b"), []byte("c")},
+	}
+	err = machine.Execute(munset)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, munset.Deleted)
+}
+
+func BenchmarkMUnset(b *testing.B) {
+	machine := turing.Test(&MUnset{})
+	defer machine.Stop()
+
+	munset := &MUnset{
+		Keys: [][]byte{[]byte("foo"), []byte("bar")},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(munset)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
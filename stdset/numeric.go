@@ -0,0 +1,250 @@
+package stdset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// AddInt64 is an operator used by IncInt64 to add together 8 byte big endian
+// encoded signed integers. Invalid values are treated as zero.
+var AddInt64 = &turing.Operator{
+	Name:    "turing/AddInt64",
+	Zero:    make([]byte, 8),
+	Apply:   applyIntegers,
+	Combine: combineIntegers,
+}
+
+// AddUint64 is an operator used by IncUint64 to add together 8 byte big endian
+// encoded unsigned integers. Invalid values are treated as zero. As two's
+// complement addition wraps around the same way for signed and unsigned
+// integers, the operator shares its functions with AddInt64.
+var AddUint64 = &turing.Operator{
+	Name:    "turing/AddUint64",
+	Zero:    make([]byte, 8),
+	Apply:   applyIntegers,
+	Combine: combineIntegers,
+}
+
+// AddFloat64 is an operator used by IncFloat64 to add together 8 byte big
+// endian encoded floating point numbers. Invalid values are treated as zero.
+// As floating point addition is not associative, the operator does not
+// combine operands and only applies them in order to keep replicas identical.
+var AddFloat64 = &turing.Operator{
+	Name: "turing/AddFloat64",
+	Zero: make([]byte, 8),
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		buf, ref := sumFloats(math.Float64frombits(readUint64(value)), ops)
+		return buf, ref, nil
+	},
+}
+
+func readUint64(value []byte) uint64 {
+	// check length
+	if len(value) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(value)
+}
+
+func applyIntegers(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+	buf, ref := sumIntegers(readUint64(value), ops)
+	return buf, ref, nil
+}
+
+func combineIntegers(ops [][]byte) ([]byte, turing.Ref, error) {
+	buf, ref := sumIntegers(0, ops)
+	return buf, ref, nil
+}
+
+func sumIntegers(sum uint64, ops [][]byte) ([]byte, turing.Ref) {
+	// add operands
+	for _, op := range ops {
+		sum += readUint64(op)
+	}
+
+	// borrow slice
+	buf, ref := fpack.Borrow(8)
+	binary.BigEndian.PutUint64(buf, sum)
+
+	return buf, ref
+}
+
+func sumFloats(sum float64, ops [][]byte) ([]byte, turing.Ref) {
+	// add operands
+	for _, op := range ops {
+		sum += math.Float64frombits(readUint64(op))
+	}
+
+	// borrow slice
+	buf, ref := fpack.Borrow(8)
+	binary.BigEndian.PutUint64(buf, math.Float64bits(sum))
+
+	return buf, ref
+}
+
+// IncInt64 will increment a binary signed integer.
+type IncInt64 struct {
+	Key   []byte
+	Value int64
+}
+
+var incInt64Desc = &turing.Description{
+	Name:      "turing/IncInt64",
+	Operators: []*turing.Operator{AddInt64},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (i *IncInt64) Describe() *turing.Description {
+	return incInt64Desc
+}
+
+// Effect implements the turing.Instruction interface.
+func (i *IncInt64) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (i *IncInt64) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mergeUint64(mem, i.Key, uint64(i.Value), AddInt64)
+}
+
+// Encode implements the turing.Instruction interface.
+func (i *IncInt64) Encode() ([]byte, turing.Ref, error) {
+	return encodeNumber(i.Key, uint64(i.Value))
+}
+
+// Decode implements the turing.Instruction interface.
+func (i *IncInt64) Decode(bytes []byte) error {
+	var value uint64
+	err := decodeNumber(bytes, "inc int64", &i.Key, &value)
+	i.Value = int64(value)
+	return err
+}
+
+// IncUint64 will increment a binary unsigned integer.
+type IncUint64 struct {
+	Key   []byte
+	Value uint64
+}
+
+var incUint64Desc = &turing.Description{
+	Name:      "turing/IncUint64",
+	Operators: []*turing.Operator{AddUint64},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (i *IncUint64) Describe() *turing.Description {
+	return incUint64Desc
+}
+
+// Effect implements the turing.Instruction interface.
+func (i *IncUint64) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (i *IncUint64) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mergeUint64(mem, i.Key, i.Value, AddUint64)
+}
+
+// Encode implements the turing.Instruction interface.
+func (i *IncUint64) Encode() ([]byte, turing.Ref, error) {
+	return encodeNumber(i.Key, i.Value)
+}
+
+// Decode implements the turing.Instruction interface.
+func (i *IncUint64) Decode(bytes []byte) error {
+	return decodeNumber(bytes, "inc uint64", &i.Key, &i.Value)
+}
+
+// IncFloat64 will increment a binary floating point number.
+type IncFloat64 struct {
+	Key   []byte
+	Value float64
+}
+
+var incFloat64Desc = &turing.Description{
+	Name:      "turing/IncFloat64",
+	Operators: []*turing.Operator{AddFloat64},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (i *IncFloat64) Describe() *turing.Description {
+	return incFloat64Desc
+}
+
+// Effect implements the turing.Instruction interface.
+func (i *IncFloat64) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (i *IncFloat64) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mergeUint64(mem, i.Key, math.Float64bits(i.Value), AddFloat64)
+}
+
+// Encode implements the turing.Instruction interface.
+func (i *IncFloat64) Encode() ([]byte, turing.Ref, error) {
+	return encodeNumber(i.Key, math.Float64bits(i.Value))
+}
+
+// Decode implements the turing.Instruction interface.
+func (i *IncFloat64) Decode(bytes []byte) error {
+	var value uint64
+	err := decodeNumber(bytes, "inc float64", &i.Key, &value)
+	i.Value = math.Float64frombits(value)
+	return err
+}
+
+func mergeUint64(mem turing.Memory, key []byte, value uint64, op *turing.Operator) error {
+	// borrow slice
+	buf, ref := fpack.Borrow(8)
+	defer ref.Release()
+
+	// encode value
+	binary.BigEndian.PutUint64(buf, value)
+
+	// merge value
+	err := mem.Merge(key, buf, op)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func encodeNumber(key []byte, value uint64) ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Uint64(value)
+		enc.Tail(key)
+
+		return nil
+	})
+}
+
+func decodeNumber(bytes []byte, name string, key *[]byte, value *uint64) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode %s: invalid version", name)
+		}
+
+		// decode body
+		*value = dec.Uint64()
+		*key = dec.Tail(true)
+
+		return nil
+	})
+}
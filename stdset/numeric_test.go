@@ -0,0 +1,103 @@
+package stdset
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestAddNumbers(t *testing.T) {
+	encode := func(values ...uint64) [][]byte {
+		var list [][]byte
+		for _, value := range values {
+			buf := make([]byte, 8)
+			binary.BigEndian.PutUint64(buf, value)
+			list = append(list, buf)
+		}
+		return list
+	}
+
+	minusSeven := int64(-7)
+	res := checkOperator(t, AddInt64, encode(5, uint64(minusSeven), 4))
+	assert.Equal(t, int64(2), int64(binary.BigEndian.Uint64(res)))
+
+	res = checkOperator(t, AddUint64, encode(5, 7, 4))
+	assert.Equal(t, uint64(16), binary.BigEndian.Uint64(res))
+
+	res = checkOperator(t, AddUint64, encode(math.MaxUint64, 2, 1))
+	assert.Equal(t, uint64(2), binary.BigEndian.Uint64(res))
+
+	res = checkOperator(t, AddInt64, encode(uint64(math.MaxInt64), 1))
+	assert.Equal(t, int64(math.MinInt64), int64(binary.BigEndian.Uint64(res)))
+
+	res = checkOperator(t, AddFloat64, encode(math.Float64bits(0.5), math.Float64bits(1.5), math.Float64bits(-1)))
+	assert.Equal(t, 1.0, math.Float64frombits(binary.BigEndian.Uint64(res)))
+
+	res = checkOperator(t, AddFloat64, encode(math.Float64bits(0.1), math.Float64bits(1e16), math.Float64bits(-1e16)))
+	assert.Equal(t, 0.0, math.Float64frombits(binary.BigEndian.Uint64(res)))
+	assert.Nil(t, AddFloat64.Combine)
+}
+
+func TestIncNumbers(t *testing.T) {
+	machine := turing.Test(&IncInt64{}, &IncUint64{}, &IncFloat64{}, &Get{})
+	defer machine.Stop()
+
+	for i := 0; i < 3; i++ {
+		err := machine.Execute(&IncInt64{
+			Key:   []byte("int"),
+			Value: -2,
+		})
+		assert.NoError(t, err)
+
+		err = machine.Execute(&IncUint64{
+			Key:   []byte("uint"),
+			Value: 2,
+		})
+		assert.NoError(t, err)
+
+		err = machine.Execute(&IncFloat64{
+			Key:   []byte("float"),
+			Value: 0.5,
+		})
+		assert.NoError(t, err)
+	}
+
+	get := &Get{Key: []byte("int")}
+	err := machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-6), int64(binary.BigEndian.Uint64(get.Value)))
+
+	get = &Get{Key: []byte("uint")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), binary.BigEndian.Uint64(get.Value))
+
+	get = &Get{Key: []byte("float")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, math.Float64frombits(binary.BigEndian.Uint64(get.Value)))
+}
+
+func BenchmarkIncInt64(b *testing.B) {
+	machine := turing.Test(&IncInt64{})
+	defer machine.Stop()
+
+	inc := &IncInt64{
+		Key:   []byte("foo"),
+		Value: 1,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(inc)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Ack will acknowledge leased messages and remove them from a queue. Receipts
+// of expired leases that have been requeued are ignored.
+type Ack struct {
+	Queue    []byte
+	Receipts []Receipt
+	Acked    int
+}
+
+var ackDesc = &turing.Description{
+	Name: "turing/queue/Ack",
+}
+
+// Describe implements the turing.Instruction interface.
+func (a *Ack) Describe() *turing.Description {
+	return ackDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (a *Ack) Effect() int {
+	return len(a.Receipts) * 2
+}
+
+// Execute implements the turing.Instruction interface.
+func (a *Ack) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check name
+	err := checkName(a.Queue)
+	if err != nil {
+		return err
+	}
+
+	// release leases
+	a.Acked = 0
+	for _, receipt := range a.Receipts {
+		_, ok, err := release(mem, a.Queue, receipt)
+		if err != nil {
+			return err
+		} else if ok {
+			a.Acked++
+		}
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (a *Ack) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarUint(uint64(a.Acked))
+		enc.VarBytes(a.Queue)
+		encodeReceipts(enc, a.Receipts)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (a *Ack) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("queue: decode ack: invalid version")
+		}
+
+		// decode body
+		a.Acked = int(dec.VarUint())
+		a.Queue = dec.VarBytes(true)
+		a.Receipts = decodeReceipts(dec)
+
+		return nil
+	})
+}
@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAck(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	err := machine.Execute(&Enqueue{Queue: []byte("foo"), Values: [][]byte{[]byte("a"), []byte("b")}})
+	assert.NoError(t, err)
+
+	messages := dequeue(t, machine, 2, 0)
+	assert.Len(t, messages, 2)
+
+	ack := &Ack{Queue: []byte("foo"), Receipts: []Receipt{
+		messages[0].Receipt(),
+		{Seq: messages[1].Seq, Lease: messages[1].Lease + 1},
+	}}
+	err = machine.Execute(ack)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ack.Acked)
+
+	ack = &Ack{Queue: []byte("foo"), Receipts: []Receipt{
+		messages[0].Receipt(),
+		messages[1].Receipt(),
+	}}
+	err = machine.Execute(ack)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ack.Acked)
+
+	nack := &Nack{Queue: []byte("foo"), Receipts: []Receipt{messages[1].Receipt()}}
+	err = machine.Execute(nack)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, nack.Nacked)
+
+	assert.Empty(t, dequeue(t, machine, 2, 0))
+}
@@ -0,0 +1,194 @@
+package queue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Dequeue will lease messages from the head of a queue. Leased messages are
+// invisible until they are acknowledged, negatively acknowledged or their
+// lease expired and they have been requeued.
+type Dequeue struct {
+	Queue    []byte
+	Limit    int
+	Lease    time.Duration
+	Messages []Message
+}
+
+var dequeueDesc = &turing.Description{
+	Name: "turing/queue/Dequeue",
+}
+
+// Describe implements the turing.Instruction interface.
+func (d *Dequeue) Describe() *turing.Description {
+	return dequeueDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (d *Dequeue) Effect() int {
+	return limit(d.Limit)*3 + 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (d *Dequeue) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check name
+	err := checkName(d.Queue)
+	if err != nil {
+		return err
+	}
+
+	// reset messages
+	d.Messages = nil
+
+	// load meta
+	m, err := loadMeta(mem, d.Queue)
+	if err != nil {
+		return err
+	}
+
+	// create iterator
+	iter := mem.Range(makeKey(d.Queue, itemKind, m.head), makeKey(d.Queue, itemKind+1), turing.RangeOptions{
+		Limit: limit(d.Limit),
+	})
+
+	// collect items
+	for iter.First(); iter.Valid(); iter.Next() {
+		// parse sequence
+		nums, err := parseNums(iter.TempKey(), d.Queue, 1)
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		// get value
+		value, err := iter.TempValue()
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		// add message
+		d.Messages = append(d.Messages, Message{
+			Seq:   nums[0],
+			Value: turing.Clone(value),
+		})
+	}
+
+	// close iterator
+	err = iter.Close()
+	if err != nil {
+		return err
+	}
+
+	// check messages
+	if len(d.Messages) == 0 {
+		return nil
+	}
+
+	// get lease
+	lease := d.Lease
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+
+	// compute deadline
+	deadline := mem.Now().Add(lease).UnixNano()
+
+	// lease messages
+	for i, msg := range d.Messages {
+		// remove item
+		err = mem.Unset(makeKey(d.Queue, itemKind, msg.Seq))
+		if err != nil {
+			return err
+		}
+
+		// add lease
+		err = mem.Set(makeKey(d.Queue, leaseKind, msg.Seq), encodeLease(deadline, msg.Value))
+		if err != nil {
+			return err
+		}
+
+		// add index
+		err = mem.Set(makeKey(d.Queue, indexKind, uint64(deadline), msg.Seq), nil)
+		if err != nil {
+			return err
+		}
+
+		// set lease
+		d.Messages[i].Lease = deadline
+	}
+
+	// advance head
+	m.head = d.Messages[len(d.Messages)-1].Seq + 1
+
+	// store meta
+	err = storeMeta(mem, d.Queue, m)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (d *Dequeue) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode options
+		enc.VarUint(uint64(d.Limit))
+		enc.Int64(int64(d.Lease))
+
+		// encode queue
+		enc.VarBytes(d.Queue)
+
+		// encode length
+		enc.VarUint(uint64(len(d.Messages)))
+
+		// encode messages
+		for _, msg := range d.Messages {
+			enc.Uint64(msg.Seq)
+			enc.Int64(msg.Lease)
+			enc.VarBytes(msg.Value)
+		}
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (d *Dequeue) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("queue: decode dequeue: invalid version")
+		}
+
+		// decode options
+		d.Limit = int(dec.VarUint())
+		d.Lease = time.Duration(dec.Int64())
+
+		// decode queue
+		d.Queue = dec.VarBytes(true)
+
+		// decode length
+		length := dec.VarUint()
+
+		// decode messages
+		d.Messages = nil
+		if length > 0 {
+			d.Messages = make([]Message, length)
+			for i := range d.Messages {
+				d.Messages[i].Seq = dec.Uint64()
+				d.Messages[i].Lease = dec.Int64()
+				d.Messages[i].Value = dec.VarBytes(true)
+			}
+		}
+
+		return nil
+	})
+}
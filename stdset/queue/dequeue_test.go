@@ -0,0 +1,58 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDequeue(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	assert.Empty(t, dequeue(t, machine, 1, 0))
+
+	err := machine.Execute(&Enqueue{Queue: []byte("foo"), Values: [][]byte{[]byte("a"), []byte("b"), []byte("c")}})
+	assert.NoError(t, err)
+
+	before := time.Now()
+	messages := dequeue(t, machine, 2, time.Minute)
+	assert.Equal(t, []string{"a", "b"}, values(messages))
+	assert.Equal(t, uint64(0), messages[0].Seq)
+	assert.Equal(t, uint64(1), messages[1].Seq)
+	assert.True(t, messages[0].Lease >= before.Add(time.Minute).UnixNano())
+	assert.Equal(t, messages[0].Lease, messages[1].Lease)
+
+	messages = dequeue(t, machine, 0, 0)
+	assert.Equal(t, []string{"c"}, values(messages))
+
+	assert.Empty(t, dequeue(t, machine, 1, 0))
+}
+
+func BenchmarkDequeue(b *testing.B) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(&Enqueue{
+			Queue:  []byte("foo"),
+			Values: [][]byte{[]byte("bar")},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(&Dequeue{
+			Queue: []byte("foo"),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
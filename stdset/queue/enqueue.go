@@ -0,0 +1,115 @@
+package queue
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Enqueue will append values to the tail of a queue.
+type Enqueue struct {
+	Queue  []byte
+	Values [][]byte
+	First  uint64
+}
+
+var enqueueDesc = &turing.Description{
+	Name: "turing/queue/Enqueue",
+}
+
+// Describe implements the turing.Instruction interface.
+func (e *Enqueue) Describe() *turing.Description {
+	return enqueueDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (e *Enqueue) Effect() int {
+	return len(e.Values) + 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (e *Enqueue) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check name
+	err := checkName(e.Queue)
+	if err != nil {
+		return err
+	}
+
+	// load meta
+	m, err := loadMeta(mem, e.Queue)
+	if err != nil {
+		return err
+	}
+
+	// set first
+	e.First = m.tail
+
+	// add items
+	for _, value := range e.Values {
+		err = mem.Set(makeKey(e.Queue, itemKind, m.tail), value)
+		if err != nil {
+			return err
+		}
+		m.tail++
+	}
+
+	// store meta
+	err = storeMeta(mem, e.Queue, m)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (e *Enqueue) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode first
+		enc.Uint64(e.First)
+
+		// encode queue
+		enc.VarBytes(e.Queue)
+
+		// encode length
+		enc.VarUint(uint64(len(e.Values)))
+
+		// encode values
+		for _, value := range e.Values {
+			enc.VarBytes(value)
+		}
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (e *Enqueue) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("queue: decode enqueue: invalid version")
+		}
+
+		// decode first
+		e.First = dec.Uint64()
+
+		// decode queue
+		e.Queue = dec.VarBytes(true)
+
+		// decode length
+		length := dec.VarUint()
+
+		// decode values
+		e.Values = make([][]byte, length)
+		for i := range e.Values {
+			e.Values[i] = dec.VarBytes(true)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,45 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnqueue(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	enqueue := &Enqueue{Queue: []byte("foo"), Values: [][]byte{[]byte("a"), []byte("b")}}
+	err := machine.Execute(enqueue)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), enqueue.First)
+
+	enqueue = &Enqueue{Queue: []byte("foo"), Values: [][]byte{[]byte("c")}}
+	err = machine.Execute(enqueue)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), enqueue.First)
+
+	err = machine.Execute(&Enqueue{Values: [][]byte{[]byte("c")}})
+	assert.Equal(t, ErrInvalidName, err)
+
+	assert.Equal(t, []string{"a", "b", "c"}, values(dequeue(t, machine, 10, 0)))
+}
+
+func BenchmarkEnqueue(b *testing.B) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(&Enqueue{
+			Queue:  []byte("foo"),
+			Values: [][]byte{[]byte("bar")},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
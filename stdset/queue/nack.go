@@ -0,0 +1,116 @@
+package queue
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Nack will negatively acknowledge leased messages and return them to a queue.
+// The messages keep their sequence and are therefore dequeued before newer
+// messages. Receipts of expired leases that have been requeued are ignored.
+type Nack struct {
+	Queue    []byte
+	Receipts []Receipt
+	Nacked   int
+}
+
+var nackDesc = &turing.Description{
+	Name: "turing/queue/Nack",
+}
+
+// Describe implements the turing.Instruction interface.
+func (n *Nack) Describe() *turing.Description {
+	return nackDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (n *Nack) Effect() int {
+	return len(n.Receipts)*3 + 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (n *Nack) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check name
+	err := checkName(n.Queue)
+	if err != nil {
+		return err
+	}
+
+	// load meta
+	m, err := loadMeta(mem, n.Queue)
+	if err != nil {
+		return err
+	}
+
+	// return messages
+	n.Nacked = 0
+	for _, receipt := range n.Receipts {
+		// release lease
+		value, ok, err := release(mem, n.Queue, receipt)
+		if err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+
+		// add item
+		err = mem.Set(makeKey(n.Queue, itemKind, receipt.Seq), value)
+		if err != nil {
+			return err
+		}
+
+		// rewind head
+		if receipt.Seq < m.head {
+			m.head = receipt.Seq
+		}
+
+		n.Nacked++
+	}
+
+	// check count
+	if n.Nacked == 0 {
+		return nil
+	}
+
+	// store meta
+	err = storeMeta(mem, n.Queue, m)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (n *Nack) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarUint(uint64(n.Nacked))
+		enc.VarBytes(n.Queue)
+		encodeReceipts(enc, n.Receipts)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (n *Nack) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("queue: decode nack: invalid version")
+		}
+
+		// decode body
+		n.Nacked = int(dec.VarUint())
+		n.Queue = dec.VarBytes(true)
+		n.Receipts = decodeReceipts(dec)
+
+		return nil
+	})
+}
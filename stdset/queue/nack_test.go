@@ -0,0 +1,28 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNack(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	err := machine.Execute(&Enqueue{Queue: []byte("foo"), Values: [][]byte{[]byte("a"), []byte("b"), []byte("c")}})
+	assert.NoError(t, err)
+
+	messages := dequeue(t, machine, 2, 0)
+	assert.Equal(t, []string{"a", "b"}, values(messages))
+
+	nack := &Nack{Queue: []byte("foo"), Receipts: []Receipt{messages[0].Receipt()}}
+	err = machine.Execute(nack)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, nack.Nacked)
+
+	messages = dequeue(t, machine, 3, 0)
+	assert.Equal(t, []string{"a", "c"}, values(messages))
+	assert.Equal(t, uint64(0), messages[0].Seq)
+	assert.Equal(t, uint64(2), messages[1].Seq)
+}
@@ -0,0 +1,240 @@
+// Package queue provides instructions that implement durable FIFO queues with
+// visibility leases and acknowledgements.
+//
+// Every queue is stored under a key prefix derived from its name that starts
+// with a namespace reserved for queues. A meta key
+// holds the head and tail sequence of the queue. Pending messages are stored
+// under their sequence while leased messages are moved to a lease key and
+// indexed by their deadline. Expired leases are returned to the queue by
+// executing Requeue periodically.
+package queue
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/stdset"
+)
+
+// DefaultLease is the lease used by Dequeue if none is specified.
+const DefaultLease = 30 * time.Second
+
+// DefaultLimit is the limit used by Dequeue and Requeue if none is specified.
+const DefaultLimit = 1
+
+// ErrInvalidName is returned if a queue name is empty or too long.
+var ErrInvalidName = fmt.Errorf("queue: invalid name")
+
+// Namespace is the prefix of all keys used by queues.
+const Namespace = stdset.Reserved + "q"
+
+const (
+	metaKind  = 'm'
+	itemKind  = 'i'
+	leaseKind = 'l'
+	indexKind = 'd'
+)
+
+// Message is a leased message returned by Dequeue.
+type Message struct {
+	Seq   uint64
+	Lease int64
+	Value []byte
+}
+
+// Receipt returns the receipt used to acknowledge the message.
+func (m Message) Receipt() Receipt {
+	return Receipt{Seq: m.Seq, Lease: m.Lease}
+}
+
+// Receipt identifies a single lease of a message.
+type Receipt struct {
+	Seq   uint64
+	Lease int64
+}
+
+func checkName(name []byte) error {
+	// check length
+	if len(name) == 0 || len(name) > 0xffff {
+		return ErrInvalidName
+	}
+
+	return nil
+}
+
+func makeKey(name []byte, kind byte, nums ...uint64) []byte {
+	// prepare key
+	n := len(Namespace)
+	key := make([]byte, n+2+len(name)+1+len(nums)*8)
+	copy(key, Namespace)
+	binary.BigEndian.PutUint16(key[n:], uint16(len(name)))
+	copy(key[n+2:], name)
+	key[n+2+len(name)] = kind
+
+	// add numbers
+	for i, num := range nums {
+		binary.BigEndian.PutUint64(key[n+3+len(name)+i*8:], num)
+	}
+
+	return key
+}
+
+func parseNums(key []byte, name []byte, nums int) ([]uint64, error) {
+	// check length
+	offset := len(Namespace) + 3 + len(name)
+	if len(key) != offset+nums*8 {
+		return nil, fmt.Errorf("queue: invalid key")
+	}
+
+	// parse numbers
+	list := make([]uint64, nums)
+	for i := range list {
+		list[i] = binary.BigEndian.Uint64(key[offset+i*8:])
+	}
+
+	return list, nil
+}
+
+type meta struct {
+	head uint64
+	tail uint64
+}
+
+func decodeMeta(value []byte) (meta, error) {
+	// check length
+	if len(value) != 16 {
+		return meta{}, fmt.Errorf("queue: invalid meta")
+	}
+
+	return meta{
+		head: binary.BigEndian.Uint64(value),
+		tail: binary.BigEndian.Uint64(value[8:]),
+	}, nil
+}
+
+func (m meta) encode() []byte {
+	// encode pointers
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf, m.head)
+	binary.BigEndian.PutUint64(buf[8:], m.tail)
+
+	return buf
+}
+
+func encodeLease(deadline int64, value []byte) []byte {
+	// encode deadline and value
+	buf := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(buf, uint64(deadline))
+	copy(buf[8:], value)
+
+	return buf
+}
+
+func decodeLease(buf []byte) (int64, []byte, error) {
+	// check length
+	if len(buf) < 8 {
+		return 0, nil, fmt.Errorf("queue: invalid lease")
+	}
+
+	return int64(binary.BigEndian.Uint64(buf)), buf[8:], nil
+}
+
+func loadMeta(mem turing.Memory, name []byte) (meta, error) {
+	// get meta
+	var m meta
+	err := mem.Use(makeKey(name, metaKind), func(value []byte) error {
+		var err error
+		m, err = decodeMeta(value)
+		return err
+	})
+	if err != nil {
+		return meta{}, err
+	}
+
+	return m, nil
+}
+
+func storeMeta(mem turing.Memory, name []byte, m meta) error {
+	return mem.Set(makeKey(name, metaKind), m.encode())
+}
+
+func release(mem turing.Memory, name []byte, receipt Receipt) ([]byte, bool, error) {
+	// get lease
+	var value []byte
+	var found bool
+	err := mem.Use(makeKey(name, leaseKind, receipt.Seq), func(buf []byte) error {
+		// decode lease
+		deadline, val, err := decodeLease(buf)
+		if err != nil {
+			return err
+		}
+
+		// check deadline
+		if deadline != receipt.Lease {
+			return nil
+		}
+
+		// copy value
+		value = turing.Clone(val)
+		found = true
+
+		return nil
+	})
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	// remove lease
+	err = mem.Unset(makeKey(name, leaseKind, receipt.Seq))
+	if err != nil {
+		return nil, false, err
+	}
+
+	// remove index
+	err = mem.Unset(makeKey(name, indexKind, uint64(receipt.Lease), receipt.Seq))
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func encodeReceipts(enc *fpack.Encoder, receipts []Receipt) {
+	// encode length
+	enc.VarUint(uint64(len(receipts)))
+
+	// encode receipts
+	for _, receipt := range receipts {
+		enc.Uint64(receipt.Seq)
+		enc.Int64(receipt.Lease)
+	}
+}
+
+func decodeReceipts(dec *fpack.Decoder) []Receipt {
+	// decode length
+	length := dec.VarUint()
+	if length == 0 {
+		return nil
+	}
+
+	// decode receipts
+	receipts := make([]Receipt, length)
+	for i := range receipts {
+		receipts[i].Seq = dec.Uint64()
+		receipts[i].Lease = dec.Int64()
+	}
+
+	return receipts
+}
+
+func limit(limit int) int {
+	// apply default
+	if limit <= 0 {
+		return DefaultLimit
+	}
+
+	return limit
+}
@@ -0,0 +1,52 @@
+package queue
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestMain(m *testing.M) {
+	// disable logging
+	turing.SetLogger(nil)
+
+	// run tests
+	os.Exit(m.Run())
+}
+
+func testMachine() *turing.Machine {
+	return turing.Test(&Enqueue{}, &Dequeue{}, &Ack{}, &Nack{}, &Requeue{})
+}
+
+func dequeue(t *testing.T, machine *turing.Machine, limit int, lease time.Duration) []Message {
+	dequeue := &Dequeue{Queue: []byte("foo"), Limit: limit, Lease: lease}
+	err := machine.Execute(dequeue)
+	assert.NoError(t, err)
+	return dequeue.Messages
+}
+
+func values(messages []Message) []string {
+	list := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		list = append(list, string(msg.Value))
+	}
+	return list
+}
+
+func TestKeys(t *testing.T) {
+	key := makeKey([]byte("foo"), indexKind, 7, 42)
+	assert.Equal(t, []byte{0, 'q', 0, 3, 'f', 'o', 'o', 'd', 0, 0, 0, 0, 0, 0, 0, 7, 0, 0, 0, 0, 0, 0, 0, 42}, key)
+
+	nums, err := parseNums(key, []byte("foo"), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{7, 42}, nums)
+
+	_, err = parseNums(key, []byte("foo"), 1)
+	assert.Error(t, err)
+
+	assert.Equal(t, ErrInvalidName, checkName(nil))
+}
@@ -0,0 +1,122 @@
+package queue
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Requeue will return messages with expired leases to a queue. It should be
+// executed periodically for every queue that uses leases.
+type Requeue struct {
+	Queue    []byte
+	Limit    int
+	Requeued int
+}
+
+var requeueDesc = &turing.Description{
+	Name: "turing/queue/Requeue",
+}
+
+// Describe implements the turing.Instruction interface.
+func (r *Requeue) Describe() *turing.Description {
+	return requeueDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (r *Requeue) Effect() int {
+	return limit(r.Limit)*3 + 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (r *Requeue) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check name
+	err := checkName(r.Queue)
+	if err != nil {
+		return err
+	}
+
+	// reset count
+	r.Requeued = 0
+
+	// get now
+	now := mem.Now().UnixNano()
+
+	// create iterator
+	iter := mem.Range(makeKey(r.Queue, indexKind), makeKey(r.Queue, indexKind, uint64(now)+1), turing.RangeOptions{
+		Limit: limit(r.Limit),
+	})
+
+	// collect expired leases
+	var receipts []Receipt
+	for iter.First(); iter.Valid(); iter.Next() {
+		// parse deadline and sequence
+		nums, err := parseNums(iter.TempKey(), r.Queue, 2)
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		// add receipt
+		receipts = append(receipts, Receipt{
+			Seq:   nums[1],
+			Lease: int64(nums[0]),
+		})
+	}
+
+	// close iterator
+	err = iter.Close()
+	if err != nil {
+		return err
+	}
+
+	// check receipts
+	if len(receipts) == 0 {
+		return nil
+	}
+
+	// return messages
+	nack := &Nack{Queue: r.Queue, Receipts: receipts}
+	err = nack.Execute(mem, nil)
+	if err != nil {
+		return err
+	}
+
+	// set count
+	r.Requeued = nack.Nacked
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (r *Requeue) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarUint(uint64(r.Limit))
+		enc.VarUint(uint64(r.Requeued))
+		enc.Tail(r.Queue)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (r *Requeue) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("queue: decode requeue: invalid version")
+		}
+
+		// decode body
+		r.Limit = int(dec.VarUint())
+		r.Requeued = int(dec.VarUint())
+		r.Queue = dec.Tail(true)
+
+		return nil
+	})
+}
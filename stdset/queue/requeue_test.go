@@ -0,0 +1,41 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequeue(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	err := machine.Execute(&Enqueue{Queue: []byte("foo"), Values: [][]byte{[]byte("a"), []byte("b"), []byte("c")}})
+	assert.NoError(t, err)
+
+	expired := dequeue(t, machine, 1, time.Millisecond)
+	assert.Equal(t, []string{"a"}, values(expired))
+
+	leased := dequeue(t, machine, 1, time.Minute)
+	assert.Equal(t, []string{"b"}, values(leased))
+
+	time.Sleep(10 * time.Millisecond)
+
+	requeue := &Requeue{Queue: []byte("foo"), Limit: 10}
+	err = machine.Execute(requeue)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requeue.Requeued)
+
+	ack := &Ack{Queue: []byte("foo"), Receipts: []Receipt{expired[0].Receipt()}}
+	err = machine.Execute(ack)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, ack.Acked)
+
+	assert.Equal(t, []string{"a", "c"}, values(dequeue(t, machine, 10, time.Minute)))
+
+	requeue = &Requeue{Queue: []byte("foo"), Limit: 10}
+	err = machine.Execute(requeue)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, requeue.Requeued)
+}
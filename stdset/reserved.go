@@ -0,0 +1,14 @@
+package stdset
+
+import "bytes"
+
+// Reserved is the prefix of all keys used by the composite data structures in
+// the hash, zset and queue packages. Every structure uses its own namespace
+// below this prefix. Plain keys should therefore never start with it.
+const Reserved = "\x00"
+
+// IsReserved returns whether the key is a composite key used by a data
+// structure below the reserved prefix.
+func IsReserved(key []byte) bool {
+	return bytes.HasPrefix(key, []byte(Reserved))
+}
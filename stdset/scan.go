@@ -0,0 +1,219 @@
+package stdset
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Scan will scan the key value pairs in the range [Start, End). If more pairs
+// than the specified limit are available, the cursor is set to the Start of
+// the next page.
+type Scan struct {
+	Start  []byte
+	End    []byte
+	Limit  int
+	Keys   [][]byte
+	Values [][]byte
+	Cursor []byte
+}
+
+var scanDesc = &turing.Description{
+	Name: "turing/Scan",
+}
+
+// Describe implements the turing.Instruction interface.
+func (s *Scan) Describe() *turing.Description {
+	return scanDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (s *Scan) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (s *Scan) Execute(mem turing.Memory, _ turing.Cache) error {
+	// scan pairs
+	keys, values, cursor, err := scan(mem, s.Start, s.End, s.Limit, false)
+	if err != nil {
+		return err
+	}
+
+	// set result
+	s.Keys = keys
+	s.Values = values
+	s.Cursor = cursor
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (s *Scan) Encode() ([]byte, turing.Ref, error) {
+	return encodeScan(s.Start, s.End, s.Limit, s.Keys, s.Values, s.Cursor)
+}
+
+// Decode implements the turing.Instruction interface.
+func (s *Scan) Decode(bytes []byte) error {
+	return decodeScan(bytes, &s.Start, &s.End, &s.Limit, &s.Keys, &s.Values, &s.Cursor)
+}
+
+// ReverseScan will scan the key value pairs in the range [Start, End) in
+// reverse order. If more pairs than the specified limit are available, the
+// cursor is set to the End of the next page.
+type ReverseScan struct {
+	Start  []byte
+	End    []byte
+	Limit  int
+	Keys   [][]byte
+	Values [][]byte
+	Cursor []byte
+}
+
+var reverseScanDesc = &turing.Description{
+	Name: "turing/ReverseScan",
+}
+
+// Describe implements the turing.Instruction interface.
+func (s *ReverseScan) Describe() *turing.Description {
+	return reverseScanDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (s *ReverseScan) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (s *ReverseScan) Execute(mem turing.Memory, _ turing.Cache) error {
+	// scan pairs
+	keys, values, cursor, err := scan(mem, s.Start, s.End, s.Limit, true)
+	if err != nil {
+		return err
+	}
+
+	// set result
+	s.Keys = keys
+	s.Values = values
+	s.Cursor = cursor
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (s *ReverseScan) Encode() ([]byte, turing.Ref, error) {
+	return encodeScan(s.Start, s.End, s.Limit, s.Keys, s.Values, s.Cursor)
+}
+
+// Decode implements the turing.Instruction interface.
+func (s *ReverseScan) Decode(bytes []byte) error {
+	return decodeScan(bytes, &s.Start, &s.End, &s.Limit, &s.Keys, &s.Values, &s.Cursor)
+}
+
+func scan(mem turing.Memory, start, end []byte, limit int, reverse bool) ([][]byte, [][]byte, []byte, error) {
+	// prepare lists
+	keys := make([][]byte, 0, 64)
+	values := make([][]byte, 0, 64)
+
+	// prepare options (fetch one more pair to detect further pages)
+	opts := turing.RangeOptions{
+		Reverse: reverse,
+	}
+	if limit > 0 {
+		opts.Limit = limit + 1
+	}
+
+	// create iterator
+	iter := mem.Range(start, end, opts)
+	defer iter.Close()
+
+	// collect pairs
+	var cursor []byte
+	for iter.First(); iter.Valid(); iter.Next() {
+		// set cursor if limit has been reached
+		if limit > 0 && len(keys) == limit {
+			if reverse {
+				cursor = turing.Clone(keys[len(keys)-1])
+			} else {
+				cursor = turing.Clone(iter.TempKey())
+			}
+
+			break
+		}
+
+		// add pair
+		err := iter.Use(func(key, value []byte) error {
+			keys = append(keys, turing.Clone(key))
+			values = append(values, turing.Clone(value))
+			return nil
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// close iterator
+	err := iter.Close()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return keys, values, cursor, nil
+}
+
+func encodeScan(start, end []byte, limit int, keys, values [][]byte, cursor []byte) ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode range
+		enc.VarBytes(start)
+		enc.VarBytes(end)
+		enc.VarUint(uint64(limit))
+
+		// encode length
+		enc.VarUint(uint64(len(keys)))
+
+		// encode pairs
+		for i, key := range keys {
+			enc.VarBytes(key)
+			enc.VarBytes(values[i])
+		}
+
+		// encode cursor
+		enc.Tail(cursor)
+
+		return nil
+	})
+}
+
+func decodeScan(bytes []byte, start, end *[]byte, limit *int, keys, values *[][]byte, cursor *[]byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode scan: invalid version")
+		}
+
+		// decode range
+		*start = dec.VarBytes(true)
+		*end = dec.VarBytes(true)
+		*limit = int(dec.VarUint())
+
+		// decode length
+		length := dec.VarUint()
+
+		// decode pairs
+		*keys = make([][]byte, length)
+		*values = make([][]byte, length)
+		for i := 0; i < int(length); i++ {
+			(*keys)[i] = dec.VarBytes(true)
+			(*values)[i] = dec.VarBytes(true)
+		}
+
+		// decode cursor
+		*cursor = dec.Tail(true)
+
+		return nil
+	})
+}
@@ -0,0 +1,129 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestScan(t *testing.T) {
+	machine := turing.Test(&Scan{}, &Set{})
+	defer machine.Stop()
+
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		err := machine.Execute(&Set{
+			Key:   []byte(key),
+			Value: []byte(key + key),
+		})
+		assert.NoError(t, err)
+	}
+
+	scan := &Scan{
+		Start: []byte("b"),
+		End:   []byte("e"),
+	}
+	err := machine.Execute(scan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("b"), []byte("c"), []byte("d")}, scan.Keys)
+	assert.Equal(t, [][]byte{[]byte("bb"), []byte("cc"), []byte("dd")}, scan.Values)
+	assert.Nil(t, scan.Cursor)
+
+	scan = &Scan{
+		Limit: 2,
+	}
+	err = machine.Execute(scan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b")}, scan.Keys)
+	assert.Equal(t, []byte("c"), scan.Cursor)
+
+	scan = &Scan{
+		Start: scan.Cursor,
+		Limit: 2,
+	}
+	err = machine.Execute(scan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("c"), []byte("d")}, scan.Keys)
+	assert.Equal(t, []byte("e"), scan.Cursor)
+
+	scan = &Scan{
+		Start: scan.Cursor,
+		Limit: 2,
+	}
+	err = machine.Execute(scan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("e")}, scan.Keys)
+	assert.Nil(t, scan.Cursor)
+}
+
+func TestReverseScan(t *testing.T) {
+	machine := turing.Test(&ReverseScan{}, &Set{})
+	defer machine.Stop()
+
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		err := machine.Execute(&Set{
+			Key:   []byte(key),
+			Value: []byte(key + key),
+		})
+		assert.NoError(t, err)
+	}
+
+	scan := &ReverseScan{
+		Start: []byte("b"),
+		End:   []byte("e"),
+	}
+	err := machine.Execute(scan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("d"), []byte("c"), []byte("b")}, scan.Keys)
+	assert.Equal(t, [][]byte{[]byte("dd"), []byte("cc"), []byte("bb")}, scan.Values)
+	assert.Nil(t, scan.Cursor)
+
+	scan = &ReverseScan{
+		Limit: 3,
+	}
+	err = machine.Execute(scan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("e"), []byte("d"), []byte("c")}, scan.Keys)
+	assert.Equal(t, []byte("c"), scan.Cursor)
+
+	scan = &ReverseScan{
+		End:   scan.Cursor,
+		Limit: 3,
+	}
+	err = machine.Execute(scan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("b"), []byte("a")}, scan.Keys)
+	assert.Nil(t, scan.Cursor)
+}
+
+func BenchmarkScan(b *testing.B) {
+	machine := turing.Test(&Scan{}, &Set{})
+	defer machine.Stop()
+
+	err := machine.Execute(&Set{
+		Key:   []byte("bar"),
+		Value: []byte("foo"),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	scan := &Scan{
+		Start: []byte("b"),
+		Limit: 10,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(scan)
+		if err != nil {
+			panic(err)
+		}
+
+		scan.Keys = nil
+		scan.Values = nil
+	}
+}
@@ -2,15 +2,18 @@ package stdset
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/256dpi/fpack"
 	"github.com/256dpi/turing"
 )
 
-// Set will set a value.
+// Set will set a value. If a TTL is specified, the value will expire after
+// the specified duration.
 type Set struct {
 	Key   []byte
 	Value []byte
+	TTL   time.Duration
 }
 
 var setDesc = &turing.Description{
@@ -27,8 +30,18 @@ func (s *Set) Effect() int {
 	return 1
 }
 
+// Scope implements the turing.Scoped interface.
+func (s *Set) Scope() ([][]byte, [][]byte) {
+	return nil, [][]byte{s.Key}
+}
+
 // Execute implements the turing.Instruction interface.
 func (s *Set) Execute(mem turing.Memory, _ turing.Cache) error {
+	// set expiring pair if requested
+	if s.TTL > 0 {
+		return mem.SetWithTTL(s.Key, s.Value, s.TTL)
+	}
+
 	// set pair
 	err := mem.Set(s.Key, s.Value)
 	if err != nil {
@@ -42,10 +55,11 @@ func (s *Set) Execute(mem turing.Memory, _ turing.Cache) error {
 func (s *Set) Encode() ([]byte, turing.Ref, error) {
 	return fpack.Encode(true, func(enc *fpack.Encoder) error {
 		// encode version
-		enc.Uint8(1)
+		enc.Uint8(2)
 
 		// encode body
 		enc.VarBytes(s.Key)
+		enc.VarInt(int64(s.TTL))
 		enc.Tail(s.Value)
 
 		return nil
@@ -56,12 +70,17 @@ func (s *Set) Encode() ([]byte, turing.Ref, error) {
 func (s *Set) Decode(bytes []byte) error {
 	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
 		// check version
-		if dec.Uint8() != 1 {
+		version := dec.Uint8()
+		if version != 1 && version != 2 {
 			return fmt.Errorf("stdset: decode set: invalid version")
 		}
 
 		// decode body
 		s.Key = dec.VarBytes(true)
+		s.TTL = 0
+		if version >= 2 {
+			s.TTL = time.Duration(dec.VarInt())
+		}
 		s.Value = dec.Tail(true)
 
 		return nil
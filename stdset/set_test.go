@@ -2,6 +2,7 @@ package stdset
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -52,3 +53,39 @@ func BenchmarkSet(b *testing.B) {
 		}
 	}
 }
+
+func TestSetTTL(t *testing.T) {
+	machine := turing.Test(&Set{}, &Get{}, &Dump{})
+	defer machine.Stop()
+
+	err := machine.Execute(&Set{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+		TTL:   50 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	get := &Get{
+		Key: []byte("foo"),
+	}
+
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.True(t, get.Exists)
+	assert.Equal(t, []byte("bar"), get.Value)
+
+	time.Sleep(100 * time.Millisecond)
+
+	get = &Get{
+		Key: []byte("foo"),
+	}
+
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.False(t, get.Exists)
+
+	dump := &Dump{}
+	err = machine.Execute(dump)
+	assert.NoError(t, err)
+	assert.Empty(t, dump.Map)
+}
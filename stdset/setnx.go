@@ -0,0 +1,107 @@
+package stdset
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// SetNX will set a value if the key does not exist. If a TTL is specified,
+// the value will expire after the specified duration.
+type SetNX struct {
+	Key     []byte
+	Value   []byte
+	TTL     time.Duration
+	Applied bool
+}
+
+var setNXDesc = &turing.Description{
+	Name: "turing/SetNX",
+}
+
+// Describe implements the turing.Instruction interface.
+func (s *SetNX) Describe() *turing.Description {
+	return setNXDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (s *SetNX) Effect() int {
+	return 1
+}
+
+// Scope implements the turing.Scoped interface.
+func (s *SetNX) Scope() ([][]byte, [][]byte) {
+	return [][]byte{s.Key}, [][]byte{s.Key}
+}
+
+// Execute implements the turing.Instruction interface.
+func (s *SetNX) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check existence
+	var exists bool
+	err := mem.Use(s.Key, func([]byte) error {
+		exists = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// skip if existing
+	s.Applied = !exists
+	if exists {
+		return nil
+	}
+
+	// set expiring pair if requested
+	if s.TTL > 0 {
+		return mem.SetWithTTL(s.Key, s.Value, s.TTL)
+	}
+
+	// set pair
+	err = mem.Set(s.Key, s.Value)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (s *SetNX) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(2)
+
+		// encode body
+		enc.Bool(s.Applied)
+		enc.VarBytes(s.Key)
+		enc.VarInt(int64(s.TTL))
+		enc.Tail(s.Value)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (s *SetNX) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		version := dec.Uint8()
+		if version != 1 && version != 2 {
+			return fmt.Errorf("stdset: decode setnx: invalid version")
+		}
+
+		// decode body
+		s.Applied = dec.Bool()
+		s.Key = dec.VarBytes(true)
+		s.TTL = 0
+		if version >= 2 {
+			s.TTL = time.Duration(dec.VarInt())
+		}
+		s.Value = dec.Tail(true)
+
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package stdset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestSetNX(t *testing.T) {
+	machine := turing.Test(&SetNX{}, &Get{})
+	defer machine.Stop()
+
+	setnx := &SetNX{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	}
+
+	err := machine.Execute(setnx)
+	assert.NoError(t, err)
+	assert.True(t, setnx.Applied)
+
+	setnx = &SetNX{
+		Key:   []byte("foo"),
+		Value: []byte("baz"),
+	}
+
+	err = machine.Execute(setnx)
+	assert.NoError(t, err)
+	assert.False(t, setnx.Applied)
+
+	get := &Get{
+		Key: []byte("foo"),
+	}
+
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("bar"), get.Value)
+}
+
+func TestSetNXTTL(t *testing.T) {
+	machine := turing.Test(&SetNX{}, &Get{})
+	defer machine.Stop()
+
+	setnx := &SetNX{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+		TTL:   50 * time.Millisecond,
+	}
+
+	err := machine.Execute(setnx)
+	assert.NoError(t, err)
+	assert.True(t, setnx.Applied)
+
+	err = machine.Execute(setnx)
+	assert.NoError(t, err)
+	assert.False(t, setnx.Applied)
+
+	time.Sleep(100 * time.Millisecond)
+
+	err = machine.Execute(setnx)
+	assert.NoError(t, err)
+	assert.True(t, setnx.Applied)
+
+	get := &Get{
+		Key: []byte("foo"),
+	}
+
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("bar"), get.Value)
+}
+
+func BenchmarkSetNX(b *testing.B) {
+	machine := turing.Test(&SetNX{})
+	defer machine.Stop()
+
+	setnx := &SetNX{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(setnx)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
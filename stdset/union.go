@@ -0,0 +1,104 @@
+package stdset
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/256dpi/turing"
+)
+
+// Union is an operator used by AddMembers to add members to a set. Sets are
+// encoded as a sorted list of unique members, see EncodeSet and DecodeSet.
+var Union = &turing.Operator{
+	Name: "turing/Union",
+	Zero: []byte{},
+	Apply: func(value []byte, ops [][]byte) ([]byte, turing.Ref, error) {
+		// prepare sets
+		sets := make([][]byte, 0, len(ops)+1)
+		sets = append(sets, value)
+		sets = append(sets, ops...)
+
+		return unite(sets)
+	},
+	Combine: func(ops [][]byte) ([]byte, turing.Ref, error) {
+		return unite(ops)
+	},
+}
+
+func unite(sets [][]byte) ([]byte, turing.Ref, error) {
+	// collect members
+	var members [][]byte
+	for _, set := range sets {
+		list, err := DecodeList(set)
+		if err != nil {
+			return nil, nil, err
+		}
+		members = append(members, list...)
+	}
+
+	return EncodeSet(members), nil, nil
+}
+
+// EncodeSet will sort and deduplicate the provided members and encode them as
+// a set.
+func EncodeSet(members [][]byte) []byte {
+	// sort members
+	sorted := make([][]byte, len(members))
+	copy(sorted, members)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	// remove duplicates
+	unique := sorted[:0]
+	for i, member := range sorted {
+		if i == 0 || !bytes.Equal(member, sorted[i-1]) {
+			unique = append(unique, member)
+		}
+	}
+
+	return EncodeList(unique)
+}
+
+// DecodeSet will decode the provided set. The returned members are not copied
+// and reference the provided slice.
+func DecodeSet(set []byte) ([][]byte, error) {
+	return DecodeList(set)
+}
+
+// AddMembers will add members to a set.
+type AddMembers struct {
+	Key     []byte
+	Members [][]byte
+}
+
+var addMembersDesc = &turing.Description{
+	Name:      "turing/AddMembers",
+	Operators: []*turing.Operator{Union},
+	NoResult:  true,
+}
+
+// Describe implements the turing.Instruction interface.
+func (a *AddMembers) Describe() *turing.Description {
+	return addMembersDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (a *AddMembers) Effect() int {
+	return 1
+}
+
+// Execute implements the turing.Instruction interface.
+func (a *AddMembers) Execute(mem turing.Memory, _ turing.Cache) error {
+	return mem.Merge(a.Key, EncodeSet(a.Members), Union)
+}
+
+// Encode implements the turing.Instruction interface.
+func (a *AddMembers) Encode() ([]byte, turing.Ref, error) {
+	return encodeElements(a.Key, a.Members)
+}
+
+// Decode implements the turing.Instruction interface.
+func (a *AddMembers) Decode(bytes []byte) error {
+	return decodeElements(bytes, "add members", &a.Key, &a.Members)
+}
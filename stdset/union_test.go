@@ -0,0 +1,46 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestUnion(t *testing.T) {
+	operands := [][]byte{
+		EncodeSet([][]byte{[]byte("c"), []byte("a")}),
+		EncodeSet([][]byte{[]byte("b"), []byte("c")}),
+		EncodeSet([][]byte{[]byte("a"), []byte("d")}),
+	}
+
+	set, err := DecodeSet(checkOperator(t, Union, operands))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}, set)
+}
+
+func TestAddMembers(t *testing.T) {
+	machine := turing.Test(&AddMembers{}, &Get{})
+	defer machine.Stop()
+
+	err := machine.Execute(&AddMembers{
+		Key:     []byte("foo"),
+		Members: [][]byte{[]byte("b"), []byte("a")},
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&AddMembers{
+		Key:     []byte("foo"),
+		Members: [][]byte{[]byte("c"), []byte("a")},
+	})
+	assert.NoError(t, err)
+
+	get := &Get{Key: []byte("foo")}
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+
+	set, err := DecodeSet(get.Value)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, set)
+}
@@ -0,0 +1,89 @@
+package stdset
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Unset will remove a value.
+type Unset struct {
+	Key     []byte
+	Existed bool
+}
+
+var unsetDesc = &turing.Description{
+	Name: "turing/Unset",
+}
+
+// Describe implements the turing.Instruction interface.
+func (u *Unset) Describe() *turing.Description {
+	return unsetDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (u *Unset) Effect() int {
+	return 1
+}
+
+// Scope implements the turing.Scoped interface.
+func (u *Unset) Scope() ([][]byte, [][]byte) {
+	return [][]byte{u.Key}, [][]byte{u.Key}
+}
+
+// Execute implements the turing.Instruction interface.
+func (u *Unset) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check existence
+	u.Existed = false
+	err := mem.Use(u.Key, func([]byte) error {
+		u.Existed = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// skip if missing
+	if !u.Existed {
+		return nil
+	}
+
+	// unset key
+	err = mem.Unset(u.Key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (u *Unset) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Bool(u.Existed)
+		enc.Tail(u.Key)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (u *Unset) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("stdset: decode unset: invalid version")
+		}
+
+		// decode body
+		u.Existed = dec.Bool()
+		u.Key = dec.Tail(true)
+
+		return nil
+	})
+}
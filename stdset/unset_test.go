@@ -0,0 +1,59 @@
+package stdset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestUnset(t *testing.T) {
+	machine := turing.Test(&Unset{}, &Set{}, &Get{})
+	defer machine.Stop()
+
+	unset := &Unset{
+		Key: []byte("foo"),
+	}
+
+	err := machine.Execute(unset)
+	assert.NoError(t, err)
+	assert.False(t, unset.Existed)
+
+	err = machine.Execute(&Set{
+		Key:   []byte("foo"),
+		Value: []byte("bar"),
+	})
+	assert.NoError(t, err)
+
+	err = machine.Execute(unset)
+	assert.NoError(t, err)
+	assert.True(t, unset.Existed)
+
+	get := &Get{
+		Key: []byte("foo"),
+	}
+
+	err = machine.Execute(get)
+	assert.NoError(t, err)
+	assert.False(t, get.Exists)
+}
+
+func BenchmarkUnset(b *testing.B) {
+	machine := turing.Test(&Unset{})
+	defer machine.Stop()
+
+	unset := &Unset{
+		Key: []byte("foo"),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(unset)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -1,8 +1,15 @@
 package stdset
 
 import (
+	"encoding/binary"
+	"math"
+	"math/rand"
 	"os"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 
 	"github.com/256dpi/turing"
 )
@@ -14,3 +21,137 @@ func TestMain(m *testing.M) {
 	// run tests
 	os.Exit(m.Run())
 }
+
+func checkOperator(t *testing.T, op *turing.Operator, operands [][]byte) []byte {
+	apply := func(value []byte, ops [][]byte) []byte {
+		res, _, err := op.Apply(value, ops)
+		assert.NoError(t, err)
+		return turing.Clone(res)
+	}
+
+	combine := func(ops [][]byte) []byte {
+		res, _, err := op.Combine(ops)
+		assert.NoError(t, err)
+		return turing.Clone(res)
+	}
+
+	// apply sequentially
+	sequential := op.Zero
+	for _, operand := range operands {
+		sequential = apply(sequential, [][]byte{operand})
+	}
+
+	// apply at once
+	assert.Equal(t, sequential, apply(op.Zero, operands))
+
+	// return if combine is not available
+	if op.Combine == nil {
+		return sequential
+	}
+
+	// combine then apply at all split points
+	for i := 0; i <= len(operands); i++ {
+		left := combine(operands[:i])
+		right := combine(operands[i:])
+		assert.Equal(t, sequential, apply(op.Zero, [][]byte{left, right}))
+		assert.Equal(t, sequential, apply(op.Zero, [][]byte{combine([][]byte{left, right})}))
+	}
+
+	return sequential
+}
+
+func TestInstructions(t *testing.T) {
+	setup := []turing.Instruction{
+		&Set{Key: []byte("foo"), Value: []byte("1")},
+		&Push{Key: []byte("list"), Elements: [][]byte{[]byte("a")}},
+	}
+
+	for _, ins := range []turing.Instruction{
+		&Set{Key: []byte("foo"), Value: []byte("2")},
+		&Get{Key: []byte("foo")},
+		&Unset{Key: []byte("foo")},
+		&MUnset{Keys: [][]byte{[]byte("foo"), []byte("list")}},
+		&SetNX{Key: []byte("bar"), Value: []byte("2")},
+		&SetNX{Key: []byte("baz"), Value: []byte("2"), TTL: time.Hour},
+		&CAS{Key: []byte("foo"), Old: []byte("1"), Value: []byte("2")},
+		&CAS{Key: []byte("bar"), Value: []byte("2"), Absent: true},
+		&DeleteRange{Start: []byte("a"), End: []byte("z")},
+		&Inc{Key: []byte("foo"), Value: 2},
+		&IncGet{Key: []byte("foo"), Value: 2},
+		&Push{Key: []byte("list"), Elements: [][]byte{[]byte("b")}},
+		&AddMembers{Key: []byte("set"), Members: [][]byte{[]byte("b"), []byte("a")}},
+	} {
+		assert.NoError(t, turing.TestInstruction(ins, setup...), ins.Describe().Name)
+	}
+}
+
+func TestOperators(t *testing.T) {
+	bytes := func(r *rand.Rand, max int) []byte {
+		buf := make([]byte, r.Intn(max+1))
+		r.Read(buf)
+		return buf
+	}
+
+	items := func(r *rand.Rand) [][]byte {
+		list := make([][]byte, 1+r.Intn(3))
+		for i := range list {
+			list[i] = []byte(strconv.Itoa(r.Intn(100)))
+		}
+		return list
+	}
+
+	for _, item := range []struct {
+		op  *turing.Operator
+		gen func(r *rand.Rand) []byte
+	}{
+		{op: Add, gen: func(r *rand.Rand) []byte {
+			return []byte(strconv.Itoa(r.Intn(1000) - 500))
+		}},
+		{op: AddInt64, gen: func(r *rand.Rand) []byte {
+			return bytes(r, 8)
+		}},
+		{op: AddUint64, gen: func(r *rand.Rand) []byte {
+			buf := make([]byte, 8)
+			binary.BigEndian.PutUint64(buf, r.Uint64())
+			return buf
+		}},
+		{op: AddFloat64, gen: func(r *rand.Rand) []byte {
+			values := []float64{0.1, 0.2, 1e16, -1e16, r.Float64(), -r.Float64() * 1e8}
+			buf := make([]byte, 8)
+			binary.BigEndian.PutUint64(buf, math.Float64bits(values[r.Intn(len(values))]))
+			return buf
+		}},
+		{op: Max, gen: func(r *rand.Rand) []byte {
+			return bytes(r, 3)
+		}},
+		{op: Min, gen: func(r *rand.Rand) []byte {
+			return bytes(r, 3)
+		}},
+		{op: LWW, gen: func(r *rand.Rand) []byte {
+			return EncodeLWW(uint64(r.Intn(10)), bytes(r, 2))
+		}},
+		{op: Append, gen: func(r *rand.Rand) []byte {
+			return EncodeList(items(r))
+		}},
+		{op: Union, gen: func(r *rand.Rand) []byte {
+			return EncodeSet(items(r))
+		}},
+		{op: Or, gen: func(r *rand.Rand) []byte {
+			return bytes(r, 4)
+		}},
+		{op: And, gen: func(r *rand.Rand) []byte {
+			return bytes(r, 4)
+		}},
+		{op: CMS, gen: func(r *rand.Rand) []byte {
+			return cmsOperand(items(r), uint64(1+r.Intn(10)))
+		}},
+		{op: Bloom, gen: func(r *rand.Rand) []byte {
+			return bloomOperand(items(r))
+		}},
+		{op: HLL, gen: func(r *rand.Rand) []byte {
+			return hllOperand(items(r))
+		}},
+	} {
+		assert.NoError(t, turing.TestOperator(item.op, item.gen), item.op.Name)
+	}
+}
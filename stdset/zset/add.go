@@ -0,0 +1,125 @@
+package zset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Add will add members to a sorted set or update the score of existing
+// members (ZADD).
+type Add struct {
+	Key     []byte
+	Members []Member
+	Added   int
+}
+
+var addDesc = &turing.Description{
+	Name: "turing/zset/Add",
+}
+
+// Describe implements the turing.Instruction interface.
+func (a *Add) Describe() *turing.Description {
+	return addDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (a *Add) Effect() int {
+	return len(a.Members) * 3
+}
+
+// Execute implements the turing.Instruction interface.
+func (a *Add) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check key
+	err := checkKey(a.Key)
+	if err != nil {
+		return err
+	}
+
+	// add members
+	a.Added = 0
+	for _, member := range a.Members {
+		// check score
+		if math.IsNaN(member.Score) {
+			return ErrInvalidScore
+		}
+
+		// encode score
+		score := encodeScore(member.Score)
+
+		// get current score
+		current, exists, err := getScore(mem, a.Key, member.Member)
+		if err != nil {
+			return err
+		}
+
+		// skip if unchanged
+		if exists && current == score {
+			continue
+		}
+
+		// remove current score
+		if exists {
+			err = mem.Unset(scoreKey(a.Key, current, member.Member))
+			if err != nil {
+				return err
+			}
+		}
+
+		// set score
+		err = mem.Set(scoreKey(a.Key, score, member.Member), nil)
+		if err != nil {
+			return err
+		}
+
+		// set member
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, score)
+		err = mem.Set(memberKey(a.Key, member.Member), value)
+		if err != nil {
+			return err
+		}
+
+		// increment
+		if !exists {
+			a.Added++
+		}
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (a *Add) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.VarUint(uint64(a.Added))
+		enc.VarBytes(a.Key)
+		encodeMembers(enc, a.Members)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (a *Add) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("zset: decode add: invalid version")
+		}
+
+		// decode body
+		a.Added = int(dec.VarUint())
+		a.Key = dec.VarBytes(true)
+		a.Members = decodeMembers(dec)
+
+		return nil
+	})
+}
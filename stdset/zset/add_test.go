@@ -0,0 +1,65 @@
+package zset
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdd(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	add := &Add{Key: []byte("foo"), Members: []Member{
+		{Member: []byte("a"), Score: 2},
+		{Member: []byte("b"), Score: 1},
+	}}
+	err := machine.Execute(add)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, add.Added)
+
+	add = &Add{Key: []byte("foo"), Members: []Member{
+		{Member: []byte("a"), Score: 0},
+		{Member: []byte("b"), Score: 1},
+		{Member: []byte("c"), Score: 3},
+	}}
+	err = machine.Execute(add)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, add.Added)
+
+	rng := &RangeByScore{Key: []byte("foo"), Min: math.Inf(-1), Max: math.Inf(1)}
+	err = machine.Execute(rng)
+	assert.NoError(t, err)
+	assert.Equal(t, []Member{
+		{Member: []byte("a"), Score: 0},
+		{Member: []byte("b"), Score: 1},
+		{Member: []byte("c"), Score: 3},
+	}, rng.Members)
+
+	err = machine.Execute(&Add{Key: []byte("foo"), Members: []Member{
+		{Member: []byte("d"), Score: math.NaN()},
+	}})
+	assert.Equal(t, ErrInvalidScore, err)
+}
+
+func BenchmarkAdd(b *testing.B) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := machine.Execute(&Add{
+			Key: []byte("foo"),
+			Members: []Member{
+				{Member: []byte(strconv.Itoa(i)), Score: float64(i)},
+			},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package zset
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// RangeByScore will return the members of a sorted set with a score between
+// Min and Max inclusive ordered by score and member (ZRANGEBYSCORE).
+type RangeByScore struct {
+	Key     []byte
+	Min     float64
+	Max     float64
+	Limit   int
+	Reverse bool
+	Members []Member
+}
+
+var rangeByScoreDesc = &turing.Description{
+	Name: "turing/zset/RangeByScore",
+}
+
+// Describe implements the turing.Instruction interface.
+func (r *RangeByScore) Describe() *turing.Description {
+	return rangeByScoreDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (r *RangeByScore) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (r *RangeByScore) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check key
+	err := checkKey(r.Key)
+	if err != nil {
+		return err
+	}
+
+	// check scores
+	if math.IsNaN(r.Min) || math.IsNaN(r.Max) {
+		return ErrInvalidScore
+	}
+
+	// reset members
+	r.Members = nil
+
+	// check range
+	if r.Min > r.Max {
+		return nil
+	}
+
+	// compute range
+	start := scoreKey(r.Key, encodeScore(r.Min), nil)
+	end := makePrefix(r.Key, scoreKind+1, 0)
+	if max := encodeScore(r.Max); max < math.MaxUint64 {
+		end = scoreKey(r.Key, max+1, nil)
+	}
+
+	// create iterator
+	iter := mem.Range(start, end, turing.RangeOptions{
+		Reverse: r.Reverse,
+		Limit:   r.Limit,
+	})
+
+	// collect members
+	for iter.First(); iter.Valid(); iter.Next() {
+		// parse key
+		score, member, err := parseScoreKey(iter.TempKey(), r.Key)
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		// add member
+		r.Members = append(r.Members, Member{
+			Member: turing.Clone(member),
+			Score:  score,
+		})
+	}
+
+	// close iterator
+	err = iter.Close()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (r *RangeByScore) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode options
+		enc.Float64(r.Min)
+		enc.Float64(r.Max)
+		enc.VarUint(uint64(r.Limit))
+		enc.Bool(r.Reverse)
+
+		// encode key
+		enc.VarBytes(r.Key)
+
+		// encode members
+		encodeMembers(enc, r.Members)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (r *RangeByScore) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("zset: decode range by score: invalid version")
+		}
+
+		// decode options
+		r.Min = dec.Float64()
+		r.Max = dec.Float64()
+		r.Limit = int(dec.VarUint())
+		r.Reverse = dec.Bool()
+
+		// decode key
+		r.Key = dec.VarBytes(true)
+
+		// decode members
+		r.Members = decodeMembers(dec)
+
+		return nil
+	})
+}
@@ -0,0 +1,48 @@
+package zset
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeByScore(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	err := machine.Execute(&Add{Key: []byte("foo"), Members: []Member{
+		{Member: []byte("a"), Score: -1},
+		{Member: []byte("b"), Score: 2},
+		{Member: []byte("c"), Score: 2},
+		{Member: []byte("d"), Score: math.Inf(1)},
+	}})
+	assert.NoError(t, err)
+
+	err = machine.Execute(&Add{Key: []byte("bar"), Members: []Member{
+		{Member: []byte("x"), Score: 2},
+	}})
+	assert.NoError(t, err)
+
+	rng := &RangeByScore{Key: []byte("foo"), Min: -1, Max: 2}
+	err = machine.Execute(rng)
+	assert.NoError(t, err)
+	assert.Equal(t, []Member{
+		{Member: []byte("a"), Score: -1},
+		{Member: []byte("b"), Score: 2},
+		{Member: []byte("c"), Score: 2},
+	}, rng.Members)
+
+	rng = &RangeByScore{Key: []byte("foo"), Min: 0, Max: math.Inf(1), Limit: 2, Reverse: true}
+	err = machine.Execute(rng)
+	assert.NoError(t, err)
+	assert.Equal(t, []Member{
+		{Member: []byte("d"), Score: math.Inf(1)},
+		{Member: []byte("c"), Score: 2},
+	}, rng.Members)
+
+	rng = &RangeByScore{Key: []byte("foo"), Min: 3, Max: 2}
+	err = machine.Execute(rng)
+	assert.NoError(t, err)
+	assert.Empty(t, rng.Members)
+}
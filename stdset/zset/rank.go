@@ -0,0 +1,103 @@
+package zset
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Rank will return the zero based rank of a member in a sorted set ordered by
+// score and member (ZRANK).
+type Rank struct {
+	Key    []byte
+	Member []byte
+	Rank   int
+	Found  bool
+}
+
+var rankDesc = &turing.Description{
+	Name: "turing/zset/Rank",
+}
+
+// Describe implements the turing.Instruction interface.
+func (r *Rank) Describe() *turing.Description {
+	return rankDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (r *Rank) Effect() int {
+	return 0
+}
+
+// Execute implements the turing.Instruction interface.
+func (r *Rank) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check key
+	err := checkKey(r.Key)
+	if err != nil {
+		return err
+	}
+
+	// reset result
+	r.Rank = 0
+	r.Found = false
+
+	// get score
+	score, exists, err := getScore(mem, r.Key, r.Member)
+	if err != nil || !exists {
+		return err
+	}
+
+	// create iterator
+	iter := mem.Range(makePrefix(r.Key, scoreKind, 0), scoreKey(r.Key, score, r.Member), turing.RangeOptions{})
+
+	// count preceding members
+	for iter.First(); iter.Valid(); iter.Next() {
+		r.Rank++
+	}
+
+	// close iterator
+	err = iter.Close()
+	if err != nil {
+		return err
+	}
+
+	// set flag
+	r.Found = true
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (r *Rank) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode body
+		enc.Bool(r.Found)
+		enc.VarUint(uint64(r.Rank))
+		enc.VarBytes(r.Key)
+		enc.Tail(r.Member)
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (r *Rank) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("zset: decode rank: invalid version")
+		}
+
+		// decode body
+		r.Found = dec.Bool()
+		r.Rank = int(dec.VarUint())
+		r.Key = dec.VarBytes(true)
+		r.Member = dec.Tail(true)
+
+		return nil
+	})
+}
@@ -0,0 +1,36 @@
+package zset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRank(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	err := machine.Execute(&Add{Key: []byte("foo"), Members: []Member{
+		{Member: []byte("a"), Score: 3},
+		{Member: []byte("b"), Score: 1},
+		{Member: []byte("c"), Score: 2},
+	}})
+	assert.NoError(t, err)
+
+	rank := &Rank{Key: []byte("foo"), Member: []byte("a")}
+	err = machine.Execute(rank)
+	assert.NoError(t, err)
+	assert.True(t, rank.Found)
+	assert.Equal(t, 2, rank.Rank)
+
+	rank = &Rank{Key: []byte("foo"), Member: []byte("b")}
+	err = machine.Execute(rank)
+	assert.NoError(t, err)
+	assert.True(t, rank.Found)
+	assert.Equal(t, 0, rank.Rank)
+
+	rank = &Rank{Key: []byte("foo"), Member: []byte("d")}
+	err = machine.Execute(rank)
+	assert.NoError(t, err)
+	assert.False(t, rank.Found)
+}
@@ -0,0 +1,112 @@
+package zset
+
+import (
+	"fmt"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+)
+
+// Remove will remove members from a sorted set (ZREM).
+type Remove struct {
+	Key     []byte
+	Members [][]byte
+	Removed int
+}
+
+var removeDesc = &turing.Description{
+	Name: "turing/zset/Remove",
+}
+
+// Describe implements the turing.Instruction interface.
+func (r *Remove) Describe() *turing.Description {
+	return removeDesc
+}
+
+// Effect implements the turing.Instruction interface.
+func (r *Remove) Effect() int {
+	return len(r.Members) * 2
+}
+
+// Execute implements the turing.Instruction interface.
+func (r *Remove) Execute(mem turing.Memory, _ turing.Cache) error {
+	// check key
+	err := checkKey(r.Key)
+	if err != nil {
+		return err
+	}
+
+	// remove members
+	r.Removed = 0
+	for _, member := range r.Members {
+		// get score
+		score, exists, err := getScore(mem, r.Key, member)
+		if err != nil {
+			return err
+		} else if !exists {
+			continue
+		}
+
+		// remove score
+		err = mem.Unset(scoreKey(r.Key, score, member))
+		if err != nil {
+			return err
+		}
+
+		// remove member
+		err = mem.Unset(memberKey(r.Key, member))
+		if err != nil {
+			return err
+		}
+
+		r.Removed++
+	}
+
+	return nil
+}
+
+// Encode implements the turing.Instruction interface.
+func (r *Remove) Encode() ([]byte, turing.Ref, error) {
+	return fpack.Encode(true, func(enc *fpack.Encoder) error {
+		// encode version
+		enc.Uint8(1)
+
+		// encode removed
+		enc.VarUint(uint64(r.Removed))
+
+		// encode key
+		enc.VarBytes(r.Key)
+
+		// encode members
+		enc.VarUint(uint64(len(r.Members)))
+		for _, member := range r.Members {
+			enc.VarBytes(member)
+		}
+
+		return nil
+	})
+}
+
+// Decode implements the turing.Instruction interface.
+func (r *Remove) Decode(bytes []byte) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		if dec.Uint8() != 1 {
+			return fmt.Errorf("zset: decode remove: invalid version")
+		}
+
+		// decode removed
+		r.Removed = int(dec.VarUint())
+
+		// decode key
+		r.Key = dec.VarBytes(true)
+
+		// decode members
+		r.Members = make([][]byte, dec.VarUint())
+		for i := range r.Members {
+			r.Members[i] = dec.VarBytes(true)
+		}
+
+		return nil
+	})
+}
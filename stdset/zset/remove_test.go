@@ -0,0 +1,36 @@
+package zset
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemove(t *testing.T) {
+	machine := testMachine()
+	defer machine.Stop()
+
+	err := machine.Execute(&Add{Key: []byte("foo"), Members: []Member{
+		{Member: []byte("a"), Score: 1},
+		{Member: []byte("b"), Score: 2},
+	}})
+	assert.NoError(t, err)
+
+	remove := &Remove{Key: []byte("foo"), Members: [][]byte{[]byte("a"), []byte("c")}}
+	err = machine.Execute(remove)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, remove.Removed)
+
+	rng := &RangeByScore{Key: []byte("foo"), Min: math.Inf(-1), Max: math.Inf(1)}
+	err = machine.Execute(rng)
+	assert.NoError(t, err)
+	assert.Equal(t, []Member{
+		{Member: []byte("b"), Score: 2},
+	}, rng.Members)
+
+	rank := &Rank{Key: []byte("foo"), Member: []byte("a")}
+	err = machine.Execute(rank)
+	assert.NoError(t, err)
+	assert.False(t, rank.Found)
+}
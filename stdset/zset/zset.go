@@ -0,0 +1,158 @@
+// Package zset provides instructions that implement sorted sets modeled after
+// the Redis sorted set commands.
+//
+// Every set is stored under a key prefix derived from its name that starts with
+// a namespace reserved for sorted sets. A member key maps each member to its
+// score while a score key with an order-preserving encoding of the score
+// followed by the member keeps the members sorted.
+package zset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/256dpi/fpack"
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/stdset"
+)
+
+// ErrInvalidKey is returned if a set key is empty or too long.
+var ErrInvalidKey = fmt.Errorf("zset: invalid key")
+
+// ErrInvalidScore is returned if a score is not a number.
+var ErrInvalidScore = fmt.Errorf("zset: invalid score")
+
+// Namespace is the prefix of all keys used by sorted sets.
+const Namespace = stdset.Reserved + "z"
+
+const (
+	memberKind = 'm'
+	scoreKind  = 's'
+)
+
+// Member is a single member of a sorted set.
+type Member struct {
+	Member []byte
+	Score  float64
+}
+
+func checkKey(key []byte) error {
+	// check length
+	if len(key) == 0 || len(key) > 0xffff {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
+
+func makePrefix(key []byte, kind byte, extra int) []byte {
+	// prepare prefix
+	n := len(Namespace)
+	prefix := make([]byte, n+2+len(key)+1, n+2+len(key)+1+extra)
+	copy(prefix, Namespace)
+	binary.BigEndian.PutUint16(prefix[n:], uint16(len(key)))
+	copy(prefix[n+2:], key)
+	prefix[n+2+len(key)] = kind
+
+	return prefix
+}
+
+func memberKey(key, member []byte) []byte {
+	return append(makePrefix(key, memberKind, len(member)), member...)
+}
+
+func scoreKey(key []byte, score uint64, member []byte) []byte {
+	// prepare key
+	buf := makePrefix(key, scoreKind, 8+len(member))
+	buf = buf[:len(buf)+8]
+	binary.BigEndian.PutUint64(buf[len(buf)-8:], score)
+
+	return append(buf, member...)
+}
+
+func parseScoreKey(buf, key []byte) (float64, []byte, error) {
+	// check length
+	offset := len(Namespace) + 3 + len(key)
+	if len(buf) < offset+8 {
+		return 0, nil, fmt.Errorf("zset: invalid score key")
+	}
+
+	return decodeScore(binary.BigEndian.Uint64(buf[offset:])), buf[offset+8:], nil
+}
+
+func encodeScore(score float64) uint64 {
+	// normalize negative zero
+	if score == 0 {
+		score = 0
+	}
+
+	// flip all bits of negative and the sign bit of positive numbers to
+	// preserve the order when comparing big endian bytes
+	bits := math.Float64bits(score)
+	if bits&(1<<63) != 0 {
+		return ^bits
+	}
+
+	return bits | 1<<63
+}
+
+func decodeScore(bits uint64) float64 {
+	// reverse encoding
+	if bits&(1<<63) != 0 {
+		return math.Float64frombits(bits &^ (1 << 63))
+	}
+
+	return math.Float64frombits(^bits)
+}
+
+func getScore(mem turing.Memory, key, member []byte) (uint64, bool, error) {
+	// get score
+	var score uint64
+	var exists bool
+	err := mem.Use(memberKey(key, member), func(value []byte) error {
+		// check length
+		if len(value) != 8 {
+			return fmt.Errorf("zset: invalid member value")
+		}
+
+		// decode score
+		score = binary.BigEndian.Uint64(value)
+		exists = true
+
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return score, exists, nil
+}
+
+func encodeMembers(enc *fpack.Encoder, members []Member) {
+	// encode length
+	enc.VarUint(uint64(len(members)))
+
+	// encode members
+	for _, member := range members {
+		enc.Float64(member.Score)
+		enc.VarBytes(member.Member)
+	}
+}
+
+func decodeMembers(dec *fpack.Decoder) []Member {
+	// decode length
+	length := dec.VarUint()
+	if length == 0 {
+		return nil
+	}
+
+	// decode members
+	members := make([]Member, length)
+	for i := range members {
+		members[i].Score = dec.Float64()
+		members[i].Member = dec.VarBytes(true)
+	}
+
+	return members
+}
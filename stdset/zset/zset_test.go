@@ -0,0 +1,51 @@
+package zset
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+)
+
+func TestMain(m *testing.M) {
+	// disable logging
+	turing.SetLogger(nil)
+
+	// run tests
+	os.Exit(m.Run())
+}
+
+func testMachine() *turing.Machine {
+	return turing.Test(&Add{}, &RangeByScore{}, &Rank{}, &Remove{})
+}
+
+func TestScoreEncoding(t *testing.T) {
+	scores := []float64{math.Inf(-1), -math.MaxFloat64, -42.5, -1, -math.SmallestNonzeroFloat64, 0, math.SmallestNonzeroFloat64, 1, 42.5, math.MaxFloat64, math.Inf(1)}
+
+	var keys [][]byte
+	for _, score := range scores {
+		assert.Equal(t, score, decodeScore(encodeScore(score)))
+		keys = append(keys, scoreKey([]byte("foo"), encodeScore(score), nil))
+	}
+
+	assert.True(t, sort.SliceIsSorted(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i], keys[j]) < 0
+	}))
+
+	assert.Equal(t, encodeScore(0), encodeScore(math.Copysign(0, -1)))
+	assert.False(t, math.Signbit(decodeScore(encodeScore(math.Copysign(0, -1)))))
+
+	score, member, err := parseScoreKey(scoreKey([]byte("foo"), encodeScore(-7), []byte("bar")), []byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, -7.0, score)
+	assert.Equal(t, []byte("bar"), member)
+}
+
+func TestKeys(t *testing.T) {
+	assert.Equal(t, []byte{0, 'z', 0, 3, 'f', 'o', 'o', 'm', 'b', 'a', 'r'}, memberKey([]byte("foo"), []byte("bar")))
+}
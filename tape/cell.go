@@ -32,6 +32,9 @@ func (k CellType) Valid() bool {
 type Cell struct {
 	Type  CellType
 	Value []byte
+
+	// The optional expiry of the value in nanoseconds since the unix epoch.
+	Expiry int64
 }
 
 // Encode will encode the cell.
@@ -42,12 +45,21 @@ func (v *Cell) Encode(borrow bool) ([]byte, fpack.Ref, error) {
 	}
 
 	return fpack.Encode(borrow, func(enc *fpack.Encoder) error {
-		// write version
-		enc.Uint8(1)
+		// write version (expiring cells use the second version)
+		if v.Expiry != 0 {
+			enc.Uint8(2)
+		} else {
+			enc.Uint8(1)
+		}
 
 		// write type
 		enc.Uint8(uint8(v.Type))
 
+		// write expiry if available
+		if v.Expiry != 0 {
+			enc.Int64(v.Expiry)
+		}
+
 		// write value
 		enc.Tail(v.Value)
 
@@ -59,7 +71,8 @@ func (v *Cell) Encode(borrow bool) ([]byte, fpack.Ref, error) {
 func (v *Cell) Decode(bytes []byte, clone bool) error {
 	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
 		// check version
-		if dec.Uint8() != 1 {
+		version := dec.Uint8()
+		if version != 1 && version != 2 {
 			return fmt.Errorf("turing: decode cell: invalid version")
 		}
 
@@ -70,6 +83,12 @@ func (v *Cell) Decode(bytes []byte, clone bool) error {
 			return fmt.Errorf("turing: decode cell: invalid type: %d", v.Type)
 		}
 
+		// decode expiry
+		v.Expiry = 0
+		if version >= 2 {
+			v.Expiry = dec.Int64()
+		}
+
 		// decode value
 		v.Value = dec.Tail(clone)
 
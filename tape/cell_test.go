@@ -31,6 +31,30 @@ func TestCellCoding(t *testing.T) {
 	}))
 }
 
+func TestCellExpiryCoding(t *testing.T) {
+	in := Cell{
+		Type:   RawCell,
+		Value:  []byte("foo bar"),
+		Expiry: 42,
+	}
+
+	bytes, _, err := in.Encode(false)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("\x02\x01\x00\x00\x00\x00\x00\x00\x00\x54foo bar"), bytes)
+
+	var out Cell
+	err = out.Decode(bytes, false)
+	assert.NoError(t, err)
+	assert.Equal(t, in, out)
+
+	err = out.Decode([]byte("\x01\x01foo"), false)
+	assert.NoError(t, err)
+	assert.Equal(t, Cell{
+		Type:  RawCell,
+		Value: []byte("foo"),
+	}, out)
+}
+
 func BenchmarkCellEncode(b *testing.B) {
 	value := Cell{
 		Type:  RawCell,
@@ -0,0 +1,361 @@
+// Package testcluster provides an in-process multi-member cluster for testing
+// instructions against the replicated execution path.
+//
+// All members run in the current process, exchange raft messages using an
+// in-memory transport and store their data on in-memory filesystems that
+// survive restarts. Faults are injected by killing and restarting members,
+// partitioning the network and pausing disks.
+package testcluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	pfs "github.com/cockroachdb/pebble/vfs"
+	dfs "github.com/lni/goutils/vfs"
+
+	"github.com/256dpi/turing"
+)
+
+// Options is used to configure a cluster.
+type Options struct {
+	// The number of members.
+	//
+	// Default: 3.
+	Size int
+
+	// The used instructions.
+	Instructions []turing.Instruction
+
+	// The average round trip time. The election timeout is a thousand round
+	// trips, the default keeps failovers around one second.
+	//
+	// Default: 1ms.
+	RoundTripTime time.Duration
+
+	// The function called to adjust the configuration of every member before
+	// it is started.
+	Configure func(*turing.Config)
+}
+
+type member struct {
+	config  turing.Config
+	machine *turing.Machine
+	gate    *gate
+}
+
+// Cluster manages the members of an in-process cluster.
+type Cluster struct {
+	opts    Options
+	network *network
+	members []*member
+	mutex   sync.Mutex
+}
+
+// Start will create and start a new cluster.
+func Start(opts Options) (*Cluster, error) {
+	// set default size
+	if opts.Size == 0 {
+		opts.Size = 3
+	}
+
+	// set default round trip time
+	if opts.RoundTripTime == 0 {
+		opts.RoundTripTime = time.Millisecond
+	}
+
+	// allocate loopback ports
+	ports, err := freePorts(opts.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	// prepare list
+	list := make([]turing.Member, 0, opts.Size)
+	for i, port := range ports {
+		list = append(list, turing.Member{
+			ID:   uint64(i + 1),
+			Host: "127.0.0.1",
+			Port: port,
+		})
+	}
+
+	// prepare cluster
+	cluster := &Cluster{
+		opts:    opts,
+		network: newNetwork(),
+	}
+
+	// prepare members
+	factory := &factory{network: cluster.network}
+	for _, local := range list {
+		// prepare gate
+		gate := &gate{}
+
+		// prepare config
+		config := turing.Config{
+			ID:                 local.ID,
+			Members:            list,
+			Instructions:       opts.Instructions,
+			RoundTripTime:      opts.RoundTripTime,
+			RaftFileSystem:     dfs.Wrap(dfs.NewMem(), gate),
+			DatabaseFileSystem: &databaseFS{FS: pfs.NewMem(), gate: gate},
+			Transport:          factory,
+		}
+
+		// configure
+		if opts.Configure != nil {
+			opts.Configure(&config)
+		}
+
+		// add member
+		cluster.members = append(cluster.members, &member{
+			config: config,
+			gate:   gate,
+		})
+	}
+
+	// start members
+	for _, member := range cluster.members {
+		err := cluster.Restart(member.config.ID)
+		if err != nil {
+			cluster.Stop()
+			return nil, err
+		}
+	}
+
+	return cluster, nil
+}
+
+// Members returns the configured members.
+func (c *Cluster) Members() []turing.Member {
+	return c.members[0].config.Members
+}
+
+// Machine returns the machine of the specified member or nil if the member
+// is not running.
+func (c *Cluster) Machine(id uint64) *turing.Machine {
+	// acquire mutex
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.member(id).machine
+}
+
+// Kill will stop the specified member. The data of the member is retained
+// and used when it is restarted. Paused disks are resumed beforehand.
+func (c *Cluster) Kill(id uint64) {
+	// acquire mutex
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// get member
+	member := c.member(id)
+	if member.machine == nil {
+		return
+	}
+
+	// resume disk
+	member.gate.resume()
+
+	// stop machine
+	member.machine.Stop()
+	member.machine = nil
+}
+
+// Shutdown will gracefully stop the specified member. Queued instructions
+// are drained and leadership is transferred if the member is the leader.
+func (c *Cluster) Shutdown(ctx context.Context, id uint64) error {
+	// acquire mutex
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// get member
+	member := c.member(id)
+	if member.machine == nil {
+		return nil
+	}
+
+	// resume disk
+	member.gate.resume()
+
+	// shutdown machine
+	err := member.machine.Shutdown(ctx)
+	member.machine = nil
+
+	return err
+}
+
+// Restart will start the specified member if it is not running.
+func (c *Cluster) Restart(id uint64) error {
+	// acquire mutex
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// get member
+	member := c.member(id)
+	if member.machine != nil {
+		return nil
+	}
+
+	// start machine
+	machine, err := turing.Start(member.config)
+	if err != nil {
+		return err
+	}
+
+	// set machine
+	member.machine = machine
+
+	return nil
+}
+
+// Partition will split the network into the specified groups of members.
+// Members only receive messages from members in the same group. Members not
+// listed in any group are isolated.
+func (c *Cluster) Partition(groups ...[]uint64) {
+	// acquire mutex
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// map addresses to groups
+	table := map[string]int{}
+	for i, group := range groups {
+		for _, id := range group {
+			table[c.member(id).config.Local().Address()] = i
+		}
+	}
+
+	// set partition
+	c.network.partition(table)
+}
+
+// Isolate will partition the specified member from all other members.
+func (c *Cluster) Isolate(id uint64) {
+	// collect others
+	var others []uint64
+	for _, member := range c.members {
+		if member.config.ID != id {
+			others = append(others, member.config.ID)
+		}
+	}
+
+	// partition
+	c.Partition([]uint64{id}, others)
+}
+
+// Heal will remove all partitions.
+func (c *Cluster) Heal() {
+	c.network.partition(nil)
+}
+
+// PauseDisk will block all writes and syncs of the specified member until the
+// disk is resumed.
+func (c *Cluster) PauseDisk(id uint64) {
+	// acquire mutex
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// pause
+	c.member(id).gate.pause()
+}
+
+// ResumeDisk will resume a paused disk of the specified member.
+func (c *Cluster) ResumeDisk(id uint64) {
+	// acquire mutex
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// resume
+	c.member(id).gate.resume()
+}
+
+// Leader returns the id of the running member that is currently the leader.
+// It returns zero if no or multiple members claim leadership.
+func (c *Cluster) Leader() uint64 {
+	// acquire mutex
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// find leader
+	var leader uint64
+	for _, member := range c.members {
+		if member.machine != nil && member.machine.Status().Role == turing.RoleLeader {
+			if leader != 0 {
+				return 0
+			}
+			leader = member.config.ID
+		}
+	}
+
+	return leader
+}
+
+// WaitForLeader will wait until a single member claims leadership and return
+// its id. Members given as exclusions are not accepted as leaders. A new
+// leader may reject linear reads until it committed an entry in its term.
+func (c *Cluster) WaitForLeader(timeout time.Duration, exclude ...uint64) (uint64, error) {
+	// get deadline
+	deadline := time.Now().Add(timeout)
+
+	for {
+		// check leader
+		leader := c.Leader()
+		if leader != 0 && !contains(exclude, leader) {
+			return leader, nil
+		}
+
+		// check deadline
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("turing: test cluster: no leader after %s", timeout)
+		}
+
+		// wait
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Stop will stop all running members.
+func (c *Cluster) Stop() {
+	// kill members
+	for _, member := range c.members {
+		c.Kill(member.config.ID)
+	}
+}
+
+func (c *Cluster) member(id uint64) *member {
+	// check id
+	if id == 0 || id > uint64(len(c.members)) {
+		panic(fmt.Sprintf("turing: test cluster: unknown member %d", id))
+	}
+
+	return c.members[id-1]
+}
+
+func freePorts(n int) ([]int, error) {
+	// listen on random loopback ports
+	ports := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return nil, err
+		}
+		defer listener.Close()
+		ports = append(ports, listener.Addr().(*net.TCPAddr).Port)
+	}
+
+	return ports, nil
+}
+
+func contains(list []uint64, id uint64) bool {
+	for _, item := range list {
+		if item == id {
+			return true
+		}
+	}
+
+	return false
+}
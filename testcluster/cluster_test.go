@@ -0,0 +1,165 @@
+package testcluster
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/turing"
+	"github.com/256dpi/turing/stdset"
+)
+
+func TestMain(m *testing.M) {
+	// disable logging
+	turing.SetLogger(nil)
+
+	// run tests
+	os.Exit(m.Run())
+}
+
+func get(t *testing.T, machine *turing.Machine, key string, stale bool) []byte {
+	get := &stdset.Get{Key: []byte(key)}
+	err := machine.Execute(get, turing.Options{StaleRead: stale})
+	assert.NoError(t, err)
+	return get.Value
+}
+
+func TestClusterFailover(t *testing.T) {
+	cluster, err := Start(Options{
+		Instructions: []turing.Instruction{&stdset.Set{}, &stdset.Get{}},
+	})
+	assert.NoError(t, err)
+	defer cluster.Stop()
+
+	leader, err := cluster.WaitForLeader(10 * time.Second)
+	assert.NoError(t, err)
+
+	err = cluster.Machine(leader).Execute(&stdset.Set{Key: []byte("foo"), Value: []byte("1")})
+	assert.NoError(t, err)
+
+	cluster.Kill(leader)
+	assert.Nil(t, cluster.Machine(leader))
+
+	next, err := cluster.WaitForLeader(10*time.Second, leader)
+	assert.NoError(t, err)
+	assert.NotEqual(t, leader, next)
+
+	assert.Eventually(t, func() bool {
+		get := &stdset.Get{Key: []byte("foo")}
+		err := cluster.Machine(next).Execute(get)
+		return err == nil && string(get.Value) == "1"
+	}, 10*time.Second, 10*time.Millisecond)
+
+	err = cluster.Machine(next).Execute(&stdset.Set{Key: []byte("foo"), Value: []byte("2")})
+	assert.NoError(t, err)
+
+	err = cluster.Restart(leader)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		get := &stdset.Get{Key: []byte("foo")}
+		err := cluster.Machine(leader).Execute(get, turing.Options{StaleRead: true})
+		return err == nil && string(get.Value) == "2"
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestClusterPartition(t *testing.T) {
+	cluster, err := Start(Options{
+		Instructions: []turing.Instruction{&stdset.Set{}, &stdset.Get{}},
+		Configure: func(config *turing.Config) {
+			config.ProposalTimeout = 200 * time.Millisecond
+		},
+	})
+	assert.NoError(t, err)
+	defer cluster.Stop()
+
+	leader, err := cluster.WaitForLeader(10 * time.Second)
+	assert.NoError(t, err)
+
+	cluster.Isolate(leader)
+
+	err = cluster.Machine(leader).Execute(&stdset.Set{Key: []byte("foo"), Value: []byte("1")})
+	assert.Error(t, err)
+
+	next, err := cluster.WaitForLeader(10*time.Second, leader)
+	assert.NoError(t, err)
+
+	err = cluster.Machine(next).Execute(&stdset.Set{Key: []byte("foo"), Value: []byte("2")})
+	assert.NoError(t, err)
+
+	cluster.Heal()
+
+	assert.Eventually(t, func() bool {
+		get := &stdset.Get{Key: []byte("foo")}
+		err := cluster.Machine(leader).Execute(get, turing.Options{StaleRead: true})
+		return err == nil && string(get.Value) == "2"
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func TestClusterPauseDisk(t *testing.T) {
+	cluster, err := Start(Options{
+		Size:         1,
+		Instructions: []turing.Instruction{&stdset.Set{}, &stdset.Get{}},
+	})
+	assert.NoError(t, err)
+	defer cluster.Stop()
+
+	_, err = cluster.WaitForLeader(10 * time.Second)
+	assert.NoError(t, err)
+
+	cluster.PauseDisk(1)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cluster.Machine(1).Execute(&stdset.Set{Key: []byte("foo"), Value: []byte("1")})
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected write to block")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cluster.ResumeDisk(1)
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, []byte("1"), get(t, cluster.Machine(1), "foo", false))
+}
+
+func TestClusterShutdown(t *testing.T) {
+	cluster, err := Start(Options{
+		Instructions: []turing.Instruction{&stdset.Set{}, &stdset.Get{}},
+	})
+	assert.NoError(t, err)
+	defer cluster.Stop()
+
+	leader, err := cluster.WaitForLeader(10 * time.Second)
+	assert.NoError(t, err)
+
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		err = cluster.Machine(leader).ExecuteAsync(&stdset.Set{Key: []byte("foo"), Value: []byte("1")}, func(err error) {
+			errs <- err
+		})
+		assert.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = cluster.Shutdown(ctx, leader)
+	assert.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, <-errs)
+	}
+
+	next, err := cluster.WaitForLeader(time.Second, leader)
+	assert.NoError(t, err)
+	assert.NotEqual(t, leader, next)
+
+	assert.Equal(t, []byte("1"), get(t, cluster.Machine(next), "foo", true))
+}
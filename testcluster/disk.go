@@ -0,0 +1,104 @@
+package testcluster
+
+import (
+	"sync"
+
+	pfs "github.com/cockroachdb/pebble/vfs"
+	dfs "github.com/lni/goutils/vfs"
+)
+
+// gate blocks writes while a disk is paused.
+type gate struct {
+	mutex sync.Mutex
+	ch    chan struct{}
+}
+
+func (g *gate) pause() {
+	// acquire mutex
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	// create channel
+	if g.ch == nil {
+		g.ch = make(chan struct{})
+	}
+}
+
+func (g *gate) resume() {
+	// acquire mutex
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	// release waiters
+	if g.ch != nil {
+		close(g.ch)
+		g.ch = nil
+	}
+}
+
+func (g *gate) wait() {
+	// get channel
+	g.mutex.Lock()
+	ch := g.ch
+	g.mutex.Unlock()
+
+	// await resume
+	if ch != nil {
+		<-ch
+	}
+}
+
+// MaybeError implements the dfs.Injector interface. The raft filesystem must
+// be a dfs.ErrorFS as dragonboat rejects other wrappers.
+func (g *gate) MaybeError(op dfs.Op) error {
+	// await resume on writes and syncs
+	if op == dfs.OpWrite || op == dfs.OpSync {
+		g.wait()
+	}
+
+	return nil
+}
+
+type databaseFS struct {
+	pfs.FS
+	gate *gate
+}
+
+func (f *databaseFS) Create(name string) (pfs.File, error) {
+	return f.wrap(f.FS.Create(name))
+}
+
+func (f *databaseFS) Open(name string, opts ...pfs.OpenOption) (pfs.File, error) {
+	return f.wrap(f.FS.Open(name, opts...))
+}
+
+func (f *databaseFS) OpenDir(name string) (pfs.File, error) {
+	return f.wrap(f.FS.OpenDir(name))
+}
+
+func (f *databaseFS) ReuseForWrite(oldname, newname string) (pfs.File, error) {
+	return f.wrap(f.FS.ReuseForWrite(oldname, newname))
+}
+
+func (f *databaseFS) wrap(file pfs.File, err error) (pfs.File, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return &databaseFile{File: file, gate: f.gate}, nil
+}
+
+type databaseFile struct {
+	pfs.File
+	gate *gate
+}
+
+func (f *databaseFile) Write(p []byte) (int, error) {
+	f.gate.wait()
+	return f.File.Write(p)
+}
+
+func (f *databaseFile) Sync() error {
+	f.gate.wait()
+	return f.File.Sync()
+}
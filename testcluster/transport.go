@@ -0,0 +1,213 @@
+package testcluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lni/dragonboat/v3/config"
+	"github.com/lni/dragonboat/v3/raftio"
+	pb "github.com/lni/dragonboat/v3/raftpb"
+)
+
+// network connects the transports of a cluster in-process and drops traffic
+// between partitioned members.
+type network struct {
+	mutex      sync.RWMutex
+	transports map[string]*transport
+	groups     map[string]int
+}
+
+func newNetwork() *network {
+	return &network{
+		transports: map[string]*transport{},
+	}
+}
+
+func (n *network) partition(groups map[string]int) {
+	// acquire mutex
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	// set groups
+	n.groups = groups
+}
+
+func (n *network) lookup(from, to string) (*transport, error) {
+	// get transport
+	target := n.transports[to]
+	if target == nil {
+		return nil, fmt.Errorf("turing: test transport: unreachable %s", to)
+	}
+
+	// check partition
+	if n.groups != nil {
+		src, ok1 := n.groups[from]
+		dst, ok2 := n.groups[to]
+		if !ok1 || !ok2 || src != dst {
+			return nil, fmt.Errorf("turing: test transport: partitioned %s -> %s", from, to)
+		}
+	}
+
+	return target, nil
+}
+
+func (n *network) send(from, to string, batch pb.MessageBatch) error {
+	// acquire mutex
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	// get target
+	target, err := n.lookup(from, to)
+	if err != nil {
+		return err
+	}
+
+	// copy batch
+	data, err := batch.Marshal()
+	if err != nil {
+		return err
+	}
+	var copied pb.MessageBatch
+	err = copied.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	// deliver batch
+	target.handler(copied)
+
+	return nil
+}
+
+func (n *network) sendChunk(from, to string, chunk pb.Chunk) error {
+	// acquire mutex
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	// get target
+	target, err := n.lookup(from, to)
+	if err != nil {
+		return err
+	}
+
+	// copy chunk
+	data, err := chunk.Marshal()
+	if err != nil {
+		return err
+	}
+	var copied pb.Chunk
+	err = copied.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	// deliver chunk
+	if !target.chunkHandler(copied) {
+		return fmt.Errorf("turing: test transport: chunk rejected")
+	}
+
+	return nil
+}
+
+type factory struct {
+	network *network
+}
+
+func (f *factory) Create(cfg config.NodeHostConfig, handler raftio.MessageHandler, chunkHandler raftio.ChunkHandler) raftio.ITransport {
+	return &transport{
+		network:      f.network,
+		address:      cfg.RaftAddress,
+		handler:      handler,
+		chunkHandler: chunkHandler,
+	}
+}
+
+func (f *factory) Validate(string) bool {
+	return true
+}
+
+type transport struct {
+	network      *network
+	address      string
+	handler      raftio.MessageHandler
+	chunkHandler raftio.ChunkHandler
+}
+
+func (t *transport) Name() string {
+	return "turing-test-transport"
+}
+
+func (t *transport) Start() error {
+	// acquire mutex
+	t.network.mutex.Lock()
+	defer t.network.mutex.Unlock()
+
+	// register transport
+	t.network.transports[t.address] = t
+
+	return nil
+}
+
+func (t *transport) Stop() {
+	// acquire mutex
+	t.network.mutex.Lock()
+	defer t.network.mutex.Unlock()
+
+	// unregister transport
+	if t.network.transports[t.address] == t {
+		delete(t.network.transports, t.address)
+	}
+}
+
+func (t *transport) GetConnection(_ context.Context, target string) (raftio.IConnection, error) {
+	// acquire mutex
+	t.network.mutex.RLock()
+	defer t.network.mutex.RUnlock()
+
+	// check target
+	_, err := t.network.lookup(t.address, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &connection{
+		network: t.network,
+		from:    t.address,
+		to:      target,
+	}, nil
+}
+
+func (t *transport) GetSnapshotConnection(_ context.Context, target string) (raftio.ISnapshotConnection, error) {
+	// acquire mutex
+	t.network.mutex.RLock()
+	defer t.network.mutex.RUnlock()
+
+	// check target
+	_, err := t.network.lookup(t.address, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &connection{
+		network: t.network,
+		from:    t.address,
+		to:      target,
+	}, nil
+}
+
+type connection struct {
+	network *network
+	from    string
+	to      string
+}
+
+func (c *connection) Close() {}
+
+func (c *connection) SendMessageBatch(batch pb.MessageBatch) error {
+	return c.network.send(c.from, c.to, batch)
+}
+
+func (c *connection) SendChunk(chunk pb.Chunk) error {
+	return c.network.sendChunk(c.from, c.to, chunk)
+}
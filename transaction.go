@@ -1,9 +1,13 @@
 package turing
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"math/rand"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/256dpi/fpack"
 	"github.com/cockroachdb/pebble"
@@ -15,15 +19,29 @@ import (
 
 var userPrefix = []byte("#")
 
+var _, userLimit = PrefixRange(userPrefix)
+
 type transaction struct {
 	config    Config
 	registry  *registry
 	current   Instruction
 	reader    pebble.Reader
 	writer    pebble.Writer
+	index     uint64
+	now       int64
+	seed      int64
+	random    *rand.Rand
+	seeded    bool
 	closers   int
 	iterators int
 	effect    int
+	base      int
+	writes    int
+	spent     int
+	exceeded  bool
+	resume    []byte
+	recorded  []byte
+	continued bool
 }
 
 var transactionPool = sync.Pool{
@@ -41,21 +59,68 @@ func recycleTransaction(txn *transaction) {
 	txn.current = nil
 	txn.reader = nil
 	txn.writer = nil
+	txn.index = 0
+	txn.now = 0
+	txn.seed = 0
+	txn.seeded = false
 	txn.closers = 0
 	txn.iterators = 0
 	txn.effect = 0
+	txn.base = 0
+	txn.writes = 0
+	txn.spent = 0
+	txn.exceeded = false
+	txn.resume = nil
+	txn.recorded = nil
+	txn.continued = false
 	transactionPool.Put(txn)
 }
 
-func (t *transaction) execute(ins Instruction, cache Cache) (bool, error) {
+func (t *transaction) execute(ins Instruction, cache Cache) (effectMaxed bool, err error) {
 	// set instruction
 	t.current = ins
 
+	// reset random
+	t.seeded = false
+
+	// reset effect tracking
+	t.base = t.effect
+	t.spent = t.writes
+	t.exceeded = false
+
+	// reset continuation
+	t.recorded = nil
+	t.continued = false
+
+	// convert panics
+	defer func() {
+		if val := recover(); val != nil {
+			effectMaxed, err = false, recovered(ins.Describe().Name, val)
+		}
+	}()
+
 	// execute transaction
-	var effectMaxed bool
-	err := ins.Execute(t, cache)
+	err = ins.Execute(t, cache)
+
+	// track effect of writes
+	if t.writer != nil {
+		t.track(ins)
+	}
+
+	// reject if declared effect has been exceeded
+	if t.exceeded {
+		return false, &EffectError{
+			Name:     ins.Describe().Name,
+			Declared: ins.Effect(),
+		}
+	}
+
+	// handle error
 	if err == ErrMaxEffect {
 		effectMaxed = true
+	} else if perr, ok := asPanic(err); ok {
+		// unwrap panic error
+		return false, perr
 	} else if err != nil {
 		return false, err
 	}
@@ -90,14 +155,23 @@ func (t *transaction) Get(key []byte) ([]byte, bool, io.Closer, error) {
 	var cell tape.Cell
 	err = cell.Decode(bytes, false)
 	if err != nil {
-		_ = closer.Close()
+		// prefer merge error reported by closer
+		if cerr := closer.Close(); cerr != nil {
+			return nil, false, nil, cerr
+		}
 		return nil, false, nil, err
 	}
 
-	// check type
+	// check type (stack cells are resolved by the merge operator)
 	if cell.Type != tape.RawCell {
-		// we never see stack cells as they are resolved by the merge operator
-		panic("turing: expected raw cell")
+		_ = closer.Close()
+		return nil, false, nil, fmt.Errorf("turing: transaction get: expected raw cell, got: %d", cell.Type)
+	}
+
+	// check expiry
+	if t.expired(cell) {
+		_ = closer.Close()
+		return nil, false, noopCloser, nil
 	}
 
 	// increment closers
@@ -137,21 +211,117 @@ func (t *transaction) Use(key []byte, fn func(value []byte) error) error {
 	return err
 }
 
+func (t *transaction) GetMany(keys [][]byte, fn func(i int, value []byte) error) error {
+	// skip if empty
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// sort indexes by key
+	indexes := make([]int, len(keys))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return bytes.Compare(keys[indexes[i]], keys[indexes[j]]) < 0
+	})
+
+	// prefix smallest key
+	lk, lkr := prefixUserKey(keys[indexes[0]])
+	defer lkr.Release()
+
+	// create iterator
+	iter := t.reader.NewIter(&pebble.IterOptions{
+		LowerBound: lk,
+		UpperBound: userLimit,
+	})
+
+	// lookup keys
+	for _, index := range indexes {
+		// prefix key
+		pk, pkr := prefixUserKey(keys[index])
+
+		// seek key
+		found := iter.SeekGE(pk) && bytes.Equal(iter.Key(), pk)
+		pkr.Release()
+		if !found {
+			continue
+		}
+
+		// decode cell
+		var cell tape.Cell
+		err := cell.Decode(iter.Value(), false)
+		if err != nil {
+			// prefer merge error reported by iterator
+			if ierr := iter.Close(); ierr != nil {
+				return ierr
+			}
+			return err
+		}
+
+		// check type (stack cells are resolved by the merge operator)
+		if cell.Type != tape.RawCell {
+			_ = iter.Close()
+			return fmt.Errorf("turing: transaction get many: expected raw cell, got: %d", cell.Type)
+		}
+
+		// skip expired values
+		if t.expired(cell) {
+			continue
+		}
+
+		// yield value
+		err = fn(index, cell.Value)
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+	}
+
+	// close iterator
+	err := iter.Close()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (t *transaction) Set(key, value []byte) error {
+	return t.set(key, value, 0)
+}
+
+func (t *transaction) SetWithTTL(key, value []byte, ttl time.Duration) error {
+	// check ttl
+	if ttl <= 0 {
+		return fmt.Errorf("turing: invalid ttl: %s", ttl)
+	}
+
+	// check time (legacy commands do not carry a proposal time)
+	if t.now == 0 {
+		return ErrMissingTime
+	}
+
+	return t.set(key, value, t.now+int64(ttl))
+}
+
+func (t *transaction) set(key, value []byte, expiry int64) error {
 	// check writer
 	if t.writer == nil {
 		return ErrReadOnly
 	}
 
 	// check effect
-	if t.effect >= t.config.MaxEffect {
-		return ErrMaxEffect
+	err := t.checkEffect()
+	if err != nil {
+		return err
 	}
 
 	// prepare cell
 	cell := tape.Cell{
-		Type:  tape.RawCell,
-		Value: value,
+		Type:   tape.RawCell,
+		Value:  value,
+		Expiry: expiry,
 	}
 
 	// encode cell
@@ -173,8 +343,22 @@ func (t *transaction) Set(key, value []byte) error {
 		return err
 	}
 
+	// add expiry index entry if available
+	if expiry != 0 {
+		ek, ekr := expiryKey(expiry, key)
+		defer ekr.Release()
+		err = t.writer.Set(ek, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		// increment writes
+		t.writes++
+	}
+
 	// increment effect
 	t.effect++
+	t.writes++
 
 	return nil
 }
@@ -186,8 +370,9 @@ func (t *transaction) Unset(key []byte) error {
 	}
 
 	// check effect
-	if t.effect >= t.config.MaxEffect {
-		return ErrMaxEffect
+	err := t.checkEffect()
+	if err != nil {
+		return err
 	}
 
 	// prefix key
@@ -195,13 +380,14 @@ func (t *transaction) Unset(key []byte) error {
 	defer pkr.Release()
 
 	// delete key
-	err := t.writer.Delete(pk, nil)
+	err = t.writer.Delete(pk, nil)
 	if err != nil {
 		return err
 	}
 
 	// increment effect
 	t.effect++
+	t.writes++
 
 	return nil
 }
@@ -213,8 +399,9 @@ func (t *transaction) Delete(start, end []byte) error {
 	}
 
 	// check effect
-	if t.effect >= t.config.MaxEffect {
-		return ErrMaxEffect
+	err := t.checkEffect()
+	if err != nil {
+		return err
 	}
 
 	// prefix keys
@@ -224,13 +411,14 @@ func (t *transaction) Delete(start, end []byte) error {
 	defer ekr.Release()
 
 	// delete range
-	err := t.writer.DeleteRange(sk, ek, nil)
+	err = t.writer.DeleteRange(sk, ek, nil)
 	if err != nil {
 		return err
 	}
 
 	// increment effect
 	t.effect++
+	t.writes++
 
 	return nil
 }
@@ -242,8 +430,9 @@ func (t *transaction) Merge(key, value []byte, operator *Operator) error {
 	}
 
 	// check effect
-	if t.effect >= t.config.MaxEffect {
-		return ErrMaxEffect
+	err := t.checkEffect()
+	if err != nil {
+		return err
 	}
 
 	// check registry
@@ -298,6 +487,12 @@ func (t *transaction) Merge(key, value []byte, operator *Operator) error {
 	pk, pkr := prefixUserKey(key)
 	defer pkr.Release()
 
+	// clear expired value
+	err = t.clearExpired(pk)
+	if err != nil {
+		return err
+	}
+
 	// merge value
 	err = t.writer.Merge(pk, cellValue, nil)
 	if err != nil {
@@ -306,14 +501,152 @@ func (t *transaction) Merge(key, value []byte, operator *Operator) error {
 
 	// increment effect
 	t.effect++
+	t.writes++
 
 	return nil
 }
 
+func (t *transaction) checkEffect() error {
+	// get declared effect
+	declared := t.current.Effect()
+
+	// check global effect, bounded instructions may always use the writes
+	// reserved for their declared effect
+	limit := t.config.MaxEffect
+	if declared > 0 && t.spent+2*declared > limit {
+		limit = t.spent + 2*declared
+	}
+	if t.writes >= limit {
+		return ErrMaxEffect
+	}
+
+	// check declared effect if strict
+	if t.config.StrictEffect {
+		if declared > 0 && t.effect-t.base >= declared {
+			t.exceeded = true
+			return &EffectError{
+				Name:     t.current.Describe().Name,
+				Declared: declared,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *transaction) track(ins Instruction) {
+	// get description and effects
+	desc := ins.Describe()
+	declared := ins.Effect()
+	actual := t.effect - t.base
+
+	// count actual effect
+	desc.actual.Add(float64(actual))
+
+	// count declared effect and misreports if bounded
+	if declared > 0 {
+		desc.declared.Add(float64(declared))
+		if actual > declared || t.exceeded {
+			desc.misreports.Inc()
+		}
+	}
+}
+
+func (t *transaction) Continue(state []byte) error {
+	// check writer
+	if t.writer == nil {
+		return ErrReadOnly
+	}
+
+	// record state
+	t.recorded = nil
+	if len(state) > 0 {
+		t.recorded = Clone(state)
+	}
+	t.continued = true
+
+	return nil
+}
+
+func (t *transaction) Continuation() []byte {
+	return t.resume
+}
+
+// proceed will carry over the continuation recorded by the last execution.
+func (t *transaction) proceed() {
+	if t.continued {
+		t.resume = t.recorded
+	}
+}
+
 func (t *transaction) Effect() int {
 	return t.effect
 }
 
+func (t *transaction) Now() time.Time {
+	return time.Unix(0, t.now).UTC()
+}
+
+func (t *transaction) Rand() *rand.Rand {
+	// create random if missing
+	if t.random == nil {
+		t.random = rand.New(rand.NewSource(t.seed))
+		t.seeded = true
+	}
+
+	// seed random if not yet seeded
+	if !t.seeded {
+		t.random.Seed(t.seed)
+		t.seeded = true
+	}
+
+	return t.random
+}
+
+func (t *transaction) expired(cell tape.Cell) bool {
+	return cell.Expiry != 0 && cell.Expiry <= t.now
+}
+
+// clearExpired will delete the key if the current value has expired. This
+// ensures that merged operands are applied to the zero value instead of
+// inheriting the expiry of the expired value. The stale expiry index entry is
+// removed by the next sweep.
+func (t *transaction) clearExpired(pk []byte) error {
+	// get value
+	value, closer, err := t.reader.Get(pk)
+	if err == pebble.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// ensure close
+	defer closer.Close()
+
+	// decode cell
+	var cell tape.Cell
+	err = cell.Decode(value, false)
+	if err != nil {
+		return err
+	}
+
+	// check expiry
+	if !t.expired(cell) {
+		return nil
+	}
+
+	// delete key
+	err = t.writer.Delete(pk, nil)
+	if err != nil {
+		return err
+	}
+
+	// increment writes
+	t.writes++
+
+	return nil
+}
+
 func (t *transaction) Iterate(prefix []byte) Iterator {
 	// increment iterators
 	t.iterators++
@@ -328,11 +661,41 @@ func (t *transaction) Iterate(prefix []byte) Iterator {
 	}
 }
 
+func (t *transaction) Range(start, end []byte, opts RangeOptions) Iterator {
+	// increment iterators
+	t.iterators++
+
+	// prefix start
+	sk, skr := prefixUserKey(start)
+
+	// prefix end if available
+	ek, ekr := userLimit, Ref(noopRef)
+	if len(end) > 0 {
+		ek, ekr = prefixUserKey(end)
+	}
+
+	return &iterator{
+		txn:     t,
+		pkr:     skr,
+		ekr:     ekr,
+		reverse: opts.Reverse,
+		limit:   opts.Limit,
+		iter: t.reader.NewIter(&pebble.IterOptions{
+			LowerBound: sk,
+			UpperBound: ek,
+		}),
+	}
+}
+
 type iterator struct {
-	txn    *transaction
-	pkr    Ref
-	iter   *pebble.Iterator
-	closed bool
+	txn     *transaction
+	pkr     Ref
+	ekr     Ref
+	iter    *pebble.Iterator
+	reverse bool
+	limit   int
+	count   int
+	closed  bool
 }
 
 func (i *iterator) SeekGE(key []byte) bool {
@@ -340,7 +703,10 @@ func (i *iterator) SeekGE(key []byte) bool {
 	pKey, pKeyRef := prefixUserKey(key)
 	defer pKeyRef.Release()
 
-	return i.iter.SeekGE(pKey)
+	// reset count
+	i.count = 0
+
+	return i.skip(i.iter.SeekGE(pKey), true) && i.Valid()
 }
 
 func (i *iterator) SeekLT(key []byte) bool {
@@ -348,27 +714,88 @@ func (i *iterator) SeekLT(key []byte) bool {
 	pKey, pKeyRef := prefixUserKey(key)
 	defer pKeyRef.Release()
 
-	return i.iter.SeekLT(pKey)
+	// reset count
+	i.count = 0
+
+	return i.skip(i.iter.SeekLT(pKey), false) && i.Valid()
 }
 
 func (i *iterator) First() bool {
-	return i.iter.First()
+	// reset count
+	i.count = 0
+
+	// seek to last key if reversed
+	if i.reverse {
+		return i.skip(i.iter.Last(), false) && i.Valid()
+	}
+
+	return i.skip(i.iter.First(), true) && i.Valid()
 }
 
 func (i *iterator) Last() bool {
-	return i.iter.Last()
+	// reset count
+	i.count = 0
+
+	// seek to first key if reversed
+	if i.reverse {
+		return i.skip(i.iter.First(), true) && i.Valid()
+	}
+
+	return i.skip(i.iter.Last(), false) && i.Valid()
 }
 
 func (i *iterator) Valid() bool {
+	// check limit
+	if i.limit > 0 && i.count >= i.limit {
+		return false
+	}
+
 	return i.iter.Valid()
 }
 
 func (i *iterator) Next() bool {
-	return i.iter.Next()
+	// increment count
+	i.count++
+
+	// move to previous key if reversed
+	if i.reverse {
+		return i.skip(i.iter.Prev(), false) && i.Valid()
+	}
+
+	return i.skip(i.iter.Next(), true) && i.Valid()
 }
 
 func (i *iterator) Prev() bool {
-	return i.iter.Prev()
+	// increment count
+	i.count++
+
+	// move to next key if reversed
+	if i.reverse {
+		return i.skip(i.iter.Next(), true) && i.Valid()
+	}
+
+	return i.skip(i.iter.Prev(), false) && i.Valid()
+}
+
+func (i *iterator) skip(valid, forward bool) bool {
+	// move past expired values
+	for valid {
+		// decode cell
+		var cell tape.Cell
+		err := cell.Decode(i.iter.Value(), false)
+		if err != nil || !i.txn.expired(cell) {
+			return true
+		}
+
+		// move on
+		if forward {
+			valid = i.iter.Next()
+		} else {
+			valid = i.iter.Prev()
+		}
+	}
+
+	return false
 }
 
 func (i *iterator) Key() ([]byte, Ref) {
@@ -399,6 +826,12 @@ func (i *iterator) Value() ([]byte, Ref, error) {
 }
 
 func (i *iterator) TempValue() ([]byte, error) {
+	// check merge error
+	err := i.iter.Error()
+	if err != nil {
+		return nil, err
+	}
+
 	// get value
 	bytes := i.iter.Value()
 	if len(bytes) == 0 {
@@ -407,15 +840,14 @@ func (i *iterator) TempValue() ([]byte, error) {
 
 	// decode cell (no need to clone as copying is explicit)
 	var cell tape.Cell
-	err := cell.Decode(bytes, false)
+	err = cell.Decode(bytes, false)
 	if err != nil {
 		return nil, err
 	}
 
-	// check type
+	// check type (stack cells are resolved by the merge operator)
 	if cell.Type != tape.RawCell {
-		// we never see stack cells as they are resolved by the merge operator
-		panic("turing: expected raw cell")
+		return nil, fmt.Errorf("turing: iterator value: expected raw cell, got: %d", cell.Type)
 	}
 
 	return cell.Value, nil
@@ -459,6 +891,11 @@ func (i *iterator) Close() error {
 	// release prefix
 	defer i.pkr.Release()
 
+	// release end if available
+	if i.ekr != nil {
+		defer i.ekr.Release()
+	}
+
 	// close iterator
 	err := i.iter.Close()
 	if err != nil {
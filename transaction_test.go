@@ -0,0 +1,75 @@
+package turing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type randomizer struct {
+	Time   time.Time
+	Values []int64
+}
+
+var randomizerDesc = &Description{
+	Name: "randomizer",
+}
+
+func (r *randomizer) Describe() *Description {
+	return randomizerDesc
+}
+
+func (r *randomizer) Effect() int {
+	return 1
+}
+
+func (r *randomizer) Execute(mem Memory, _ Cache) error {
+	r.Time = mem.Now()
+	r.Values = append(r.Values, mem.Rand().Int63(), mem.Rand().Int63())
+	return mem.Set([]byte("randomizer"), []byte(r.Time.String()))
+}
+
+func (r *randomizer) Encode() ([]byte, Ref, error) {
+	return nil, nil, nil
+}
+
+func (r *randomizer) Decode([]byte) error {
+	return nil
+}
+
+func TestTransactionNowAndRand(t *testing.T) {
+	config := Config{
+		Instructions: []Instruction{&randomizer{}},
+		Standalone:   true,
+	}
+	assert.NoError(t, config.Validate())
+
+	registry, err := buildRegistry(config)
+	assert.NoError(t, err)
+
+	db1, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	db2, _, err := openDatabase(config, registry, newManager())
+	assert.NoError(t, err)
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	a1, a2 := &randomizer{}, &randomizer{}
+	err = db1.update([]Instruction{a1, a2}, nil, 1, now.UnixNano(), 42)
+	assert.NoError(t, err)
+
+	b1, b2 := &randomizer{}, &randomizer{}
+	err = db2.update([]Instruction{b1, b2}, nil, 1, now.UnixNano(), 42)
+	assert.NoError(t, err)
+
+	assert.Equal(t, now, a1.Time)
+	assert.Equal(t, now, b2.Time)
+	assert.Equal(t, a1.Values, b1.Values)
+	assert.Equal(t, a2.Values, b2.Values)
+	assert.NotEqual(t, a1.Values, a2.Values)
+
+	assert.NoError(t, db1.close())
+	assert.NoError(t, db2.close())
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -23,6 +25,19 @@ var ErrReadOnly = errors.New("turing: read only")
 // changes persistent and be executed again to persist the remaining changes.
 var ErrMaxEffect = errors.New("turing: max effect")
 
+// ErrMissingTime is returned by a transaction if a value is set with a TTL
+// while executing a legacy command that does not carry a proposal time.
+var ErrMissingTime = errors.New("turing: missing time")
+
+// ErrOverloaded is returned in fail fast mode if a queue or an instruction
+// limit is exhausted. The instruction has not been executed and may be
+// retried later.
+var ErrOverloaded = errors.New("turing: overloaded")
+
+// ErrStopped is returned for instructions that are executed while or after
+// the machine has been stopped.
+var ErrStopped = errors.New("turing: stopped")
+
 // Ref manages the reference to buffer that can be released.
 type Ref interface {
 	Release()
@@ -41,7 +56,8 @@ type Instruction interface {
 	// unset merged and deleted keys during the execution. A zero value
 	// indicates that the instruction is read only and will not set or delete
 	// any keys. A negative number indicates that the effect is unbounded and
-	// may modify many keys.
+	// may modify many keys. Additional writes to maintain the expiry index are
+	// not part of the effect, but count towards the configured MaxEffect.
 	Effect() int
 
 	// Execute should execute the instruction using the provided memory.
@@ -76,7 +92,10 @@ type Description struct {
 	// not carry a result. This potentially reduces some RPC traffic.
 	NoResult bool
 
-	observer prometheus.Observer
+	observer   prometheus.Observer
+	declared   prometheus.Counter
+	actual     prometheus.Counter
+	misreports prometheus.Counter
 }
 
 // Validate will validate the instruction description.
@@ -97,7 +116,9 @@ type Operator struct {
 	// The zero value used as the base value if there is none.
 	Zero []byte
 
-	// The function called to apply operands to a value.
+	// The function called to apply operands to a value. Operands that cause
+	// the function to panic are logged and dropped while the value is kept.
+	// A result returned without a ref may reference the provided value.
 	Apply func(value []byte, ops [][]byte) ([]byte, Ref, error)
 
 	// An optional function called to combine operands.
@@ -112,6 +133,11 @@ type Memory interface {
 	// closed as soon as it is not used anymore.
 	Iterate(prefix []byte) Iterator
 
+	// Range will construct and return a new iterator over the keys in the
+	// range [start, end). An empty end will leave the range unbounded. The
+	// iterator must be closed as soon as it is not used anymore.
+	Range(start, end []byte, opts RangeOptions) Iterator
+
 	// Get will lookup the specified key. The returned slice must not be modified
 	// by the caller. A closer is returned that must be closed once the value is
 	// not used anymore. Consider using Use() if the value is only used temporarily.
@@ -121,10 +147,23 @@ type Memory interface {
 	// it exists.
 	Use(key []byte, fn func(value []byte) error) error
 
+	// GetMany will lookup the specified keys in sorted order using a single
+	// iterator and yield the index and value of every existing key to the
+	// provided function. The value is only valid until the function returns.
+	GetMany(keys [][]byte, fn func(i int, value []byte) error) error
+
 	// Set will set the specified key to the new value. This operation will count
 	// as one towards the effect of the backing transaction.
 	Set(key, value []byte) error
 
+	// SetWithTTL will set the specified key to the new value that expires after
+	// the specified duration. The expiry is computed using the time assigned
+	// to the instruction when it was proposed. Expired keys are treated as
+	// absent and eventually deleted by a background sweep. Merging into an
+	// expiring key retains its expiry. This operation will count as one
+	// towards the effect of the backing transaction.
+	SetWithTTL(key, value []byte, ttl time.Duration) error
+
 	// Unset will remove the specified key. This operation will count as one towards
 	// the effect of the backing transaction.
 	Unset(key []byte) error
@@ -135,11 +174,47 @@ type Memory interface {
 	Delete(start, end []byte) error
 
 	// Merge merges existing values with the provided value using the specified
-	// operator.
+	// operator. An expired value is removed first so that the operand is merged
+	// with the zero value of the operator and no expiry is retained.
 	Merge(key, value []byte, operator *Operator) error
 
+	// Continue will record a continuation for an unbounded instruction, such
+	// as a resume key or cursor. If the instruction returns ErrMaxEffect, the
+	// continuation is persisted with the committed changes and yielded by
+	// Continuation when the instruction is executed again, also after a crash.
+	// An empty state clears the continuation.
+	Continue(state []byte) error
+
+	// Continuation will return the continuation recorded by a previous
+	// execution of the instruction or nil if there is none.
+	Continuation() []byte
+
 	// Effect will return the current effect of the backing transaction.
 	Effect() int
+
+	// Now will return the time assigned to the instruction when it was
+	// proposed. The time is identical on all replicas for write instructions
+	// and the local time for read instructions.
+	Now() time.Time
+
+	// Rand will return a random number generator that is seeded using the
+	// random seed assigned to the instruction when it was proposed. The
+	// generated sequence is identical on all replicas for write instructions.
+	// The generator must not be used after the instruction returned.
+	Rand() *rand.Rand
+}
+
+// RangeOptions define options used to construct a range iterator.
+type RangeOptions struct {
+	// Reverse may be set to iterate the range in reverse order. First and Next
+	// will then move from the end of the range towards the start while Last
+	// and Prev move in the opposite direction. SeekGE and SeekLT are not
+	// affected.
+	Reverse bool
+
+	// Limit may be set to limit the amount of keys yielded after the iterator
+	// has been positioned using First, Last, SeekGE or SeekLT.
+	Limit int
 }
 
 // Iterator is used to iterate over the memory.
@@ -215,3 +290,10 @@ type Observer interface {
 	// returned, the observer will be unsubscribed.
 	Process(ins Instruction) bool
 }
+
+// Flusher may be implemented by observers that buffer processed instructions.
+// Flush is called once when the machine is stopped after the last instruction
+// has been processed.
+type Flusher interface {
+	Flush()
+}
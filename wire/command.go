@@ -14,6 +14,13 @@ type Operation struct {
 
 // Command represents a list of operations.
 type Command struct {
+	// The time of the proposal in nanoseconds since the unix epoch.
+	Time int64
+
+	// The random seed of the proposal.
+	Seed int64
+
+	// The list of operations.
 	Operations []Operation
 }
 
@@ -28,7 +35,11 @@ func (c *Command) Encode(borrow bool) ([]byte, fpack.Ref, error) {
 
 	return fpack.Encode(borrow, func(enc *fpack.Encoder) error {
 		// encode version
-		enc.Uint8(1)
+		enc.Uint8(2)
+
+		// encode header
+		enc.Int64(c.Time)
+		enc.Int64(c.Seed)
 
 		// encode number of operations
 		enc.Uint16(uint16(len(c.Operations))) // ~65K
@@ -47,10 +58,18 @@ func (c *Command) Encode(borrow bool) ([]byte, fpack.Ref, error) {
 func (c *Command) Decode(bytes []byte, clone bool) error {
 	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
 		// check version
-		if dec.Uint8() != 1 {
+		version := dec.Uint8()
+		if version < 1 || version > 2 {
 			return fmt.Errorf("turing: decode command: invalid version")
 		}
 
+		// decode header
+		c.Time, c.Seed = 0, 0
+		if version >= 2 {
+			c.Time = dec.Int64()
+			c.Seed = dec.Int64()
+		}
+
 		// decode number of operations
 		length := dec.Uint16()
 
@@ -70,10 +89,16 @@ func (c *Command) Decode(bytes []byte, clone bool) error {
 func WalkCommand(bytes []byte, fn func(i int, op Operation) (bool, error)) error {
 	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
 		// check version
-		if dec.Uint8() != 1 {
+		version := dec.Uint8()
+		if version < 1 || version > 2 {
 			return fmt.Errorf("turing: walk command: invalid version")
 		}
 
+		// skip header
+		if version >= 2 {
+			dec.Skip(16)
+		}
+
 		// decode number of operations
 		length := dec.Uint16()
 
@@ -91,3 +116,23 @@ func WalkCommand(bytes []byte, fn func(i int, op Operation) (bool, error)) error
 		return nil
 	})
 }
+
+// DecodeHeader will only decode the header of the encoded command.
+func DecodeHeader(bytes []byte, cmd *Command) error {
+	return fpack.Decode(bytes, func(dec *fpack.Decoder) error {
+		// check version
+		version := dec.Uint8()
+		if version < 1 || version > 2 {
+			return fmt.Errorf("turing: decode header: invalid version")
+		}
+
+		// decode header
+		cmd.Time, cmd.Seed = 0, 0
+		if version >= 2 {
+			cmd.Time = dec.Int64()
+			cmd.Seed = dec.Int64()
+		}
+
+		return nil
+	})
+}
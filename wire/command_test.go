@@ -8,6 +8,8 @@ import (
 
 func TestCommandCoding(t *testing.T) {
 	in := Command{
+		Time: 42,
+		Seed: 7,
 		Operations: []Operation{
 			{
 				Name: "foo",
@@ -29,6 +31,12 @@ func TestCommandCoding(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, in, out)
 
+	var header Command
+	err = DecodeHeader(bytes, &header)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), header.Time)
+	assert.Equal(t, int64(7), header.Seed)
+
 	var ops []Operation
 	err = WalkCommand(bytes, func(i int, op Operation) (bool, error) {
 		ops = append(ops, op)
@@ -50,6 +58,30 @@ func TestCommandCoding(t *testing.T) {
 			return true, nil
 		})
 	}))
+
+	assert.Equal(t, 0.0, testing.AllocsPerRun(10, func() {
+		_ = DecodeHeader(bytes, &header)
+	}))
+}
+
+func TestCommandVersions(t *testing.T) {
+	bytes := []byte("\x01\x00\x01\x00\x03foo\x00\x00\x00\x03bar")
+
+	var cmd Command
+	err := cmd.Decode(bytes, false)
+	assert.NoError(t, err)
+	assert.Equal(t, Command{
+		Operations: []Operation{{Name: "foo", Code: []byte("bar")}},
+	}, cmd)
+
+	bytes, _, err = (&Command{Time: 1, Seed: 2}).Encode(false)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(2), bytes[0])
+	assert.Len(t, bytes, 1+16+2)
+
+	bytes[0] = 3
+	err = cmd.Decode(bytes, false)
+	assert.Error(t, err)
 }
 
 func BenchmarkCommandEncode(b *testing.B) {